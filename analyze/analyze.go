@@ -0,0 +1,292 @@
+// Package analyze — итоги года одним вызовом, для встраивания в свои
+// сервисы вместо запуска year-summary:
+//
+//	report, err := analyze.Run(ctx, "export/result.json",
+//		analyze.WithYear(2025),
+//		analyze.WithTimezone(moscow),
+//		analyze.WithJSON(w),
+//	)
+//
+// Внутри — stats.Compute, так что вызовы Run с разными настройками
+// независимы и могут идти параллельно.
+package analyze
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/render"
+	"github.com/bebroedik/year-summary-2025/stats"
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// Report — результат анализа
+type Report struct {
+	Page     stats.PageData        // номинации, как их получает шаблон
+	Year     int                   // за какой год
+	Messages int                   // сколько сообщений вошло в подсчёт
+	Files    []telegram.FileReport // как прочитались экспорты
+	Warnings []error               // не помешало собрать страницу: пропавшие картинки (*render.MediaError)
+}
+
+// Option настраивает Run
+type Option func(*options) error
+
+// options — настройки всех трёх шагов: разбора экспорта, подсчёта
+// (stats.Options) и вывода
+type options struct {
+	stats.Options
+
+	format   string
+	location *time.Location
+	cacheDir string
+	inline   bool
+	outputs  []func(context.Context, stats.PageData) error
+}
+
+// WithYear — год итогов; по умолчанию последний полный год в экспорте (stats.DetectYear)
+func WithYear(year int) Option {
+	return func(o *options) error {
+		o.Year = year
+		return nil
+	}
+}
+
+// WithFormat — формат экспорта, как -format; по умолчанию узнаётся по файлу
+func WithFormat(format string) Option {
+	return func(o *options) error {
+		o.format = format
+		return nil
+	}
+}
+
+// WithTimezone — часовой пояс, в котором считаются дни, часы и границы
+// года; время из экспорта без пояса считается временем в loc (см. inZone)
+func WithTimezone(loc *time.Location) Option {
+	return func(o *options) error {
+		if loc == nil {
+			return fmt.Errorf("timezone is nil")
+		}
+		o.location = loc
+		return nil
+	}
+}
+
+// inZone — время сообщения в поясе loc. Время без пояса, как его пишут
+// Telegram (date), WhatsApp и VK, — это часы того, кто выгружал, подписанные
+// UTC (telegram.Message.WallClock): часы остаются теми же, меняется только
+// пояс, иначе In сдвинул бы их ещё раз. Unix-время Slack, Signal, Matrix и
+// date_unixtime и время со своим смещением (Discord) — настоящие моменты,
+// они переводятся в loc.
+func inZone(m telegram.Message, loc *time.Location) time.Time {
+	t := m.Date
+	if !m.WallClock {
+		return t.In(loc)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// WithNominations — только эти номинации и в этом порядке; имена как в -only
+func WithNominations(names ...string) Option {
+	return func(o *options) error {
+		for _, name := range names {
+			if _, ok := stats.Nominators.Lookup(name); !ok {
+				return fmt.Errorf("unknown nomination %q, known: %s", name, strings.Join(stats.Nominators.Names(), ", "))
+			}
+		}
+		o.Nominations = names
+		return nil
+	}
+}
+
+// WithLocale — язык подписей: ru (по умолчанию) или en, см. stats.Languages
+func WithLocale(locale string) Option {
+	return func(o *options) error {
+		if !slices.Contains(stats.Languages(), locale) {
+			return fmt.Errorf("locale %q is not supported, known: %s", locale, strings.Join(stats.Languages(), ", "))
+		}
+		o.Language = locale
+		return nil
+	}
+}
+
+// WithCacheDir складывает разобранный экспорт в dir, чтобы не разбирать его
+// заново при следующем вызове
+func WithCacheDir(dir string) Option {
+	return func(o *options) error {
+		o.cacheDir = dir
+		return nil
+	}
+}
+
+// WithTitle — заголовок страницы вместо «<чат> — итоги <год>»
+func WithTitle(title string) Option {
+	return func(o *options) error {
+		o.Title = title
+		return nil
+	}
+}
+
+// WithTimeline добавляет на страницу хронику года
+func WithTimeline() Option {
+	return func(o *options) error {
+		o.Timeline = true
+		return nil
+	}
+}
+
+// WithMethodology добавляет приложение «Как считали»
+func WithMethodology() Option {
+	return func(o *options) error {
+		o.Methodology = true
+		return nil
+	}
+}
+
+// WithLeaderboard добавляет слайд с общим рейтингом: первые n участников по
+// числу сообщений
+func WithLeaderboard(n int) Option {
+	return func(o *options) error {
+		if n < 0 {
+			return fmt.Errorf("leaderboard size can't be negative")
+		}
+		o.Leaderboard = n
+		return nil
+	}
+}
+
+// WithThresholds — пороги карточек и хроники вместо stats.DefaultThresholds;
+// нулевые поля t остаются по умолчанию
+func WithThresholds(t stats.Thresholds) Option {
+	return func(o *options) error {
+		if t.CorrMinMessages < 0 || t.DiscoverMinMessages < 0 || t.TimelineSpikes < 0 || t.TimelineTop < 0 || t.SparklineUsers < 0 {
+			return fmt.Errorf("thresholds can't be negative")
+		}
+		o.Thresholds = t
+		return nil
+	}
+}
+
+// WithOptOut — from_id или @ник тех, кого не показывать на карточках
+func WithOptOut(ids ...string) Option {
+	return func(o *options) error {
+		o.OptOut = append(o.OptOut, ids...)
+		return nil
+	}
+}
+
+// WithAvatarDirs — где искать profile_pictures/ с аватарками; по умолчанию
+// рядом с экспортом
+func WithAvatarDirs(dirs ...string) Option {
+	return func(o *options) error {
+		o.AvatarDirs = dirs
+		return nil
+	}
+}
+
+// WithWorkers — сколько номинаций считать одновременно, как -workers
+func WithWorkers(n int) Option {
+	return func(o *options) error {
+		if n < 0 {
+			return fmt.Errorf("workers can't be negative")
+		}
+		o.Workers = n
+		return nil
+	}
+}
+
+// WithJSON пишет номинации в w в том же виде, что export-stats
+func WithJSON(w io.Writer) Option {
+	return func(o *options) error {
+		o.outputs = append(o.outputs, func(_ context.Context, page stats.PageData) error {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(page)
+		})
+		return nil
+	}
+}
+
+// WithSingleFile встраивает картинки страницы в data URL (render.Inline):
+// HTML и JSON не ссылаются на файлы рядом с экспортом
+func WithSingleFile() Option {
+	return func(o *options) error {
+		o.inline = true
+		return nil
+	}
+}
+
+// WithHTML рендерит страницу шаблоном tmpl в w
+func WithHTML(w io.Writer, tmpl *render.Templates) Option {
+	return func(o *options) error {
+		o.outputs = append(o.outputs, func(ctx context.Context, page stats.PageData) error {
+			return render.RenderContext(ctx, w, tmpl, page)
+		})
+		return nil
+	}
+}
+
+// Run читает экспорт input, считает номинации и пишет их во все выходы из opts
+func Run(ctx context.Context, input string, opts ...Option) (*Report, error) {
+	o := &options{}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+
+	read := telegram.ReadExports
+	if o.cacheDir != "" {
+		read = (&telegram.Cache{Dir: o.cacheDir}).ReadExports
+	}
+	all, files, err := read(ctx, []string{input}, o.format)
+	if err != nil {
+		return nil, err
+	}
+	if o.location != nil {
+		for i := range all {
+			all[i].Date = inZone(all[i], o.location)
+		}
+	}
+	year := o.Year
+	if year == 0 {
+		year = stats.DetectYear(stats.FilterMessages(all, stats.FilterTypeMessage))
+	}
+	messages := stats.FilterMessages(all, stats.FilterTypeMessage, stats.FilterYear(year))
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages for %d in %s", year, input)
+	}
+
+	compute := o.Options
+	compute.Year = year
+	if compute.AvatarDirs == nil {
+		compute.AvatarDirs = []string{filepath.Dir(input)}
+	}
+	res, err := stats.ComputeContext(ctx, all, compute)
+	if err != nil {
+		return nil, err
+	}
+	page := res.Page
+
+	var warnings []error
+	for _, path := range res.Missing {
+		warnings = append(warnings, &render.MediaError{Path: path, Err: fs.ErrNotExist})
+	}
+	if o.inline {
+		warnings = append(warnings, render.Inline(&page, ".")...)
+	}
+
+	for _, out := range o.outputs {
+		if err := out(ctx, page); err != nil {
+			return nil, err
+		}
+	}
+	return &Report{Page: page, Year: year, Messages: res.Messages, Files: files, Warnings: warnings}, nil
+}
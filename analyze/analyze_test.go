@@ -0,0 +1,80 @@
+package analyze
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+var moscow = time.FixedZone("MSK", 3*60*60)
+
+func TestInZone(t *testing.T) {
+	cases := []struct {
+		name string
+		in   telegram.Message
+		want string
+	}{
+		// date из result.json: 23:59 по часам выгрузившего
+		{"wall clock", telegram.Message{Date: time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC), WallClock: true}, "2025-12-31T23:59:59+03:00"},
+		{"new year", telegram.Message{Date: time.Date(2025, 1, 1, 0, 0, 5, 0, time.UTC), WallClock: true}, "2025-01-01T00:00:05+03:00"},
+		// unix-время: настоящий момент, в Москве это уже следующий день
+		{"epoch", telegram.Message{Date: time.Date(2025, 12, 31, 23, 30, 0, 0, time.UTC)}, "2026-01-01T02:30:00+03:00"},
+		// Discord: время со смещением
+		{"with offset", telegram.Message{Date: time.Date(2025, 12, 31, 22, 0, 0, 0, time.FixedZone("CET", 60*60))}, "2026-01-01T00:00:00+03:00"},
+	}
+	for _, c := range cases {
+		if got := inZone(c.in, moscow).Format(time.RFC3339); got != c.want {
+			t.Errorf("%s: inZone(%v) = %s, want %s", c.name, c.in.Date, got, c.want)
+		}
+	}
+}
+
+// Экспорт с unix-временем (свой JSONL): сообщение в 23:30 UTC 31 декабря в
+// Москве попадает в следующий год, а без пояса остаётся в этом
+func TestRunTimezoneEpoch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chat.jsonl")
+	lines := strings.Join([]string{
+		`{"timestamp": 1748779200, "sender_id": "anna", "text": "июнь"}`,
+		`{"timestamp": 1767223800, "sender_id": "bob", "text": "почти новый год"}`,
+	}, "\n")
+	if err := os.WriteFile(path, []byte(lines), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		opts []Option
+		want int
+	}{
+		{[]Option{WithYear(2025)}, 2},
+		{[]Option{WithYear(2025), WithTimezone(moscow)}, 1},
+	} {
+		report, err := Run(context.Background(), path, c.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Messages != c.want {
+			t.Errorf("%d messages in 2025, want %d", report.Messages, c.want)
+		}
+	}
+}
+
+// Граница года не сдвигается: сообщения в первую секунду и последнюю
+// минуту года остаются в нём
+func TestRunTimezoneYearBoundary(t *testing.T) {
+	for _, opts := range [][]Option{
+		{WithYear(2025)},
+		{WithYear(2025), WithTimezone(moscow)},
+	} {
+		report, err := Run(context.Background(), "../telegram/testdata/result.json", opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Messages != 7 {
+			t.Errorf("%d messages in 2025, want 7", report.Messages)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/bebroedik/year-summary-2025/render"
+	"github.com/bebroedik/year-summary-2025/stats"
+	"github.com/rs/zerolog/log"
+)
+
+const maxAvatarSize = 10 << 20
+
+// uploadMu не даёт двум загрузкам одновременно переписать конфиг
+var uploadMu sync.Mutex
+
+type adminUser struct {
+	ID       string
+	Name     string
+	Avatar   template.URL // свои пути и data URL, экранировать не нужно
+	Messages int
+}
+
+func (s *previewServer) handleAdmin(w http.ResponseWriter, r *http.Request) {
+	uploadMu.Lock()
+	userCount := stats.Count(s.messages, stats.FilterUser, stats.LabelID)
+	avatars := s.in.settings.Avatars()
+	users := make([]adminUser, 0, len(userCount))
+	for id, n := range userCount {
+		users = append(users, adminUser{ID: id, Name: avatars.Names[id], Avatar: template.URL(avatars.Get(id)), Messages: n})
+	}
+	uploadMu.Unlock()
+
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].Messages != users[j].Messages {
+			return users[i].Messages > users[j].Messages
+		}
+		return users[i].ID < users[j].ID
+	})
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := adminTemplate.Execute(w, users); err != nil {
+		log.Error().Err(err).Msg("render admin page")
+	}
+}
+
+// handleAvatarUpload принимает уже обрезанную в браузере картинку,
+// сохраняет её в avatarsDir и запоминает в конфиге
+func (s *previewServer) handleAvatarUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST only", http.StatusMethodNotAllowed)
+		return
+	}
+	// загрузка пишет файлы и конфиг: чужая страница не должна суметь
+	// отправить её из браузера того, кто смотрит превью
+	if !s.sameOrigin(r) {
+		http.Error(w, "cross-origin request", http.StatusForbidden)
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	uploadMu.Lock()
+	_, known := s.in.settings.Avatars().Names[id]
+	uploadMu.Unlock()
+	if !known {
+		http.Error(w, "unknown participant", http.StatusBadRequest)
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxAvatarSize+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(data) > maxAvatarSize {
+		http.Error(w, "image too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var ext string
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		ext = ".jpg"
+	case "image/png":
+		ext = ".png"
+	default:
+		http.Error(w, "expected JPEG or PNG", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if err := s.saveAvatar(id, ext, data); err != nil {
+		log.Error().Err(err).Str("id", id).Msg("save avatar")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// пересчёт не должен оборваться, если браузер не дождался ответа
+	if err := s.rebuild(context.WithoutCancel(r.Context())); err != nil {
+		log.Error().Err(err).Msg("rebuild page")
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *previewServer) saveAvatar(id, ext string, data []byte) error {
+	uploadMu.Lock()
+	defer uploadMu.Unlock()
+
+	if err := os.MkdirAll(s.avatarsDir, 0755); err != nil {
+		return &render.MediaError{Path: s.avatarsDir, Err: err}
+	}
+	path := filepath.Join(s.avatarsDir, avatarFileName(id)+ext)
+	if rel, err := filepath.Rel(s.avatarsDir, path); err != nil || !filepath.IsLocal(rel) {
+		return &render.MediaError{Path: path, Err: errors.New("outside of avatars dir")}
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return &render.MediaError{Path: path, Err: err}
+	}
+
+	cfg := s.in.cfg
+	if cfg.Users == nil {
+		cfg.Users = map[string]UserConfig{}
+	}
+	u := cfg.Users[id]
+	u.Avatar = filepath.ToSlash(path)
+	cfg.Users[id] = u
+	avatars := s.in.settings.Avatars()
+	avatars.ByID[id] = avatars.Rel(path)
+
+	if err := saveConfig(s.in.Config, cfg); err != nil {
+		return err
+	}
+	log.Info().Str("id", id).Str("path", path).Str("config", s.in.Config).Msg("avatar saved")
+	return nil
+}
+
+// avatarSafe — id, который можно без изменений сделать именем файла
+var avatarSafe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// avatarFileName — имя файла аватарки без расширения. Id телеграма
+// ("user123") остаются как есть, а имена из WhatsApp, id Matrix и прочие
+// id с разделителями, точками и @ заменяются хешем: участник чата не должен
+// выбирать путь, по которому сервер пишет файл.
+func avatarFileName(id string) string {
+	if avatarSafe.MatchString(id) {
+		return id
+	}
+	return stats.HashKey("avatar", id)
+}
+
+// sameOrigin — запрос пришёл со страницы этого же сервера: Host совпадает
+// с адресом, который он слушает, а Origin — с Host
+func (s *previewServer) sameOrigin(r *http.Request) bool {
+	if !sameHost(r.Host, s.addr) {
+		return false
+	}
+	origin, err := url.Parse(r.Header.Get("Origin"))
+	if err != nil || origin.Host == "" {
+		return false
+	}
+	return origin.Host == r.Host
+}
+
+// sameHost сравнивает Host запроса с адресом -addr; для адреса без хоста
+// или 0.0.0.0 совпадать должен только порт
+func sameHost(host, addr string) bool {
+	if host == addr {
+		return true
+	}
+	h, port, err := net.SplitHostPort(addr)
+	if err != nil || (h != "" && !net.ParseIP(h).IsUnspecified()) {
+		return false
+	}
+	_, hostPort, err := net.SplitHostPort(host)
+	return err == nil && hostPort == port
+}
+
+var adminTemplate = template.Must(template.New("admin").Parse(`<!doctype html>
+<html lang="ru">
+<head>
+  <meta charset="utf-8" />
+  <title>Аватарки участников</title>
+  <style>
+    body { font-family: sans-serif; background: #08112b; color: #fff; padding: 24px; }
+    a { color: #ffe066; }
+    table { border-collapse: collapse; }
+    td { padding: 8px 12px; border-bottom: 1px solid rgba(255,255,255,.1); vertical-align: middle; }
+    img.avatar { width: 64px; height: 64px; border-radius: 50%; object-fit: cover; }
+    .muted { color: #ffd8a6; font-size: 13px; }
+    #crop { display: none; position: fixed; inset: 0; background: rgba(0,0,0,.8); align-items: center; justify-content: center; flex-direction: column; gap: 12px; }
+    #crop canvas { border-radius: 50%; cursor: move; background: #222; }
+    button { padding: 8px 14px; border-radius: 8px; border: none; cursor: pointer; }
+  </style>
+</head>
+<body>
+  <h1>Аватарки участников</h1>
+  <p class="muted">Загруженные картинки сохраняются в папку аватарок и прописываются в конфиг. <a href="/">К странице итогов</a></p>
+  <table>
+    {{range .}}
+    <tr>
+      <td><img class="avatar" src="{{.Avatar}}" alt=""></td>
+      <td>{{.Name}}<br><span class="muted">{{.ID}} · {{.Messages}} сообщений</span></td>
+      <td><input type="file" accept="image/*" data-id="{{.ID}}"></td>
+    </tr>
+    {{end}}
+  </table>
+
+  <div id="crop">
+    <canvas id="canvas" width="320" height="320"></canvas>
+    <label>Масштаб <input type="range" id="zoom" min="1" max="4" step="0.01" value="1"></label>
+    <div><button id="save">Сохранить</button> <button id="cancel">Отмена</button></div>
+  </div>
+
+  <script>
+    (function(){
+      const crop = document.getElementById('crop');
+      const canvas = document.getElementById('canvas');
+      const zoom = document.getElementById('zoom');
+      let img = null, id = '', dx = 0, dy = 0, drag = null;
+
+      function base(){ return canvas.width / Math.min(img.width, img.height); }
+      function draw(target, size){
+        const c = target.getContext('2d');
+        const s = base() * zoom.value * size / canvas.width;
+        c.fillStyle = '#000';
+        c.fillRect(0, 0, size, size);
+        c.drawImage(img, size/2 - img.width*s/2 + dx*size/canvas.width, size/2 - img.height*s/2 + dy*size/canvas.height, img.width*s, img.height*s);
+      }
+
+      document.querySelectorAll('input[type=file]').forEach(input => input.addEventListener('change', () => {
+        if (!input.files.length) return;
+        id = input.dataset.id;
+        img = new Image();
+        img.onload = () => { dx = dy = 0; zoom.value = 1; crop.style.display = 'flex'; draw(canvas, canvas.width); };
+        img.src = URL.createObjectURL(input.files[0]);
+        input.value = '';
+      }));
+
+      zoom.addEventListener('input', () => draw(canvas, canvas.width));
+      canvas.addEventListener('mousedown', e => { drag = {x: e.clientX - dx, y: e.clientY - dy}; });
+      window.addEventListener('mouseup', () => { drag = null; });
+      window.addEventListener('mousemove', e => {
+        if (!drag) return;
+        dx = e.clientX - drag.x; dy = e.clientY - drag.y;
+        draw(canvas, canvas.width);
+      });
+
+      document.getElementById('cancel').addEventListener('click', () => { crop.style.display = 'none'; });
+      document.getElementById('save').addEventListener('click', () => {
+        const out = document.createElement('canvas');
+        out.width = out.height = 512;
+        draw(out, 512);
+        out.toBlob(blob => {
+          fetch('/admin/avatar?id=' + encodeURIComponent(id), {method: 'POST', body: blob})
+            .then(r => r.ok ? location.reload() : r.text().then(t => alert(t)));
+        }, 'image/jpeg', 0.9);
+      });
+    })();
+  </script>
+</body>
+</html>
+`))
@@ -0,0 +1,1086 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/render"
+	"github.com/bebroedik/year-summary-2025/stats"
+	"github.com/bebroedik/year-summary-2025/telegram"
+	"github.com/rs/zerolog/log"
+)
+
+// command — одна подкоманда CLI
+type command struct {
+	Name    string
+	Aliases []string // старые имена, чтобы не ломать скрипты
+	Short   string   // однострочное описание для общего help
+	Run     func(ctx context.Context, args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{Name: "generate", Short: "сгенерировать HTML-страницу с итогами года", Run: cmdGenerate},
+		{Name: "serve", Short: "локальный предпросмотр страницы в браузере", Run: cmdServe},
+		{Name: "validate", Short: "проверить экспорт и шаблон без генерации", Run: cmdValidate},
+		{Name: "inspect", Aliases: []string{"explore"}, Short: "вывести сводку по экспорту в терминал", Run: cmdInspect},
+		{Name: "list-chats", Short: "список чатов в экспортах с числом сообщений", Run: cmdListChats},
+		{Name: "list-nominations", Aliases: []string{"nominations"}, Short: "список номинаций по порядку страницы", Run: cmdListNominations},
+		{Name: "export-stats", Short: "выгрузить номинации в JSON", Run: cmdExportStats},
+		{Name: "cards", Short: "нарисовать номинации PNG-карточками или слайдами для сторис", Run: cmdCards},
+		{Name: "video", Short: "собрать номинации в вертикальный ролик MP4 или WebM (нужен ffmpeg)", Run: cmdVideo},
+		{Name: "site", Short: "собрать статический сайт: номинации и страница каждого участника", Run: cmdSite},
+		{Name: "compare", Short: "сравнить два чата: объём, медиа и скорость ответов", Run: cmdCompare},
+		{Name: "post", Short: "выложить номинации в чат Telegram через бота", Run: cmdPost},
+		{Name: "on-this-day", Short: "пост о том, что было в чате в этот день год назад", Run: cmdOnThisDay},
+		{Name: "telegraph", Short: "опубликовать итоги страницей на telegra.ph", Run: cmdTelegraph},
+		{Name: "init", Short: "интерактивно создать year-summary.yaml", Run: cmdInit},
+		{Name: "fixture", Short: "сгенерировать синтетический экспорт для тестов", Run: cmdFixture},
+		{Name: "demo", Short: "собрать демо-страницу на встроенных данных, без экспорта", Run: cmdDemo},
+	}
+}
+
+// language — язык страниц из -lang
+var language = stats.DefaultLanguage
+
+// globals — общие флаги до имени команды: year-summary -in chat.json -year 2024 generate.
+// Они подставляются в одноимённые флаги команды, если та их не задала.
+var globals = map[string]string{}
+
+func newGlobalFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("year-summary", flag.ContinueOnError)
+	fs.String("in", "", "export file(s), as -in of the command")
+	fs.String("out", "", "output file, as -out of the command")
+	fs.Int("year", 0, "year to summarize")
+	fs.String("template", "", "HTML template file")
+	fs.String("config", "", "config file")
+	lang := fs.String("lang", "ru", "language of the page: "+strings.Join(stats.Languages(), " or "))
+	fs.StringVar(lang, "locale", "ru", "alias for -lang")
+	fs.String("profile", "", "write CPU and heap pprof profiles to this directory and print time per stage")
+	fs.Bool("version", false, "print the version and commit and exit")
+	fs.Usage = func() { usage(fs.Output()) }
+	return fs
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: year-summary [global flags] <command> [flags]")
+	fmt.Fprintln(w, "       year-summary [global flags] <export file> [generate flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		name := c.Name
+		if len(c.Aliases) > 0 {
+			name += " (" + strings.Join(c.Aliases, ", ") + ")"
+		}
+		fmt.Fprintf(w, "  %-30s %s\n", name, c.Short)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Global flags:")
+	gfs := newGlobalFlagSet()
+	gfs.SetOutput(w)
+	gfs.PrintDefaults()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "year-summary <command> -h" for command flags.`)
+}
+
+func run(ctx context.Context, args []string) error {
+	gfs := newGlobalFlagSet()
+	if err := gfs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	gfs.Visit(func(fl *flag.Flag) { globals[fl.Name] = fl.Value.String() })
+	if globals["version"] == "true" {
+		b := stats.Build()
+		fmt.Printf("year-summary %s", b.Version)
+		if b.Commit != "" {
+			fmt.Printf(" (%s)", b.Commit)
+		}
+		fmt.Printf(" %s\n", runtime.Version())
+		return nil
+	}
+	l, err := stats.ParseLanguage(gfs.Lookup("lang").Value.String())
+	if err != nil {
+		return err
+	}
+	language = l
+	delete(globals, "lang")
+	delete(globals, "locale")
+	if dir := globals["profile"]; dir != "" {
+		p, err := startProfile(dir)
+		if err != nil {
+			return err
+		}
+		prof = p
+		defer func() {
+			if err := prof.stop(os.Stderr); err != nil {
+				log.Warn().Err(err).Msg("profile")
+			}
+			prof = nil
+		}()
+	}
+	delete(globals, "profile")
+	args = gfs.Args()
+
+	// без подкоманды ведём себя как раньше — просто генерируем страницу
+	if len(args) == 0 {
+		return cmdGenerate(ctx, nil)
+	}
+
+	if args[0] == "help" {
+		usage(os.Stdout)
+		return nil
+	}
+
+	for _, c := range commands {
+		if c.Name == args[0] || slices.Contains(c.Aliases, args[0]) {
+			err := c.Run(ctx, args[1:])
+			if errors.Is(err, flag.ErrHelp) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	// year-summary export.json — то же, что generate -in export.json
+	if st, err := os.Stat(args[0]); err == nil && !st.IsDir() {
+		return cmdGenerate(ctx, append([]string{"-in", args[0]}, args[1:]...))
+	}
+
+	usage(os.Stderr)
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+// parseFlags разбирает флаги команды и добирает незаданные из глобальных
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for name, v := range globals {
+		if set[name] || fs.Lookup(name) == nil {
+			continue
+		}
+		if err := fs.Set(name, v); err != nil {
+			return fmt.Errorf("global -%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func newFlagSet(name, synopsis string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "Usage: year-summary %s [flags]\n\n%s\n\nFlags:\n", name, synopsis)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// inputFlags — общие флаги для команд, которые читают экспорт
+type inputFlags struct {
+	In       string
+	Format   string
+	Year     int    // 0 — последний полный год в экспорте
+	Title    string // заголовок страницы вместо title из конфига и названия чата
+	Config   string
+	PerChat  bool
+	Minimal  bool
+	Only     string // одна номинация вместо всей страницы
+	CacheDir string // куда складывать разобранные экспорты
+	Workers  int    // сколько номинаций считать одновременно, 0 — по числу процессоров
+	Sample   string // доля сообщений для быстрого предпросмотра: 10% или 0.1
+	Seed     int64  // от него зависит, какие сообщения попадут в выборку
+	Decoder  string // чем разбирать result.json, см. telegram.SetDecoder
+
+	cfg      *Config
+	settings *stats.Settings // настройки подсчёта из флагов и конфига, собирает prepare
+	opts     stats.Options   // из чего собраны settings, для других языков
+	registry *stats.Registry // номинации до конфига, для -watch
+	noms     *stats.Registry // номинации с включением и порядком из конфига
+	podium   int             // мест на пьедестале, если podium в конфиге не задан
+	sample   float64         // разобранный Sample, 0 — все сообщения
+	preview  string          // плашка страницы по выборке
+	title    string          // -title или title из конфига с подставленным годом
+	report   telegram.ParseReport
+	service  []telegram.Message // service-сообщения года, для хроники
+}
+
+func addInputFlags(fs *flag.FlagSet) *inputFlags {
+	f := &inputFlags{}
+	fs.StringVar(&f.In, "in", "kuski.json", "path to Telegram export result.json; several exports separated by commas")
+	fs.StringVar(&f.Format, "format", "", "export format: telegram, discord, whatsapp, slack, vk, signal, matrix or generic (CSV/JSONL); detected from the file if empty")
+	fs.IntVar(&f.Year, "year", 0, "year to summarize; by default the last complete year in the export")
+	fs.StringVar(&f.Title, "title", "", "page title; by default the chat name and year")
+	fs.BoolVar(&f.PerChat, "per-chat", false, "with several exports, add a section of nominations per chat")
+	fs.BoolVar(&f.Minimal, "minimal", false, "privacy-safe report: only aggregate numbers, hashed users, no message texts")
+	fs.StringVar(&f.Config, "config", defaultConfigFile, "config file (created by init)")
+	fs.StringVar(&f.CacheDir, "cache-dir", "", "keep parsed exports here, so a rerun (also after Ctrl+C) skips parsing them again")
+	fs.IntVar(&f.Workers, "workers", 0, "nominations computed in parallel; 0 means one per CPU")
+	fs.StringVar(&f.Sample, "sample", "", "quick preview on huge exports: count only this share of messages, e.g. 10%; the page is marked as a preview")
+	fs.Int64Var(&f.Seed, "sample-seed", 1, "seed of the -sample subset; the same seed gives the same messages")
+	fs.StringVar(&f.Decoder, "json-decoder", "std", "JSON decoder for Telegram exports: "+strings.Join(telegram.Decoders(), ", ")+"; scan is faster on huge files")
+	return f
+}
+
+// splitInputs разбирает -in: несколько экспортов через запятую
+func splitInputs(in string) []string {
+	var files []string
+	for _, f := range strings.Split(in, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+func addTemplateFlags(fs *flag.FlagSet) *render.Templates {
+	t := &render.Templates{}
+	fs.StringVar(&t.File, "template", render.DefaultTemplate, "HTML template file; built in: "+strings.Join(render.Builtin(), ", "))
+	fs.StringVar(&t.Dir, "templates-dir", "", "directory with partials (card.html, section.html, cover.html, timeline.html, methodology.html, leaderboard.html, styles.html) overriding the template's")
+	fs.StringVar(&t.Dir, "template-dir", "", "same as -templates-dir")
+	addThemeFlags(fs, t)
+	return t
+}
+
+// addThemeFlags — -theme и -theme-file, в том числе для страниц со своим
+// встроенным шаблоном
+func addThemeFlags(fs *flag.FlagSet, t *render.Templates) {
+	fs.StringVar(&t.Theme, "theme", "", "color theme: "+strings.Join(render.Themes(), " or ")+"; by default the template's own colors")
+	fs.StringVar(&t.ThemeFile, "theme-file", "", "CSS file with variables (--bg: #123;) over the theme, to match the chat's colors without editing the template")
+}
+
+// addOnlyFlag — для команд, которые считают номинации
+func (f *inputFlags) addOnlyFlag(fs *flag.FlagSet) {
+	fs.StringVar(&f.Only, "only", "", "compute just one nomination, e.g. mostReactions (for debugging it or its card)")
+}
+
+// parse разбирает флаги и добирает незаданные из конфига: in, year и extra
+func (f *inputFlags) parse(fs *flag.FlagSet, args []string, extra ...string) error {
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	explicit := false
+	fs.Visit(func(fl *flag.Flag) { explicit = explicit || fl.Name == "config" })
+
+	cfg, err := loadConfig(f.Config, explicit)
+	if err != nil {
+		return err
+	}
+	f.registry = stats.Nominators.Clone()
+	f.noms = f.registry.Clone()
+	if err := cfg.applyNominations(f.noms); err != nil {
+		return err
+	}
+	if _, ok := f.noms.Lookup(f.Only); f.Only != "" && !ok {
+		return fmt.Errorf("unknown nomination %q, known: %s", f.Only, strings.Join(f.noms.Names(), ", "))
+	}
+
+	if err := telegram.SetDecoder(f.Decoder); err != nil {
+		return err
+	}
+	if f.Sample != "" {
+		if f.sample, err = stats.ParseSample(f.Sample); err != nil {
+			return err
+		}
+	}
+
+	f.cfg = cfg
+	return cfg.applyTo(fs, append([]string{"in", "format", "year", "per-chat", "minimal", "cache-dir", "workers"}, extra...)...)
+}
+
+// load читает экспорт и подбирает аватарки; baseDir — папка, относительно
+// которой страница будет ссылаться на картинки
+func (f *inputFlags) load(ctx context.Context, baseDir string) ([]telegram.Message, error) {
+	all, err := f.read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return f.prepare(all, baseDir)
+}
+
+// read разбирает экспорты -in целиком, со всеми годами и service-сообщениями
+func (f *inputFlags) read(ctx context.Context) ([]telegram.Message, error) {
+	read := telegram.ReadExports
+	if f.CacheDir != "" {
+		read = (&telegram.Cache{Dir: f.CacheDir}).ReadExports
+	}
+	defer stage("parse")()
+	all, reports, err := read(ctx, splitInputs(f.In), f.Format)
+	if err != nil {
+		return nil, err
+	}
+	runMetrics.count("parse", len(all))
+	for _, r := range reports {
+		warnDamaged(r.File, r.ParseReport)
+		warnUnknown(r.File, r.ParseReport)
+		f.report.Add(r.ParseReport)
+	}
+	return all, nil
+}
+
+// prepare отбирает из разобранного экспорта сообщения года и настраивает
+// по конфигу всё остальное: заголовок, правки, подписи, аватарки, opt_out.
+// all не меняется, кроме дозаполненных отправителей, так что -watch
+// вызывает prepare заново после правки конфига, не разбирая экспорт.
+func (f *inputFlags) prepare(all []telegram.Message, baseDir string) ([]telegram.Message, error) {
+	defer stage("filter")()
+	if n := stats.BackfillSenders(all); n > 0 {
+		log.Debug().Int("messages", n).Msg("filled missing from or from_id by other messages")
+	}
+	var err error
+	if f.Year == 0 {
+		f.Year = stats.DetectYear(stats.FilterMessages(all, stats.FilterTypeMessage))
+	}
+	f.title = f.Title
+	if f.title == "" {
+		if f.title, err = f.cfg.pageTitle(f.Year); err != nil {
+			return nil, err
+		}
+	}
+	notExcluded := func(m telegram.Message) bool { return !f.cfg.excluded(m) }
+	messages := stats.FilterMessages(all, stats.FilterTypeMessage, stats.FilterYear(f.Year), notExcluded)
+	if f.sample > 0 {
+		total := len(messages)
+		messages = stats.Sample(messages, f.sample, f.Seed)
+		f.preview = stats.PreviewNote(language, len(messages), total)
+		log.Info().Int("messages", len(messages)).Int("of", total).Msg("sample: counting a subset, the page is a preview")
+	}
+	f.service = stats.FilterMessages(all, func(m telegram.Message) bool { return m.Type == "service" }, stats.FilterYear(f.Year))
+
+	rules, err := stats.CompileRedactions(f.cfg.Redact)
+	if err != nil {
+		return nil, err
+	}
+	stats.ApplyRedactions(messages, rules)
+	if f.cfg.Podium == 0 {
+		f.cfg.Podium = f.podium
+	}
+	opts := f.cfg.options()
+	opts.Language = language
+	opts.Minimal = f.Minimal
+	opts.Workers = f.Workers
+	opts.Registry = f.noms
+	if f.Minimal {
+		// имена из opt_out превращаются в from_id, пока сообщения не обезличены
+		opts.OptOut = stats.MinimalOptOut(f.cfg.OptOut, slices.Concat(messages, f.service), f.cfg.MinimalSalt)
+		stats.MinimizeMessages(messages, f.cfg.MinimalSalt)
+		stats.MinimizeMessages(f.service, f.cfg.MinimalSalt)
+		// в обезличенном отчёте никаких фото и настоящих имён
+		opts.Avatars = stats.LoadAvatars(nil, baseDir, messages)
+	} else {
+		files := splitInputs(f.In)
+		dirs := make([]string, len(files))
+		for i, file := range files {
+			dirs[i] = filepath.Dir(file)
+		}
+		opts.Avatars = stats.LoadAvatars(dirs, baseDir, messages)
+		f.cfg.applyUsers(opts.Avatars)
+		f.cfg.applyImages(opts.Avatars)
+		opts.OptOut = f.cfg.OptOut
+	}
+	if f.settings, err = stats.NewSettings(opts, messages, f.service); err != nil {
+		return nil, fmt.Errorf("config %w", err)
+	}
+	f.opts = opts
+	return messages, nil
+}
+
+func warnDamaged(file string, r telegram.ParseReport) {
+	if !r.Damaged() {
+		return
+	}
+	log.Warn().
+		Str("file", file).
+		Int("recovered", r.Parsed).
+		Int("lost", r.Lost).
+		Bool("truncated", r.Truncated).
+		Int64("offset", r.Offset).
+		Msg("export is damaged, keeping what could be parsed")
+}
+
+// warnUnknown — сводка по незнакомым значениям в экспорте: обычно это
+// значит, что Telegram поменял формат и стоит обновить программу
+func warnUnknown(file string, r telegram.ParseReport) {
+	for _, u := range r.Unknown {
+		log.Warn().
+			Str("file", file).
+			Str("field", u.Field).
+			Str("value", u.Value).
+			Int("messages", u.Count).
+			Ints64("examples", u.Examples).
+			Msg("unknown value in export, newer Telegram format?")
+	}
+}
+
+// page собирает данные страницы; несколько экспортов дают общую страницу
+func (f *inputFlags) page(ctx context.Context, messages []telegram.Message) (stats.PageData, error) {
+	return f.pageWith(ctx, f.settings, messages)
+}
+
+// pageWith — page с настройками s, например на другом языке
+func (f *inputFlags) pageWith(ctx context.Context, s *stats.Settings, messages []telegram.Message) (stats.PageData, error) {
+	done := stage("nominations")
+	page, err := f.formPage(ctx, s, messages)
+	done()
+	if err != nil {
+		return stats.PageData{}, err
+	}
+	count := len(page.Nominations)
+	for _, sec := range page.Sections {
+		count += len(sec.Nominations)
+	}
+	runMetrics.count("nominations", count)
+	page.Preview = f.preview
+	if missing := s.Avatars().TakeMissing(); len(missing) > 0 {
+		log.Warn().Strs("files", missing).Msg("images not found, using generated avatars instead")
+	}
+	timings := s.TakeTimings()
+	prof.nominations(timings)
+	for _, t := range timings {
+		if t.Duration >= slowNomination {
+			log.Warn().Str("nomination", t.Name).Dur("took", t.Duration).Msg("slow nomination")
+		}
+	}
+	return page, nil
+}
+
+// slowNomination — номинации дольше этого попадают в лог
+const slowNomination = 2 * time.Second
+
+func (f *inputFlags) formPage(ctx context.Context, s *stats.Settings, messages []telegram.Message) (stats.PageData, error) {
+	if nom, ok := s.Registry().Lookup(f.Only); ok {
+		n, ok := stats.Nominate(s, nom, messages)
+		if !ok {
+			return stats.PageData{Title: nom.Name(), Build: stats.Build()}, nil
+		}
+		return stats.PageData{Title: n.Title, Nominations: []stats.Nomination{n}, Build: stats.Build()}, nil
+	}
+	page, err := stats.FormMultiPageContext(ctx, s, messages, f.PerChat)
+	if err != nil {
+		return stats.PageData{}, err
+	}
+	if f.title != "" {
+		page.Title = f.title
+	}
+	page.Cover = s.Avatars().Cover()
+	// в обезличенном отчёте хроника выдала бы имена и тексты
+	if f.cfg.Timeline && !f.Minimal {
+		page.Timeline = stats.Timeline(s, append(f.service, messages...))
+	}
+	if f.cfg.Methodology {
+		page.Methodology = stats.Methodology(s, page.Nominations)
+	}
+	if f.cfg.Leaderboard > 0 {
+		page.TopUsers = stats.TopUsers(s, messages, f.cfg.Leaderboard)
+	}
+	return page, nil
+}
+
+func cmdGenerate(ctx context.Context, args []string) error {
+	fs := newFlagSet("generate", "Render the year summary page from a Telegram export.")
+	in := addInputFlags(fs)
+	tmpl := addTemplateFlags(fs)
+	out := fs.String("out", "year_summary.html", `output HTML file ("-" for stdout)`)
+	singleFile := fs.Bool("single-file", false, "embed avatars and other images into the HTML, so the page is one file without images/")
+	outFormat := fs.String("out-format", "", "html, md (Markdown for wikis, Notion and chats), json (nominations and the per-user, per-day and emoji aggregates), csv or xlsx (a table with a row per member), email (HTML for a mailing list: tables, inline styles, no images), term (a colorized report for the terminal, to stdout unless -out is set); by default from the -out extension, otherwise html")
+	langs := fs.String("langs", "", "comma-separated languages for one page with a language switch, e.g. ru,en (HTML only)")
+	watchFlag := fs.Bool("watch", false, "keep running: regenerate when the template, partials or config change, without parsing the export again")
+	graph := fs.String("graph", "", "also write who replies to and mentions whom as a graph: .dot or .gv for Graphviz, .graphml for Gephi")
+	in.addOnlyFlag(fs)
+	if err := in.parse(fs, args, "template", "templates-dir", "theme", "theme-file", "out", "single-file", "langs"); err != nil {
+		return err
+	}
+	format, err := outputFormat(*outFormat, *out)
+	if err != nil {
+		return err
+	}
+	languages := splitInputs(*langs)
+	if len(languages) > 1 && format != "html" {
+		return fmt.Errorf("-langs needs HTML output, not %s", format)
+	}
+	if format == "term" {
+		// отчёт для глаз: в терминал, если -out не задан, и с пьедесталом
+		outSet := false
+		fs.Visit(func(fl *flag.Flag) { outSet = outSet || fl.Name == "out" })
+		if !outSet {
+			*out = "-"
+		}
+		in.podium = termPodium
+	}
+	if *watchFlag && *out == "-" {
+		return errors.New("-watch needs an output file, not stdout")
+	}
+	if *graph == "-" && *out == "-" {
+		return errors.New("-graph and -out can't both go to stdout")
+	}
+
+	all, err := in.read(ctx)
+	if err != nil {
+		return err
+	}
+	baseDir := filepath.Dir(*out)
+	messages, err := in.prepare(all, baseDir)
+	if err != nil {
+		return err
+	}
+	job := &generateJob{in: in, tmpl: tmpl, out: *out, format: format, singleFile: *singleFile, languages: languages, graph: *graph}
+	if err := job.run(ctx, messages); err != nil {
+		return err
+	}
+	if !*watchFlag {
+		return nil
+	}
+	return watch(ctx, in, tmpl, func(reload bool) error {
+		if reload {
+			reloaded, err := in.reloadMessages(all, baseDir)
+			if err != nil {
+				return fmt.Errorf("reload config: %w", err)
+			}
+			messages = reloaded
+		}
+		return job.run(ctx, messages)
+	})
+}
+
+// generateJob — что и куда пишет generate; с -watch запускается заново на
+// каждое изменение шаблона или конфига
+type generateJob struct {
+	in         *inputFlags
+	tmpl       *render.Templates
+	out        string
+	format     string
+	singleFile bool
+	languages  []string
+	graph      string // куда ещё записать граф ответов и упоминаний, "" — не писать
+}
+
+func (j *generateJob) run(ctx context.Context, messages []telegram.Message) error {
+	in, tmpl, out, format, languages := j.in, j.tmpl, j.out, j.format, j.languages
+	if j.graph != "" {
+		if err := render.GenerateGraph(j.graph, stats.SocialGraph(in.settings, messages)); err != nil {
+			return fmt.Errorf("generate graph: %w", err)
+		}
+		log.Info().Str("out", j.graph).Msg("reply and mention graph written")
+	}
+	if format == "csv" || format == "xlsx" {
+		// таблице участников номинации не нужны
+		defer stage("render")()
+		if err := render.GenerateTable(out, format, stats.ComputeAggregates(in.settings, messages)); err != nil {
+			return fmt.Errorf("generate %s: %w", format, err)
+		}
+		log.Info().Str("out", out).Int("messages", len(messages)).Msg("members table written")
+		return nil
+	}
+
+	page, err := in.page(ctx, messages)
+	if err != nil {
+		return err
+	}
+	done := stage("render")
+	switch format {
+	case "json":
+		err = render.GenerateJSON(out, page, stats.ComputeAggregates(in.settings, messages))
+		done()
+		if err != nil {
+			return fmt.Errorf("generate json: %w", err)
+		}
+		log.Info().Str("out", out).Int("messages", len(messages)).Msg("json stats written")
+		return nil
+	case "md":
+		err = render.GenerateMarkdown(out, page)
+		done()
+		if err != nil {
+			return fmt.Errorf("generate markdown: %w", err)
+		}
+		log.Info().Str("out", out).Int("messages", len(messages)).Msg("markdown report generated")
+		return nil
+	case "term":
+		err = writeTerm(out, page)
+		done()
+		if err != nil {
+			return fmt.Errorf("generate term: %w", err)
+		}
+		return nil
+	case "email":
+		if page.Theme, err = render.LoadTheme(tmpl.Theme, tmpl.ThemeFile); err == nil {
+			err = render.GenerateEmail(out, page)
+		}
+		done()
+		if err != nil {
+			return fmt.Errorf("generate email: %w", err)
+		}
+		log.Info().Str("out", out).Int("messages", len(messages)).Msg("email page generated")
+		return nil
+	}
+	if len(languages) > 1 {
+		done()
+		pages, err := in.languagePages(ctx, messages, page, languages)
+		if err != nil {
+			return err
+		}
+		defer stage("render")()
+		if j.singleFile {
+			for i := range pages {
+				for _, err := range render.Inline(&pages[i], filepath.Dir(out)) {
+					log.Warn().Err(err).Msg("image not embedded, the page links to it")
+				}
+			}
+		}
+		if err := render.GenerateLanguages(ctx, tmpl, out, pages); err != nil {
+			return fmt.Errorf("generate html: %w", err)
+		}
+		log.Info().Str("out", out).Strs("langs", languages).Int("messages", len(messages)).Msg("page generated")
+		return nil
+	}
+	if j.singleFile {
+		for _, err := range render.Inline(&page, filepath.Dir(out)) {
+			log.Warn().Err(err).Msg("image not embedded, the page links to it")
+		}
+	}
+	err = render.GenerateContext(ctx, tmpl, out, page)
+	done()
+	if err != nil {
+		return fmt.Errorf("generate html: %w", err)
+	}
+	log.Info().Str("out", out).Int("messages", len(messages)).Msg("page generated")
+	return nil
+}
+
+// languagePages — страница на каждом из языков langs по порядку; page уже
+// посчитана на языке -lang, остальные считаются заново со своими
+// настройками — подписи номинаций собираются при подсчёте
+func (f *inputFlags) languagePages(ctx context.Context, messages []telegram.Message, page stats.PageData, langs []string) ([]stats.PageData, error) {
+	pages := make([]stats.PageData, 0, len(langs))
+	for _, l := range langs {
+		if l == f.settings.Language() {
+			pages = append(pages, page)
+			continue
+		}
+		opts := f.opts
+		opts.Language = l
+		s, err := stats.NewSettings(opts, messages, f.service)
+		if err != nil {
+			return nil, err
+		}
+		p, err := f.pageWith(ctx, s, messages)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, p)
+	}
+	return pages, nil
+}
+
+// termPodium — сколько мест показывать в -out-format term, если podium
+// в конфиге не задан
+const termPodium = 5
+
+// writeTerm печатает отчёт для терминала; цвета — только если вывод и
+// правда в терминал и не задан NO_COLOR
+func writeTerm(outFile string, page stats.PageData) error {
+	if outFile != "-" {
+		var out bytes.Buffer
+		if err := render.Term(&out, page, false); err != nil {
+			return err
+		}
+		return os.WriteFile(outFile, out.Bytes(), 0644)
+	}
+	color := os.Getenv("NO_COLOR") == ""
+	if st, err := os.Stdout.Stat(); err != nil || st.Mode()&os.ModeCharDevice == 0 {
+		color = false
+	}
+	return render.Term(os.Stdout, page, color)
+}
+
+// outputFormat — во что писать generate: html, md, json, csv, xlsx, email
+// или term; без -out-format — по расширению -out
+func outputFormat(format, out string) (string, error) {
+	switch strings.ToLower(format) {
+	case "":
+		switch strings.ToLower(filepath.Ext(out)) {
+		case ".md", ".markdown":
+			return "md", nil
+		case ".json", ".csv", ".xlsx":
+			return strings.ToLower(filepath.Ext(out))[1:], nil
+		}
+		return "html", nil
+	case "md", "markdown":
+		return "md", nil
+	case "html", "json", "csv", "xlsx", "email", "term":
+		return strings.ToLower(format), nil
+	}
+	return "", fmt.Errorf("unknown -out-format %q, want html, md, json, csv, xlsx, email or term", format)
+}
+
+func cmdValidate(ctx context.Context, args []string) error {
+	fs := newFlagSet("validate", "Check that the export parses and the template only uses fields that exist.")
+	in := addInputFlags(fs)
+	tmpl := addTemplateFlags(fs)
+	in.addOnlyFlag(fs)
+	if err := in.parse(fs, args, "template", "templates-dir", "theme", "theme-file"); err != nil {
+		return err
+	}
+
+	messages, err := in.load(ctx, ".")
+	if err != nil {
+		return err
+	}
+	if len(messages) == 0 {
+		return fmt.Errorf("no messages for %d in %s", in.Year, in.In)
+	}
+
+	page, err := in.page(ctx, messages)
+	if err != nil {
+		return err
+	}
+	t, err := tmpl.Load()
+	if err != nil {
+		return err
+	}
+	if issues := render.Lint(t, page); len(issues) > 0 {
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+		return fmt.Errorf("template has %d problems", len(issues))
+	}
+	if err := render.RenderContext(ctx, io.Discard, tmpl, page); err != nil {
+		return err
+	}
+
+	fmt.Printf("ok: %d messages, template %s\n", len(messages), tmpl.File)
+	if r := in.report; r.Damaged() {
+		fmt.Printf("warning: export damaged — %d messages recovered, %d lost (truncated: %v)\n", r.Parsed, r.Lost, r.Truncated)
+	}
+	return nil
+}
+
+func cmdInspect(ctx context.Context, args []string) error {
+	fs := newFlagSet("inspect", "Print message counts per user and per media type.")
+	in := addInputFlags(fs)
+	limit := fs.Int("n", 5, "number of sample messages to print")
+	if err := in.parse(fs, args); err != nil {
+		return err
+	}
+
+	messages, err := in.load(ctx, ".")
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Messages in %d: %d\n\n", in.Year, len(messages))
+
+	fmt.Println("Users:")
+	printCounts(stats.Count(messages, stats.FilterTrue, stats.LabelID), func(id string) string {
+		return fmt.Sprintf("%s (%s)", in.settings.Avatars().Names[id], id)
+	})
+
+	fmt.Println("\nMedia types:")
+	printCounts(stats.Count(messages, stats.FilterTrue, func(m telegram.Message) string {
+		if m.MediaType == "" {
+			return "(text)"
+		}
+		return m.MediaType
+	}), nil)
+
+	if len(in.report.Unknown) > 0 {
+		fmt.Println("\nUnknown values (new export format?):")
+		for _, u := range in.report.Unknown {
+			if in.Minimal { // id сообщений в обезличенном отчёте ни к чему
+				fmt.Printf("  %7d  %s=%q\n", u.Count, u.Field, u.Value)
+				continue
+			}
+			fmt.Printf("  %7d  %s=%q, e.g. %s\n", u.Count, u.Field, u.Value, joinIDs(u.Examples))
+		}
+	}
+
+	if in.Minimal {
+		return nil
+	}
+	fmt.Println("\nSample:")
+	for i, m := range messages {
+		if i >= *limit {
+			break
+		}
+		fmt.Printf("#%d %s %s: %s\n", m.ID, m.Date.Format("2006-01-02 15:04"), m.From, m.Text)
+	}
+	return nil
+}
+
+// joinIDs — id сообщений через запятую: #12, #40
+func joinIDs(ids []int64) string {
+	list := make([]string, len(ids))
+	for i, id := range ids {
+		list[i] = fmt.Sprintf("#%d", id)
+	}
+	return strings.Join(list, ", ")
+}
+
+// печатает счётчики по убыванию
+func printCounts(cnt map[string]int, label func(string) string) {
+	keys := make([]string, 0, len(cnt))
+	for k := range cnt {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if cnt[keys[i]] != cnt[keys[j]] {
+			return cnt[keys[i]] > cnt[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	for _, k := range keys {
+		name := k
+		if label != nil {
+			name = label(k)
+		}
+		fmt.Printf("  %7d  %s\n", cnt[k], name)
+	}
+}
+
+func cmdListChats(ctx context.Context, args []string) error {
+	fs := newFlagSet("list-chats", "List chats found in the exports with their message counts for the year.")
+	in := addInputFlags(fs)
+	if err := in.parse(fs, args); err != nil {
+		return err
+	}
+
+	messages, err := in.load(ctx, ".")
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Chats in %d:\n", in.Year)
+	printCounts(stats.Count(messages, stats.FilterTrue, func(m telegram.Message) string { return m.Chat }), nil)
+	return nil
+}
+
+func cmdListNominations(ctx context.Context, args []string) error {
+	fs := newFlagSet("list-nominations", "List nominations in page order; disabled ones are marked.")
+	config := fs.String("config", defaultConfigFile, "config file (created by init)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	explicit := false
+	fs.Visit(func(fl *flag.Flag) { explicit = explicit || fl.Name == "config" })
+	cfg, err := loadConfig(*config, explicit)
+	if err != nil {
+		return err
+	}
+	registry := stats.Nominators.Clone()
+	if err := cfg.applyNominations(registry); err != nil {
+		return err
+	}
+
+	for _, name := range registry.Names() {
+		mark := " "
+		if !registry.IsEnabled(name) {
+			mark = "-"
+		}
+		fmt.Printf("%s %s\n", mark, name)
+	}
+	return nil
+}
+
+func cmdExportStats(ctx context.Context, args []string) error {
+	fs := newFlagSet("export-stats", "Write the computed nominations as JSON.")
+	in := addInputFlags(fs)
+	out := fs.String("out", "-", `output file ("-" for stdout)`)
+	in.addOnlyFlag(fs)
+	if err := in.parse(fs, args); err != nil {
+		return err
+	}
+
+	messages, err := in.load(ctx, ".")
+	if err != nil {
+		return err
+	}
+
+	page, err := in.page(ctx, messages)
+	if err != nil {
+		return err
+	}
+	defer stage("render")()
+	data, err := json.MarshalIndent(page, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal stats: %w", err)
+	}
+	data = append(data, '\n')
+
+	if *out == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*out, data, 0644)
+}
+
+func cmdCards(ctx context.Context, args []string) error {
+	fs := newFlagSet("cards", "Render every nomination as a square PNG card, to post them in the chat one by one.")
+	in := addInputFlags(fs)
+	out := fs.String("out", "cards", "output directory for 01.png, 02.png, …")
+	story := fs.Bool("story", false, "vertical 1080×1920 story slides instead: a cover and a few nominations per slide (story-01.png, …)")
+	in.addOnlyFlag(fs)
+	if err := in.parse(fs, args, "out"); err != nil {
+		return err
+	}
+
+	messages, err := in.load(ctx, ".")
+	if err != nil {
+		return err
+	}
+
+	page, err := in.page(ctx, messages)
+	if err != nil {
+		return err
+	}
+	defer stage("render")()
+	write := render.WriteCards
+	if *story {
+		write = render.WriteStories
+	}
+	files, warnings, err := write(ctx, page, *out, ".")
+	for _, w := range warnings {
+		log.Warn().Err(w).Msg("image not drawn, using a placeholder")
+	}
+	if err != nil {
+		return fmt.Errorf("cards: %w", err)
+	}
+	log.Info().Str("out", *out).Int("cards", len(files)).Msg("cards generated")
+	return nil
+}
+
+func cmdVideo(ctx context.Context, args []string) error {
+	fs := newFlagSet("video", "Render the nominations as a vertical 1080×1920 video: a cover, then one award per slide with fades. Frames are piped to ffmpeg, which must be installed.")
+	in := addInputFlags(fs)
+	out := fs.String("out", "recap.mp4", "output video file, .mp4 or .webm")
+	seconds := fs.Float64("seconds", render.VideoSeconds, "how long every award stays on screen")
+	ffmpeg := fs.String("ffmpeg", "ffmpeg", "ffmpeg binary")
+	in.addOnlyFlag(fs)
+	if err := in.parse(fs, args); err != nil {
+		return err
+	}
+
+	messages, err := in.load(ctx, ".")
+	if err != nil {
+		return err
+	}
+	page, err := in.page(ctx, messages)
+	if err != nil {
+		return err
+	}
+	defer stage("render")()
+	warnings, err := render.WriteVideo(ctx, page, *out, ".", render.VideoOptions{Seconds: *seconds, FFmpeg: *ffmpeg})
+	for _, w := range warnings {
+		log.Warn().Err(w).Msg("image not drawn, using a placeholder")
+	}
+	if err != nil {
+		return fmt.Errorf("video: %w", err)
+	}
+	log.Info().Str("out", *out).Msg("video generated")
+	return nil
+}
+
+func cmdSite(ctx context.Context, args []string) error {
+	fs := newFlagSet("site", "Build a static site: the nominations page, a page per member and the images they use, ready for any static hosting.")
+	in := addInputFlags(fs)
+	tmpl := addTemplateFlags(fs)
+	out := fs.String("out", "site", "output directory for index.html, members/ and assets/")
+	in.addOnlyFlag(fs)
+	if err := in.parse(fs, args, "template", "templates-dir", "theme", "theme-file", "out"); err != nil {
+		return err
+	}
+
+	// картинки копируются в out/assets, пути к ним — от текущей папки
+	messages, err := in.load(ctx, ".")
+	if err != nil {
+		return err
+	}
+	page, err := in.page(ctx, messages)
+	if err != nil {
+		return err
+	}
+	defer stage("render")()
+	members := stats.MemberPages(in.settings, page, messages)
+	files, warnings, err := render.WriteSite(ctx, tmpl, *out, ".", page, members)
+	for _, w := range warnings {
+		log.Warn().Err(w).Msg("image not copied, the page links to it")
+	}
+	if err != nil {
+		return fmt.Errorf("site: %w", err)
+	}
+	log.Info().Str("out", *out).Int("members", len(members)).Int("pages", len(files)).Msg("site generated")
+	return nil
+}
+
+func cmdCompare(ctx context.Context, args []string) error {
+	fs := newFlagSet("compare", "Compare two chats head to head: volume, media mix and response speed. -in takes two exports separated by a comma.")
+	in := addInputFlags(fs)
+	tmpl := &render.Templates{}
+	addThemeFlags(fs, tmpl)
+	out := fs.String("out", "compare.html", "output HTML file, - for stdout")
+	if err := in.parse(fs, args, "theme", "theme-file"); err != nil {
+		return err
+	}
+
+	messages, err := in.load(ctx, ".")
+	if err != nil {
+		return err
+	}
+	defer stage("render")()
+	c, err := stats.CompareExports(in.settings, messages)
+	if err != nil {
+		return err
+	}
+	if c.Theme, err = render.LoadTheme(tmpl.Theme, tmpl.ThemeFile); err != nil {
+		return err
+	}
+	if err := render.GenerateCompare(*out, c); err != nil {
+		return fmt.Errorf("compare: %w", err)
+	}
+	log.Info().Str("out", *out).Strs("chats", c.Chats[:]).Ints("wins", c.Wins[:]).Msg("comparison generated")
+	return nil
+}
+
+func cmdFixture(ctx context.Context, args []string) error {
+	fs := newFlagSet("fixture", "Generate a synthetic Telegram export for testing templates and stats.")
+	out := fs.String("out", "fixture.json", "output file")
+	users := fs.Int("users", 8, "number of participants")
+	n := fs.Int("messages", 2000, "number of messages")
+	year := fs.Int("year", 2025, "year of generated messages")
+	seed := fs.Int64("seed", 1, "random seed")
+	name := fs.String("name", "Тестовый чат", "chat name")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	export := makeFixture(*seed, *users, *n, *year)
+	export.Name = *name
+	data, err := json.MarshalIndent(export, "", " ")
+	if err != nil {
+		return fmt.Errorf("marshal fixture: %w", err)
+	}
+	if err := os.WriteFile(*out, data, 0644); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+	log.Info().Str("out", *out).Int("messages", *n).Msg("fixture written")
+	return nil
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+	"github.com/bebroedik/year-summary-2025/telegram"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultConfigFile = "year-summary.yaml"
+
+// Config — year-summary.yaml. Значения из файла служат умолчаниями для флагов,
+// явно переданный флаг всегда важнее.
+type Config struct {
+	Input        string                      `yaml:"input,omitempty"`
+	Inputs       []string                    `yaml:"inputs,omitempty"`    // несколько чатов в одном отчёте
+	Format       string                      `yaml:"format,omitempty"`    // см. -format
+	CacheDir     string                      `yaml:"cache_dir,omitempty"` // см. -cache-dir
+	Workers      int                         `yaml:"workers,omitempty"`   // см. -workers
+	PerChat      bool                        `yaml:"per_chat,omitempty"`
+	Output       string                      `yaml:"output,omitempty"`
+	SingleFile   bool                        `yaml:"single_file,omitempty"` // картинки внутри HTML, см. -single-file
+	Template     string                      `yaml:"template,omitempty"`
+	TemplatesDir string                      `yaml:"templates_dir,omitempty"` // свои card.html, section.html, styles.html
+	Theme        string                      `yaml:"theme,omitempty"`         // палитра: dark или light, см. -theme
+	ThemeFile    string                      `yaml:"theme_file,omitempty"`    // CSS-переменные поверх палитры, см. -theme-file
+	Year         int                         `yaml:"year,omitempty"`
+	Title        string                      `yaml:"title,omitempty"`     // заголовок страницы, шаблон с {{.Year}}
+	Languages    []string                    `yaml:"languages,omitempty"` // несколько языков на одной странице, см. -langs
+	Users        map[string]UserConfig       `yaml:"users,omitempty"`     // ключ — from_id
+	Images       ImagesConfig                `yaml:"images,omitempty"`
+	Nominations  map[string]NominationConfig `yaml:"nominations,omitempty"` // ключ — имя номинации, как в -only
+	Enabled      []string                    `yaml:"enabled,omitempty"`     // только эти номинации; пусто — все
+	Order        []string                    `yaml:"order,omitempty"`       // эти номинации идут первыми, остальные за ними
+	Discover     int                         `yaml:"discover,omitempty"`    // сколько необычных фактов о чате добавить карточками
+	Podium       int                         `yaml:"podium,omitempty"`      // сколько мест показывать под карточками с участниками
+	Custom       []stats.CustomNomination    `yaml:"custom,omitempty"`      // свои номинации без программирования
+	Plugins      []string                    `yaml:"plugins,omitempty"`     // Go-плагины (.so) со своими номинациями
+	Churn        *stats.ChurnOptions         `yaml:"churn,omitempty"`       // карточка «Мы скучаем», только если задана
+	Thresholds   stats.Thresholds            `yaml:"thresholds,omitempty"`  // пороги карточек и хроники, см. stats.Thresholds
+	Timeline     bool                        `yaml:"timeline,omitempty"`    // слайд «Хроника года» после номинаций
+	Methodology  bool                        `yaml:"methodology,omitempty"` // приложение «Как считали» в конце
+	Leaderboard  int                         `yaml:"leaderboard,omitempty"` // слайд с общим рейтингом: сколько участников показать
+	OptOut       []string                    `yaml:"opt_out,omitempty"`     // from_id или @username тех, кого не показывать
+	Exclude      []string                    `yaml:"exclude,omitempty"`     // from_id или имена тех, чьи сообщения не считать вовсе (боты)
+	Redact       []stats.RedactRule          `yaml:"redact,omitempty"`      // что вычистить из текстов сообщений
+	Normalize    []string                    `yaml:"normalize,omitempty"`   // шаги нормализации текста для анализа, см. stats.Options.Normalize
+	Minimal      bool                        `yaml:"minimal,omitempty"`     // только агрегаты, см. -minimal
+	MinimalSalt  string                      `yaml:"minimal_salt,omitempty"`
+}
+
+// UserConfig — ручные настройки участника
+type UserConfig struct {
+	Name   string          `yaml:"name,omitempty"`   // как подписывать участника
+	Avatar string          `yaml:"avatar,omitempty"` // путь к картинке, важнее автопоиска
+	Forms  stats.NameForms `yaml:"forms,omitempty"`  // падежи имени для подписей
+	Gender string          `yaml:"gender,omitempty"` // m или f — окончания глаголов в подписях
+}
+
+// ImagesConfig — общие картинки страницы
+type ImagesConfig struct {
+	Avatar      string `yaml:"avatar,omitempty"`      // для номинаций без участника; по умолчанию нарисованная
+	Cover       string `yaml:"cover,omitempty"`       // обложка перед номинациями; по умолчанию её нет
+	Placeholder string `yaml:"placeholder,omitempty"` // вместо заглушки с инициалами
+}
+
+// NominationConfig — ручные настройки номинации
+type NominationConfig struct {
+	Avatar   string `yaml:"avatar,omitempty"`   // картинка вместо аватарки победителя
+	Caption  string `yaml:"caption,omitempty"`  // своя подпись, шаблон с {{.Nom}}, {{.Gen}}, {{.Dat}}
+	Disabled bool   `yaml:"disabled,omitempty"` // не показывать на странице
+	Podium   *int   `yaml:"podium,omitempty"`   // сколько мест под карточкой, поверх podium страницы; 0 — только победитель
+}
+
+// loadConfig читает конфиг; отсутствие файла не ошибка, если он не обязателен
+func loadConfig(path string, required bool) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+func saveConfig(path string, cfg *Config) error {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("marshal config: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	return nil
+}
+
+// applyTo подставляет значения конфига во флаги names, которые не заданы явно
+func (c *Config) applyTo(fs *flag.FlagSet, names ...string) error {
+	values := map[string]string{
+		"in":            c.Input,
+		"format":        c.Format,
+		"out":           c.Output,
+		"template":      c.Template,
+		"templates-dir": c.TemplatesDir,
+		"theme":         c.Theme,
+		"theme-file":    c.ThemeFile,
+		"cache-dir":     c.CacheDir,
+	}
+	if len(c.Inputs) > 0 {
+		values["in"] = strings.Join(append(splitInputs(c.Input), c.Inputs...), ",")
+	}
+	if len(c.Languages) > 0 {
+		values["langs"] = strings.Join(c.Languages, ",")
+	}
+	if c.PerChat {
+		values["per-chat"] = "true"
+	}
+	if c.Minimal {
+		values["minimal"] = "true"
+	}
+	if c.SingleFile {
+		values["single-file"] = "true"
+	}
+	if c.Year != 0 {
+		values["year"] = strconv.Itoa(c.Year)
+	}
+	if c.Workers != 0 {
+		values["workers"] = strconv.Itoa(c.Workers)
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	for _, name := range names {
+		v := values[name]
+		if v == "" || set[name] || fs.Lookup(name) == nil {
+			continue
+		}
+		if err := fs.Set(name, v); err != nil {
+			return fmt.Errorf("config %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// applyImages подставляет общие картинки и картинки номинаций
+func (c *Config) applyImages(set *stats.AvatarSet) {
+	if c.Images.Avatar != "" {
+		set.CommonPath = set.Rel(c.Images.Avatar)
+	}
+	if c.Images.Cover != "" {
+		set.CoverPath = set.Rel(c.Images.Cover)
+	}
+	if c.Images.Placeholder != "" {
+		set.PlaceholderPath = set.Rel(c.Images.Placeholder)
+	}
+	for name, n := range c.Nominations {
+		if n.Avatar != "" {
+			set.Nominations[strings.ToLower(name)] = set.Rel(n.Avatar)
+		}
+	}
+}
+
+// applyUsers накладывает ручные имена и аватарки на найденные автоматически
+func (c *Config) applyUsers(set *stats.AvatarSet) {
+	for id, u := range c.Users {
+		if u.Name != "" {
+			set.Names[id] = u.Name
+		}
+		if u.Avatar != "" {
+			set.ByID[id] = set.Rel(u.Avatar)
+		}
+	}
+}
+
+// options — настройки подсчёта из конфига: свои подписи номинаций, формы
+// имён и пол участников для них, пьедесталы, пороги и нормализация текста.
+// Ошибки в них находит stats.NewSettings.
+func (c *Config) options() stats.Options {
+	opts := stats.Options{
+		NameForms:  map[string]stats.NameForms{},
+		Genders:    map[string]string{},
+		Captions:   map[string]string{},
+		Podium:     c.Podium,
+		Podiums:    map[string]int{},
+		Thresholds: c.Thresholds,
+		Normalize:  c.Normalize,
+	}
+	for id, u := range c.Users {
+		if u.Forms != (stats.NameForms{}) {
+			opts.NameForms[id] = u.Forms
+		}
+		if u.Gender != "" {
+			opts.Genders[id] = u.Gender
+		}
+	}
+	for name, n := range c.Nominations {
+		if n.Caption != "" {
+			opts.Captions[name] = n.Caption
+		}
+		if n.Podium != nil {
+			opts.Podiums[name] = *n.Podium
+		}
+	}
+	return opts
+}
+
+// excluded — сообщение участника из exclude
+func (c *Config) excluded(m telegram.Message) bool {
+	for _, key := range c.Exclude {
+		if key == m.FromID || key == m.From {
+			return true
+		}
+	}
+	return false
+}
+
+// pageTitle — заголовок из title; пусто, если его нет
+func (c *Config) pageTitle(year int) (string, error) {
+	if c.Title == "" {
+		return "", nil
+	}
+	t, err := template.New("title").Parse(c.Title)
+	if err != nil {
+		return "", fmt.Errorf("config title: %w", err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, struct{ Year int }{year}); err != nil {
+		return "", fmt.Errorf("config title: %w", err)
+	}
+	return b.String(), nil
+}
+
+// applyNominations добавляет свои номинации, номинации из плагинов и
+// найденные факты, выключает и переставляет номинации по конфигу
+func (c *Config) applyNominations(r *stats.Registry) error {
+	for _, spec := range c.Custom {
+		// filter.text компилируется под цепочку нормализации
+		n, err := stats.CompileCustom(spec, c.Normalize)
+		if err != nil {
+			return fmt.Errorf("config custom: %w", err)
+		}
+		r.Register(n)
+	}
+	for _, path := range c.Plugins {
+		list, err := stats.LoadPlugin(path)
+		if err != nil {
+			return fmt.Errorf("config plugins: %w", err)
+		}
+		for _, n := range list {
+			r.Register(n)
+		}
+	}
+	if c.Churn != nil {
+		r.Register(stats.ChurnNominator(*c.Churn))
+	}
+	for _, n := range stats.DiscoveryNominators(c.Discover) {
+		r.Register(n)
+	}
+	if len(c.Enabled) > 0 {
+		for _, name := range r.Names() {
+			r.SetEnabled(name, false)
+		}
+		for _, name := range c.Enabled {
+			if err := r.SetEnabled(name, true); err != nil {
+				return fmt.Errorf("config enabled: %w", err)
+			}
+		}
+	}
+	names := make([]string, 0, len(c.Nominations))
+	for name := range c.Nominations {
+		names = append(names, name)
+	}
+	sort.Strings(names) // об ошибке — всегда об одной и той же номинации
+	for _, name := range names {
+		if err := r.SetEnabled(name, !c.Nominations[name].Disabled); err != nil {
+			return fmt.Errorf("config nominations: %w", err)
+		}
+	}
+	if err := r.Reorder(c.Order); err != nil {
+		return fmt.Errorf("config order: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bebroedik/year-summary-2025/render"
+	"github.com/bebroedik/year-summary-2025/stats"
+	"github.com/bebroedik/year-summary-2025/telegram"
+	"github.com/rs/zerolog/log"
+)
+
+// размер демо-чата: хватает, чтобы сработали все встроенные номинации
+const (
+	demoUsers    = 8
+	demoMessages = 3000
+	demoYear     = 2025
+)
+
+func cmdDemo(ctx context.Context, args []string) error {
+	fs := newFlagSet("demo", "Render a full page from built-in synthetic data: every card type, the timeline, the leaderboard and the methodology, no export needed.")
+	tmpl := addTemplateFlags(fs)
+	out := fs.String("out", "demo.html", `output HTML file ("-" for stdout)`)
+	seed := fs.Int64("seed", 1, "random seed of the synthetic chat")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	// та же фикстура, что пишет fixture, но через разбор result.json:
+	// так демо проходит тот же путь, что и настоящий экспорт
+	fixture := makeFixture(*seed, demoUsers, demoMessages, demoYear)
+	demoPair(&fixture)
+	data, err := json.Marshal(fixture)
+	if err != nil {
+		return fmt.Errorf("demo: %w", err)
+	}
+	done := stage("parse")
+	var export telegram.ChatExport
+	err = json.Unmarshal(data, &export)
+	done()
+	if err != nil {
+		return fmt.Errorf("demo: %w", err)
+	}
+	runMetrics.count("parse", len(export.Messages))
+
+	done = stage("nominations")
+	res, err := stats.ComputeContext(ctx, export.Messages, stats.Options{
+		Year:        demoYear,
+		Language:    language,
+		Timeline:    true,
+		Methodology: true,
+		Leaderboard: 10,
+	})
+	done()
+	if err != nil {
+		return fmt.Errorf("demo: %w", err)
+	}
+	runMetrics.count("nominations", len(res.Page.Nominations))
+
+	defer stage("render")()
+	if err := render.GenerateContext(ctx, tmpl, *out, res.Page); err != nil {
+		return fmt.Errorf("demo: %w", err)
+	}
+	log.Info().Str("out", *out).Int("nominations", len(res.Page.Nominations)).Msg("demo page generated")
+	return nil
+}
+
+// demoPair добавляет пару, которая отвечает друг другу в те же дни, — в
+// случайной фикстуре её нет, и «Синхронных душ» без неё не было бы
+func demoPair(export *fixtureExport) {
+	const a, b = "user1002", "user1003"
+	var name string
+	for _, m := range export.Messages {
+		if m.FromID == b {
+			name = m.From
+			break
+		}
+	}
+	next := int64(len(export.Messages)) + 1000
+	for _, m := range export.Messages {
+		if m.FromID != a || m.Type != "message" {
+			continue
+		}
+		reply := m
+		reply.ID, next = next, next+1
+		reply.From, reply.FromID = name, b
+		reply.Reactions, reply.Story, reply.ForwardedFrom, reply.ForwardedFromID = nil, nil, "", ""
+		export.Messages = append(export.Messages, reply)
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// структуры в формате result.json — Message умеет только читать его
+type fixtureExport struct {
+	Name     string           `json:"name"`
+	Type     string           `json:"type"`
+	ID       int64            `json:"id"`
+	Messages []fixtureMessage `json:"messages"`
+}
+
+type fixtureMessage struct {
+	ID              int64                   `json:"id"`
+	Type            string                  `json:"type"`
+	Date            string                  `json:"date"`
+	DateUnix        string                  `json:"date_unixtime"`
+	From            string                  `json:"from,omitempty"`
+	FromID          string                  `json:"from_id,omitempty"`
+	Text            any                     `json:"text"`
+	TextEntities    []telegram.TextFragment `json:"text_entities"`
+	MediaType       string                  `json:"media_type,omitempty"`
+	DurationSeconds int                     `json:"duration_seconds,omitempty"`
+	ViaBot          string                  `json:"via_bot,omitempty"`
+	File            string                  `json:"file,omitempty"`
+	MimeType        string                  `json:"mime_type,omitempty"`
+	Photo           string                  `json:"photo,omitempty"`
+	ForwardedFrom   string                  `json:"forwarded_from,omitempty"`
+	ForwardedFromID string                  `json:"forwarded_from_id,omitempty"`
+	Story           *fixtureStory           `json:"story,omitempty"`
+	ReplyToStoryID  int64                   `json:"reply_to_story_id,omitempty"`
+	Reactions       []fixtureReaction       `json:"reactions,omitempty"`
+
+	Actor   string `json:"actor,omitempty"`
+	ActorID string `json:"actor_id,omitempty"`
+	Action  string `json:"action,omitempty"`
+	Boosts  int    `json:"boosts,omitempty"`
+}
+
+type fixtureStory struct {
+	ID     int64  `json:"id"`
+	FromID string `json:"from_id"`
+}
+
+type fixtureReaction struct {
+	Type   string          `json:"type"`
+	Count  int             `json:"count"`
+	Emoji  string          `json:"emoji"`
+	Recent []fixtureRecent `json:"recent,omitempty"`
+}
+
+type fixtureRecent struct {
+	From   string `json:"from"`
+	FromID string `json:"from_id"`
+	Date   string `json:"date"`
+}
+
+var fixtureTexts = []string{
+	"привет всем",
+	"кто сегодня идёт?",
+	"ахахаха 😂",
+	"смотрите https://www.tiktok.com/@cat/video/1",
+	"ну такое",
+	"я опоздаю минут на 10 🙏",
+	"🔥🔥🔥",
+	"это лучший чат на свете ❤",
+	"кто-нибудь видел мои ключи?",
+	"скинул фотки с выходных",
+}
+
+var fixtureMedia = []string{"", "", "", "", "", "sticker", "video_message", "voice_message", "animation"}
+
+var fixtureEmoji = []string{"👍", "❤", "😂", "🔥", "🤡"}
+
+// makeFixture строит детерминированный синтетический экспорт
+func makeFixture(seed int64, users, n, year int) fixtureExport {
+	rnd := rand.New(rand.NewSource(seed))
+
+	type user struct{ id, name string }
+	people := make([]user, users)
+	for i := range people {
+		people[i] = user{
+			id:   fmt.Sprintf("user%d", 1000+i),
+			name: fmt.Sprintf("Участник %d", i+1),
+		}
+	}
+
+	start := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	step := time.Duration(int64(365*24*time.Hour) / int64(n+1))
+
+	export := fixtureExport{Name: "Тестовый чат", Type: "private_supergroup", ID: 1}
+	for i := 0; i < n; i++ {
+		// чем меньше индекс, тем активнее участник
+		u := people[int(float64(users)*rnd.Float64()*rnd.Float64())]
+		date := start.Add(step * time.Duration(i+1)).Add(time.Duration(rnd.Intn(3600)) * time.Second)
+
+		m := fixtureMessage{
+			ID:           int64(i + 1),
+			Type:         "message",
+			Date:         date.Format("2006-01-02T15:04:05"),
+			DateUnix:     strconv.FormatInt(date.Unix(), 10),
+			From:         u.name,
+			FromID:       u.id,
+			Text:         "",
+			TextEntities: []telegram.TextFragment{},
+			MediaType:    fixtureMedia[rnd.Intn(len(fixtureMedia))],
+		}
+
+		switch {
+		case m.MediaType == "voice_message" || m.MediaType == "video_message":
+			m.DurationSeconds = 3 + i*7%180 // без rnd, чтобы не сдвигать остальные данные фикстуры
+		case m.MediaType != "":
+		case rnd.Intn(10) == 0:
+			m.Photo = fmt.Sprintf("photos/photo_%d.jpg", i)
+		case rnd.Intn(15) == 0:
+			mention := people[rnd.Intn(users)]
+			text := "@" + mention.id
+			m.Text = []any{telegram.TextFragment{Type: "mention", Text: text}, " смотри"}
+			m.TextEntities = []telegram.TextFragment{{Type: "mention", Text: text}, {Type: "plain", Text: " смотри"}}
+		default:
+			text := fixtureTexts[rnd.Intn(len(fixtureTexts))]
+			m.Text = text
+			m.TextEntities = []telegram.TextFragment{{Type: "plain", Text: text}}
+		}
+
+		// половина гифок — результаты @gif: mp4 без media_type, как в настоящем экспорте
+		if m.MediaType == "animation" && i%2 == 0 {
+			m.MediaType, m.ViaBot = "", "@gif"
+			m.File, m.MimeType = fmt.Sprintf("video_files/gif_%d.mp4", i), "video/mp4"
+		}
+
+		// пересланные сторис — без текста, как в экспорте; тоже без rnd
+		switch {
+		case i%53 == 0 && m.MediaType == "" && m.Photo == "":
+			m.Text, m.TextEntities = "", []telegram.TextFragment{}
+			m.Story = &fixtureStory{ID: int64(i), FromID: people[i%users].id}
+		case i%61 == 0 && m.MediaType == "":
+			m.ReplyToStoryID = int64(i)
+		}
+
+		if rnd.Intn(20) == 0 {
+			// каждая третья пересылка — от человека, остальные — репосты из канала
+			if from := people[i%users]; i%3 == 0 {
+				m.ForwardedFrom, m.ForwardedFromID = from.name, from.id
+			} else {
+				m.ForwardedFrom, m.ForwardedFromID = "Новостной канал", "channel1001234567"
+			}
+		}
+
+		if rnd.Intn(4) == 0 {
+			r := fixtureReaction{Type: "emoji", Emoji: fixtureEmoji[rnd.Intn(len(fixtureEmoji))]}
+			for _, p := range people {
+				if rnd.Intn(3) == 0 {
+					r.Recent = append(r.Recent, fixtureRecent{From: p.name, FromID: p.id, Date: m.Date})
+				}
+			}
+			r.Count = len(r.Recent) + 1
+			m.Reactions = append(m.Reactions, r)
+		}
+
+		// у первых трёх участников Premium: реакции кастомными эмодзи
+		if i%37 == 0 {
+			p := people[i%3]
+			m.Reactions = append(m.Reactions, fixtureReaction{Type: "custom_emoji", Count: 1, Recent: []fixtureRecent{{From: p.name, FromID: p.id, Date: m.Date}}})
+		}
+
+		export.Messages = append(export.Messages, m)
+	}
+
+	// бусты — service-сообщения, по одному в месяц от первых двух участников
+	for month := 1; month <= 12; month++ {
+		date := time.Date(year, time.Month(month), 15, 12, 0, 0, 0, time.UTC)
+		p := people[month/8%users]
+		export.Messages = append(export.Messages, fixtureMessage{
+			ID:           int64(n + month),
+			Type:         "service",
+			Date:         date.Format("2006-01-02T15:04:05"),
+			DateUnix:     strconv.FormatInt(date.Unix(), 10),
+			Text:         "",
+			TextEntities: []telegram.TextFragment{},
+			Actor:        p.name,
+			ActorID:      p.id,
+			Action:       "boost_apply",
+			Boosts:       1 + month%2,
+		})
+	}
+	return export
+}
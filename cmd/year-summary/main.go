@@ -0,0 +1,35 @@
+// Команда year-summary — итоги года по экспорту чата: HTML-страница,
+// предпросмотр, проверка шаблона и выгрузка номинаций. Разбор экспортов,
+// номинации и шаблоны живут в пакетах telegram, stats и render.
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		// первый Ctrl+C прерывает аккуратно, второй — сразу
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := run(ctx, os.Args[1:])
+	if err == nil {
+		runMetrics.log()
+	}
+	if errors.Is(err, context.Canceled) {
+		log.Warn().Msg("interrupted")
+		os.Exit(130)
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("year-summary")
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// runStats — сводка запуска для лога: сколько сообщений разобрано и за
+// сколько, сколько номинаций посчитано, сколько занял вывод. Пишется в
+// конце любой команды, в отличие от -profile, которому нужен флаг.
+type runStats struct {
+	mu     sync.Mutex
+	stages map[string]stageStat
+}
+
+type stageStat struct {
+	took  time.Duration
+	count int // сообщений у parse, номинаций у nominations
+}
+
+var runMetrics = &runStats{stages: map[string]stageStat{}}
+
+// stage засекает этап и для сводки запуска, и для -profile:
+//
+//	defer stage("render")()
+//
+// В serve этапы повторяются на каждый запрос, в сводке остаётся последний.
+func stage(name string) func() {
+	profiled := prof.stage(name)
+	start := time.Now()
+	return func() {
+		profiled()
+		runMetrics.mu.Lock()
+		s := runMetrics.stages[name]
+		s.took = time.Since(start)
+		runMetrics.stages[name] = s
+		runMetrics.mu.Unlock()
+	}
+}
+
+// count запоминает, сколько всего прошло через этап name
+func (r *runStats) count(name string, n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.stages[name]
+	s.count = n
+	r.stages[name] = s
+}
+
+// log пишет сводку одной строкой: «parsed 1.2M msgs in 8.3s, 14 nominations
+// in 0.9s, rendered in 120ms»; числа ещё и отдельными полями, для разбора лога
+func (r *runStats) log() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.stages) == 0 {
+		return
+	}
+	ev := log.Info()
+	var parts []string
+	if s, ok := r.stages["parse"]; ok {
+		parts = append(parts, fmt.Sprintf("parsed %s msgs in %s", shortCount(s.count), shortDuration(s.took)))
+		ev = ev.Int("messages", s.count).Dur("parse", s.took)
+	}
+	if s, ok := r.stages["filter"]; ok {
+		ev = ev.Dur("filter", s.took)
+	}
+	if s, ok := r.stages["nominations"]; ok {
+		parts = append(parts, fmt.Sprintf("%d nominations in %s", s.count, shortDuration(s.took)))
+		ev = ev.Int("nominations", s.count).Dur("compute", s.took)
+	}
+	if s, ok := r.stages["render"]; ok {
+		parts = append(parts, "rendered in "+shortDuration(s.took))
+		ev = ev.Dur("render", s.took)
+	}
+	ev.Msg(strings.Join(parts, ", "))
+}
+
+// shortCount — 950, 12.3k, 200k, 1.2M
+func shortCount(n int) string {
+	short := func(v float64, unit string) string {
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", v), ".0") + unit
+	}
+	switch {
+	case n >= 1_000_000:
+		return short(float64(n)/1e6, "M")
+	case n >= 1_000:
+		return short(float64(n)/1e3, "k")
+	}
+	return fmt.Sprint(n)
+}
+
+// shortDuration — 8.3s, 120ms
+func shortDuration(d time.Duration) string {
+	if d >= time.Second {
+		return d.Round(100 * time.Millisecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
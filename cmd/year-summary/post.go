@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/render"
+	"github.com/bebroedik/year-summary-2025/stats"
+	"github.com/rs/zerolog/log"
+)
+
+// captionLimit — сколько символов Telegram принимает в подписи к фото
+const captionLimit = 1024
+
+func cmdPost(ctx context.Context, args []string) error {
+	fs := newFlagSet("post", "Post the nominations into a Telegram chat through a bot, one message per nomination.")
+	in := addInputFlags(fs)
+	token := fs.String("token", "", "bot token from @BotFather; by default $YEAR_SUMMARY_BOT_TOKEN")
+	chat := fs.String("chat", "", "chat to post into: numeric chat id or @channel; the bot must be a member")
+	delay := fs.Duration("delay", 0, "pause between messages, e.g. 30s for a live award ceremony")
+	cards := fs.Bool("cards", false, "send every nomination as a PNG card with the text as its caption")
+	dryRun := fs.Bool("dry-run", false, "print the messages instead of sending them")
+	apiURL := fs.String("api-url", "https://api.telegram.org", "Bot API server, for a local telegram-bot-api")
+	in.addOnlyFlag(fs)
+	if err := in.parse(fs, args); err != nil {
+		return err
+	}
+	if *token == "" {
+		*token = os.Getenv("YEAR_SUMMARY_BOT_TOKEN")
+	}
+	if !*dryRun && (*token == "" || *chat == "") {
+		return errors.New("post: -token (or $YEAR_SUMMARY_BOT_TOKEN) and -chat are required")
+	}
+
+	messages, err := in.load(ctx, ".")
+	if err != nil {
+		return err
+	}
+	page, err := in.page(ctx, messages)
+	if err != nil {
+		return err
+	}
+	defer stage("render")()
+	posts := render.Posts(page)
+	if *dryRun {
+		for _, p := range posts {
+			fmt.Println(p.Text)
+			fmt.Println()
+		}
+		return nil
+	}
+
+	bot := &botAPI{base: strings.TrimSuffix(*apiURL, "/") + "/bot" + *token, chat: *chat, client: &http.Client{Timeout: time.Minute}}
+	for i, p := range posts {
+		if i > 0 && *delay > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(*delay):
+			}
+		}
+		if err := bot.post(ctx, p, *cards); err != nil {
+			return fmt.Errorf("post %d of %d: %w", i+1, len(posts), err)
+		}
+		log.Info().Int("n", i+1).Int("of", len(posts)).Msg("posted")
+	}
+	return nil
+}
+
+func cmdOnThisDay(ctx context.Context, args []string) error {
+	fs := newFlagSet("on-this-day", "Print what happened in the chat on this day a year ago as a Telegram post, or send it with -chat: for a nostalgia bot run from cron.")
+	in := addInputFlags(fs)
+	date := fs.String("date", "", "today's date as 2006-01-02, the post is about a year before it; by default today")
+	days := fs.Int("days", 1, "cover this many days ending a year ago, e.g. 7 for a weekly post")
+	token := fs.String("token", "", "bot token from @BotFather; by default $YEAR_SUMMARY_BOT_TOKEN")
+	chat := fs.String("chat", "", "send the post to this chat (numeric id or @channel) instead of printing it")
+	apiURL := fs.String("api-url", "https://api.telegram.org", "Bot API server, for a local telegram-bot-api")
+	if err := in.parse(fs, args); err != nil {
+		return err
+	}
+	today := time.Now()
+	if *date != "" {
+		t, err := time.ParseInLocation(time.DateOnly, *date, time.Local)
+		if err != nil {
+			return fmt.Errorf("-date: %w", err)
+		}
+		today = t
+	}
+	if *token == "" {
+		*token = os.Getenv("YEAR_SUMMARY_BOT_TOKEN")
+	}
+	if *chat != "" && *token == "" {
+		return errors.New("on-this-day: -chat needs -token (or $YEAR_SUMMARY_BOT_TOKEN)")
+	}
+
+	// год берётся из даты, а не из -year и конфига
+	day := stats.YearAgo(today)
+	in.Year = day.Year()
+	messages, err := in.load(ctx, ".")
+	if err != nil {
+		return err
+	}
+	text := render.OnThisDayPost(stats.OnThisDayOf(in.settings, messages, day, *days))
+	if *chat == "" {
+		fmt.Println(text)
+		return nil
+	}
+	bot := &botAPI{base: strings.TrimSuffix(*apiURL, "/") + "/bot" + *token, chat: *chat, client: &http.Client{Timeout: time.Minute}}
+	if err := bot.sendMessage(ctx, text); err != nil {
+		return fmt.Errorf("on-this-day: %w", err)
+	}
+	log.Info().Str("day", day.Format(time.DateOnly)).Msg("posted")
+	return nil
+}
+
+// botAPI — минимальный клиент Bot API: только отправка в один чат
+type botAPI struct {
+	base   string // https://api.telegram.org/bot<token>, в ошибки не попадает
+	chat   string
+	client *http.Client
+}
+
+// post отправляет сообщение; с cards номинация уходит карточкой, текст —
+// подписью, а если он длиннее подписи — следующим сообщением
+func (b *botAPI) post(ctx context.Context, p render.Post, cards bool) error {
+	if !cards || p.Nomination == nil {
+		return b.sendMessage(ctx, p.Text)
+	}
+	img, err := render.Card(*p.Nomination, ".")
+	if err != nil {
+		log.Warn().Err(err).Msg("image not drawn, using a placeholder")
+	}
+	var card bytes.Buffer
+	if err := png.Encode(&card, img); err != nil {
+		return err
+	}
+	caption := p.Text
+	if len([]rune(caption)) > captionLimit {
+		caption = ""
+	}
+	if err := b.sendPhoto(ctx, card.Bytes(), caption); err != nil {
+		return err
+	}
+	if caption == "" {
+		return b.sendMessage(ctx, p.Text)
+	}
+	return nil
+}
+
+func (b *botAPI) sendMessage(ctx context.Context, text string) error {
+	form := url.Values{"chat_id": {b.chat}, "text": {text}, "parse_mode": {"HTML"}, "disable_web_page_preview": {"true"}}
+	return b.call(ctx, "sendMessage", func() (io.Reader, string) {
+		return strings.NewReader(form.Encode()), "application/x-www-form-urlencoded"
+	})
+}
+
+func (b *botAPI) sendPhoto(ctx context.Context, photo []byte, caption string) error {
+	return b.call(ctx, "sendPhoto", func() (io.Reader, string) {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		w.WriteField("chat_id", b.chat)
+		if caption != "" {
+			w.WriteField("caption", caption)
+			w.WriteField("parse_mode", "HTML")
+		}
+		fw, _ := w.CreateFormFile("photo", "card.png")
+		fw.Write(photo)
+		w.Close()
+		return &body, w.FormDataContentType()
+	})
+}
+
+// botRetries — сколько раз повторять запрос, на который Telegram ответил
+// «слишком часто» (429)
+const botRetries = 3
+
+// call выполняет метод; тело собирается заново на каждую попытку
+func (b *botAPI) call(ctx context.Context, method string, body func() (io.Reader, string)) error {
+	for attempt := 0; ; attempt++ {
+		data, contentType := body()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.base+"/"+method, data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", method, err)
+		}
+		req.Header.Set("Content-Type", contentType)
+		resp, err := b.client.Do(req)
+		if err != nil {
+			// в тексте ошибки URL с токеном
+			var ue *url.Error
+			if errors.As(err, &ue) {
+				ue.URL = method
+			}
+			return fmt.Errorf("%s: %w", method, err)
+		}
+		var res struct {
+			OK          bool   `json:"ok"`
+			Description string `json:"description"`
+			Parameters  struct {
+				RetryAfter int `json:"retry_after"`
+			} `json:"parameters"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&res)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", method, resp.Status, err)
+		}
+		if res.OK {
+			return nil
+		}
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < botRetries {
+			wait := time.Duration(res.Parameters.RetryAfter) * time.Second
+			log.Warn().Dur("wait", wait).Msg("telegram asks to slow down")
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+			continue
+		}
+		return fmt.Errorf("%s: %s", method, res.Description)
+	}
+}
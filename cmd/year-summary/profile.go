@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+)
+
+// profiler — -profile: профили CPU и памяти для go tool pprof и время по
+// этапам (разбор, фильтры, каждая номинация, вывод)
+type profiler struct {
+	dir    string
+	start  time.Time
+	cpu    *os.File
+	stages []stageTime
+}
+
+type stageTime struct {
+	name string
+	took time.Duration
+	sub  bool // номинация внутри этапа nominations
+}
+
+// prof — профайлер запуска; nil, если -profile не задан, и тогда все его
+// методы ничего не делают
+var prof *profiler
+
+func startProfile(dir string) (*profiler, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("profile dir: %w", err)
+	}
+	cpu, err := os.Create(filepath.Join(dir, "cpu.pprof"))
+	if err != nil {
+		return nil, fmt.Errorf("cpu profile: %w", err)
+	}
+	if err := pprof.StartCPUProfile(cpu); err != nil {
+		cpu.Close()
+		return nil, fmt.Errorf("cpu profile: %w", err)
+	}
+	return &profiler{dir: dir, start: time.Now(), cpu: cpu}, nil
+}
+
+// stage засекает этап до вызова возвращённой функции:
+//
+//	defer prof.stage("render")()
+func (p *profiler) stage(name string) func() {
+	if p == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		p.stages = append(p.stages, stageTime{name: name, took: time.Since(start)})
+	}
+}
+
+// nominations добавляет время номинаций под этапом nominations
+func (p *profiler) nominations(list []stats.Timing) {
+	if p == nil {
+		return
+	}
+	for _, t := range list {
+		p.stages = append(p.stages, stageTime{name: t.Name, took: t.Duration, sub: true})
+	}
+}
+
+// stop дописывает профиль памяти и печатает время по этапам в w
+func (p *profiler) stop(w io.Writer) error {
+	if p == nil {
+		return nil
+	}
+	pprof.StopCPUProfile()
+	if err := p.cpu.Close(); err != nil {
+		return fmt.Errorf("cpu profile: %w", err)
+	}
+
+	heap, err := os.Create(filepath.Join(p.dir, "heap.pprof"))
+	if err != nil {
+		return fmt.Errorf("heap profile: %w", err)
+	}
+	runtime.GC() // в профиле — то, что живо, а не мусор
+	if err := pprof.WriteHeapProfile(heap); err != nil {
+		heap.Close()
+		return fmt.Errorf("heap profile: %w", err)
+	}
+	if err := heap.Close(); err != nil {
+		return fmt.Errorf("heap profile: %w", err)
+	}
+
+	fmt.Fprintf(w, "Profile: %s, %s\n", p.cpu.Name(), heap.Name())
+	for _, s := range p.stages {
+		if s.sub {
+			// номинации считаются параллельно, их время в сумме больше этапа
+			fmt.Fprintf(w, "    %-24s %10s\n", s.name, s.took.Round(time.Millisecond))
+			continue
+		}
+		fmt.Fprintf(w, "  %-26s %10s\n", s.name, s.took.Round(time.Millisecond))
+	}
+	fmt.Fprintf(w, "  %-26s %10s\n", "total", time.Since(p.start).Round(time.Millisecond))
+	return nil
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/bebroedik/year-summary-2025/render"
+	"github.com/bebroedik/year-summary-2025/stats"
+	"github.com/bebroedik/year-summary-2025/telegram"
+	"github.com/rs/zerolog/log"
+)
+
+// previewServer отдаёт страницу, числа под ней и админку аватарок; экспорт
+// читается один раз
+type previewServer struct {
+	in         *inputFlags
+	tmpl       *render.Templates
+	addr       string // -addr: с ним сверяется Host загрузок в админке
+	avatarsDir string
+	messages   []telegram.Message
+	agg        *stats.Aggregates // от аватарок не зависят, считаются при старте
+
+	mu      sync.RWMutex
+	page    stats.PageData
+	files   http.Handler  // картинки страницы, см. pageFiles
+	live    bool          // -watch: подмешивать в страницу скрипт перезагрузки
+	changed chan struct{} // закрывается при изменении страницы, см. notify
+}
+
+func cmdServe(ctx context.Context, args []string) error {
+	fs := newFlagSet("serve", "Serve the rendered page locally; the template and partials are re-read on every request.\nThe numbers are at /stats.json, /nominations.json and /members.csv; avatars can be uploaded and cropped at /admin.\nWith -watch open tabs reload themselves when the template, partials or config change.")
+	in := addInputFlags(fs)
+	tmpl := addTemplateFlags(fs)
+	addr := fs.String("addr", "localhost:8080", "listen address")
+	avatarsDir := fs.String("avatars-dir", "avatars", "where avatars uploaded via /admin are stored")
+	watchFlag := fs.Bool("watch", false, "reload open pages when the template, partials or config change; config changes recompute the page")
+	in.addOnlyFlag(fs)
+	if err := in.parse(fs, args, "template", "templates-dir", "theme", "theme-file"); err != nil {
+		return err
+	}
+
+	all, err := in.read(ctx)
+	if err != nil {
+		return err
+	}
+	messages, err := in.prepare(all, ".")
+	if err != nil {
+		return err
+	}
+
+	srv := &previewServer{in: in, tmpl: tmpl, addr: *addr, avatarsDir: *avatarsDir, messages: messages,
+		agg: stats.ComputeAggregates(in.settings, messages), live: *watchFlag, changed: make(chan struct{})}
+	if err := srv.rebuild(ctx); err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", srv.handlePage)
+	mux.HandleFunc("/stats.json", srv.handleStats)
+	mux.HandleFunc("/nominations.json", srv.handleNominations)
+	mux.HandleFunc("/members.csv", srv.handleMembers)
+	mux.HandleFunc("/livereload", srv.handleLiveReload)
+	mux.HandleFunc("/admin", srv.handleAdmin)
+	mux.HandleFunc("/admin/avatar", srv.handleAvatarUpload)
+
+	// запросы живут не дольше сервера: иначе открытая /livereload не даст
+	// Shutdown завершиться
+	server := &http.Server{Addr: *addr, Handler: mux, BaseContext: func(net.Listener) context.Context { return ctx }}
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+	if *watchFlag {
+		go watch(ctx, in, tmpl, func(reload bool) error {
+			return srv.update(ctx, all, reload)
+		})
+	}
+
+	log.Info().Str("addr", "http://"+*addr).Msg("serving")
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return ctx.Err()
+}
+
+// rebuild пересчитывает номинации, например после смены аватарки
+func (s *previewServer) rebuild(ctx context.Context) error {
+	uploadMu.Lock()
+	page, err := s.in.page(ctx, s.messages)
+	files := pageFiles(s.in.settings.Avatars(), s.avatarsDir)
+	uploadMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.page = page
+	s.files = files
+	s.mu.Unlock()
+	s.notify()
+	return nil
+}
+
+// update — для -watch: шаблон и так читается на каждый запрос, но страницу
+// надо пересчитать и перезагрузить во вкладках. reload — поменялся конфиг:
+// сообщения отбираются из all заново под uploadMu, иначе загрузка аватарки
+// или админка увидели бы конфиг и настройки наполовину заменёнными.
+func (s *previewServer) update(ctx context.Context, all []telegram.Message, reload bool) error {
+	if reload {
+		uploadMu.Lock()
+		messages, err := s.in.reloadMessages(all, ".")
+		if err == nil {
+			s.messages = messages
+		}
+		uploadMu.Unlock()
+		if err != nil {
+			return fmt.Errorf("reload config: %w", err)
+		}
+	}
+	return s.rebuild(ctx)
+}
+
+// notify будит всех, кто ждёт в /livereload
+func (s *previewServer) notify() {
+	s.mu.Lock()
+	close(s.changed)
+	s.changed = make(chan struct{})
+	s.mu.Unlock()
+}
+
+// handleLiveReload — поток server-sent events: «reload» на каждое изменение
+func (s *previewServer) handleLiveReload(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher.Flush()
+	for {
+		s.mu.RLock()
+		changed := s.changed
+		s.mu.RUnlock()
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+			fmt.Fprint(w, "data: reload\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// liveReloadScript перезагружает вкладку по событию из /livereload
+const liveReloadScript = `<script>new EventSource("/livereload").onmessage = () => location.reload();</script>`
+
+// withLiveReload вставляет liveReloadScript перед </body>, а без него — в конец
+func withLiveReload(page []byte) []byte {
+	i := bytes.LastIndex(bytes.ToLower(page), []byte("</body>"))
+	if i < 0 {
+		return append(page, liveReloadScript...)
+	}
+	return slices.Concat(page[:i], []byte(liveReloadScript), page[i:])
+}
+
+func (s *previewServer) handlePage(w http.ResponseWriter, r *http.Request) {
+	// всё, кроме самой страницы, — её картинки, см. pageFiles
+	if r.URL.Path != "/" {
+		s.mu.RLock()
+		files := s.files
+		s.mu.RUnlock()
+		files.ServeHTTP(w, r)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := render.Render(&buf, s.tmpl, s.current()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if s.live {
+		w.Write(withLiveReload(buf.Bytes()))
+		return
+	}
+	w.Write(buf.Bytes())
+}
+
+// pageFiles отдаёт только папки, из которых страница берёт картинки, и
+// папку загруженных аватарок. Вся текущая папка открыта быть не должна:
+// там лежат экспорт, конфиг и кэш, а minimal и opt_out прячут их содержимое
+// и со страницы. Картинки прямо в текущей папке отдаются по одной.
+func pageFiles(avatars *stats.AvatarSet, avatarsDir string) http.Handler {
+	files := map[string]bool{}
+	dirs := map[string]http.Handler{}
+	addDir := func(dir string) {
+		dir = filepath.ToSlash(filepath.Clean(dir))
+		if dir != "." && filepath.IsLocal(dir) && dirs[dir] == nil {
+			dirs[dir] = http.StripPrefix("/"+dir, http.FileServer(http.Dir(dir)))
+		}
+	}
+	addDir(avatarsDir)
+	for _, p := range avatars.Local() {
+		if dir := path.Dir(p); dir != "." {
+			addDir(dir)
+		} else {
+			files[p] = true
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := path.Clean(r.URL.Path)[1:]
+		if files[name] {
+			http.ServeFile(w, r, filepath.FromSlash(name))
+			return
+		}
+		// самая длинная подходящая папка: avatars/big раньше avatars
+		for dir := path.Dir(name); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			if h := dirs[dir]; h != nil {
+				h.ServeHTTP(w, r)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+}
+
+func (s *previewServer) current() stats.PageData {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.page
+}
+
+// handleStats — то же, что generate -out-format json: страница и агрегаты
+func (s *previewServer) handleStats(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := render.JSON(&buf, s.current(), s.agg); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(buf.Bytes())
+}
+
+// handleNominations — то же, что export-stats: только номинации страницы
+func (s *previewServer) handleNominations(w http.ResponseWriter, r *http.Request) {
+	data, err := json.MarshalIndent(s.current(), "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append(data, '\n'))
+}
+
+func (s *previewServer) handleMembers(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := render.Table(&buf, "csv", s.agg); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/render"
+	"github.com/rs/zerolog/log"
+)
+
+// telegraphLimit — больше createPage не принимает
+const telegraphLimit = 64 << 10
+
+func cmdTelegraph(ctx context.Context, args []string) error {
+	fs := newFlagSet("telegraph", "Publish the nominations as a telegra.ph page and print its URL.")
+	in := addInputFlags(fs)
+	token := fs.String("token", "", "Telegraph access token, to publish under your account; by default $YEAR_SUMMARY_TELEGRAPH_TOKEN, otherwise a new account is created")
+	author := fs.String("author", "", "author name under the page title")
+	apiURL := fs.String("api-url", "https://api.telegra.ph", "Telegraph API server")
+	in.addOnlyFlag(fs)
+	if err := in.parse(fs, args); err != nil {
+		return err
+	}
+	if *token == "" {
+		*token = os.Getenv("YEAR_SUMMARY_TELEGRAPH_TOKEN")
+	}
+
+	messages, err := in.load(ctx, ".")
+	if err != nil {
+		return err
+	}
+	page, err := in.page(ctx, messages)
+	if err != nil {
+		return err
+	}
+	defer stage("render")()
+	nodes := render.Telegraph(page)
+	if len(nodes) == 0 {
+		return errors.New("telegraph: nothing to publish")
+	}
+	content, err := json.Marshal(nodes)
+	if err != nil {
+		return err
+	}
+	if len(content) > telegraphLimit {
+		return fmt.Errorf("telegraph: page is %d KB, telegra.ph takes up to %d KB; leave fewer nominations with -only", len(content)>>10, telegraphLimit>>10)
+	}
+
+	tg := &telegraphAPI{base: strings.TrimSuffix(*apiURL, "/"), client: &http.Client{Timeout: time.Minute}}
+	if *token == "" {
+		// без своего аккаунта страницу потом не отредактировать — токен в лог
+		var account struct {
+			AccessToken string `json:"access_token"`
+		}
+		if err := tg.call(ctx, "createAccount", url.Values{"short_name": {"year-summary"}, "author_name": {*author}}, &account); err != nil {
+			return fmt.Errorf("telegraph: %w", err)
+		}
+		*token = account.AccessToken
+		log.Info().Str("token", *token).Msg("telegraph account created, pass -token to edit the page later")
+	}
+	var created struct {
+		URL string `json:"url"`
+	}
+	form := url.Values{
+		"access_token": {*token},
+		"title":        {page.Title},
+		"author_name":  {*author},
+		"content":      {string(content)},
+	}
+	if err := tg.call(ctx, "createPage", form, &created); err != nil {
+		return fmt.Errorf("telegraph: %w", err)
+	}
+	fmt.Println(created.URL)
+	return nil
+}
+
+// telegraphAPI — клиент api.telegra.ph: метод, форма, {"ok", "result", "error"}
+type telegraphAPI struct {
+	base   string
+	client *http.Client
+}
+
+func (t *telegraphAPI) call(ctx context.Context, method string, form url.Values, result any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.base+"/"+method, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	defer resp.Body.Close()
+	var res struct {
+		OK     bool            `json:"ok"`
+		Result json.RawMessage `json:"result"`
+		Error  string          `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return fmt.Errorf("%s: %s: %w", method, resp.Status, err)
+	}
+	if !res.OK {
+		if res.Error == "" {
+			res.Error = resp.Status
+		}
+		return fmt.Errorf("%s: %s", method, res.Error)
+	}
+	if err := json.Unmarshal(res.Result, result); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/render"
+	"github.com/bebroedik/year-summary-2025/telegram"
+	"github.com/rs/zerolog/log"
+)
+
+// watchInterval — как часто -watch проверяет файлы; watchSettle — сколько
+// ждать после изменения, пока редактор допишет файл
+const (
+	watchInterval = 300 * time.Millisecond
+	watchSettle   = 100 * time.Millisecond
+)
+
+// watcher следит за файлами по времени изменения. Файлов немного —
+// шаблон, части и конфиг, — так что опроса хватает и без fsnotify.
+// Папка — это все файлы в ней, без подпапок.
+type watcher struct {
+	paths []string
+	seen  map[string]time.Time
+}
+
+func newWatcher(paths ...string) *watcher {
+	w := &watcher{}
+	for _, p := range paths {
+		if p != "" {
+			w.paths = append(w.paths, p)
+		}
+	}
+	w.seen = w.stamp()
+	return w
+}
+
+// watchFiles — что править при разработке шаблона: конфиг, файл шаблона,
+// если это не встроенный, папка частей и файл темы
+func watchFiles(in *inputFlags, tmpl *render.Templates) *watcher {
+	return newWatcher(in.Config, tmpl.File, tmpl.Dir, tmpl.ThemeFile)
+}
+
+func (w *watcher) stamp() map[string]time.Time {
+	stamps := map[string]time.Time{}
+	for _, p := range w.paths {
+		st, err := os.Stat(p)
+		if err != nil {
+			continue // файла ещё нет или это встроенный шаблон
+		}
+		if !st.IsDir() {
+			stamps[p] = st.ModTime()
+			continue
+		}
+		entries, _ := os.ReadDir(p)
+		for _, e := range entries {
+			if info, err := e.Info(); err == nil && !e.IsDir() {
+				stamps[filepath.Join(p, e.Name())] = info.ModTime()
+			}
+		}
+	}
+	return stamps
+}
+
+// wait ждёт, пока что-то изменится, появится или пропадёт, и возвращает
+// изменившиеся файлы
+func (w *watcher) wait(ctx context.Context) ([]string, error) {
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+		now := w.stamp()
+		if changed := diffStamps(w.seen, now); len(changed) > 0 {
+			time.Sleep(watchSettle)
+			w.seen = w.stamp()
+			return diffStamps(now, w.seen, changed...), nil
+		}
+	}
+}
+
+// diffStamps — файлы, которые есть только в одном из снимков или изменились,
+// плюс уже известные changed
+func diffStamps(old, now map[string]time.Time, changed ...string) []string {
+	seen := map[string]bool{}
+	for _, p := range changed {
+		seen[p] = true
+	}
+	for p, t := range now {
+		if o, ok := old[p]; (!ok || !o.Equal(t)) && !seen[p] {
+			seen[p] = true
+			changed = append(changed, p)
+		}
+	}
+	for p := range old {
+		if _, ok := now[p]; !ok && !seen[p] {
+			seen[p] = true
+			changed = append(changed, p)
+		}
+	}
+	return changed
+}
+
+// watch ждёт изменений и вызывает update; reload — поменялся конфиг, и
+// сообщения года надо отобрать заново (reloadMessages). Сам watch настроек
+// in не трогает: update делает это там же, где их читают, — в serve под
+// uploadMu. Ошибки пишутся в лог: поправили шаблон — и в следующий раз
+// получится. Возвращается по Ctrl+C.
+func watch(ctx context.Context, in *inputFlags, tmpl *render.Templates, update func(reload bool) error) error {
+	w := watchFiles(in, tmpl)
+	log.Info().Strs("files", w.paths).Msg("watching for changes, Ctrl+C to stop")
+	for {
+		changed, err := w.wait(ctx)
+		if err != nil {
+			return err
+		}
+		log.Info().Strs("files", changed).Msg("changed, regenerating")
+		reload := slices.ContainsFunc(changed, func(p string) bool { return filepath.Clean(p) == filepath.Clean(in.Config) })
+		if err := update(reload); err != nil {
+			log.Error().Err(err).Msg("regenerate")
+		}
+	}
+}
+
+// reloadMessages перечитывает конфиг для -watch и заново отбирает сообщения
+// года из all: номинации, подписи, участники, картинки, правки. Флаги,
+// взятые из конфига при запуске (in, year, template, …), остаются прежними —
+// для них нужен перезапуск. Номинации собираются в свой реестр из номинаций
+// до конфига: общий stats.Nominators не меняется.
+func (f *inputFlags) reloadMessages(all []telegram.Message, baseDir string) ([]telegram.Message, error) {
+	cfg, err := loadConfig(f.Config, false)
+	if err != nil {
+		return nil, err
+	}
+	registry := f.registry.Clone()
+	if err := cfg.applyNominations(registry); err != nil {
+		return nil, err
+	}
+	f.noms = registry
+	f.cfg = cfg
+	return f.prepare(all, baseDir)
+}
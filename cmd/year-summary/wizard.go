@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bebroedik/year-summary-2025/render"
+	"github.com/bebroedik/year-summary-2025/stats"
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// prompter задаёт вопросы в терминале, пустой ответ — значение по умолчанию
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func (p *prompter) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", err
+	}
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return def, nil
+	}
+	return line, nil
+}
+
+func (p *prompter) confirm(question string, def bool) (bool, error) {
+	d := "y/N"
+	if def {
+		d = "Y/n"
+	}
+	a, err := p.ask(question+" ("+d+")", "")
+	if err != nil {
+		return false, err
+	}
+	if a == "" {
+		return def, nil
+	}
+	return strings.HasPrefix(strings.ToLower(a), "y") || strings.HasPrefix(strings.ToLower(a), "д"), nil
+}
+
+// guessExport ищет result.json в текущей папке и на уровень ниже
+func guessExport() string {
+	if _, err := os.Stat("result.json"); err == nil {
+		return "result.json"
+	}
+	if m, _ := filepath.Glob(filepath.Join("*", "result.json")); len(m) > 0 {
+		return m[0]
+	}
+	return ""
+}
+
+func cmdInit(ctx context.Context, args []string) error {
+	fs := newFlagSet("init", "Interactively create a starter config: export path, year, nicknames and avatars.")
+	path := fs.String("config", defaultConfigFile, "config file to write")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	p := &prompter{in: bufio.NewReader(os.Stdin), out: os.Stdout}
+	return runWizard(ctx, p, *path)
+}
+
+func runWizard(ctx context.Context, p *prompter, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		ok, err := p.confirm(fmt.Sprintf("%s уже существует, перезаписать?", path), false)
+		if err != nil || !ok {
+			return err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	cfg := &Config{Users: map[string]UserConfig{}}
+
+	// 1. экспорт
+	var export *telegram.ChatExport
+	for export == nil {
+		in, err := p.ask("Путь к result.json из экспорта Telegram", guessExport())
+		if err != nil {
+			return err
+		}
+		export, err = telegram.ReadFile(ctx, in, "")
+		if err != nil {
+			fmt.Fprintf(p.out, "  не получилось прочитать: %v\n", err)
+			continue
+		}
+		warnDamaged(in, export.Report)
+		warnUnknown(in, export.Report)
+		cfg.Input = in
+	}
+	fmt.Fprintf(p.out, "\nЧат: %s (%s), сообщений: %d\n", export.Name, export.Type, len(export.Messages))
+
+	// 2. год — по умолчанию последний полный
+	all := stats.FilterMessages(export.Messages, stats.FilterTypeMessage)
+	latest := strconv.Itoa(stats.DetectYear(all))
+	for cfg.Year == 0 {
+		y, err := p.ask("Год для итогов", latest)
+		if err != nil {
+			return err
+		}
+		if cfg.Year, err = strconv.Atoi(y); err != nil {
+			fmt.Fprintf(p.out, "  это не год: %q\n", y)
+		}
+	}
+
+	// 3. участники
+	messages := stats.FilterMessages(all, stats.FilterYear(cfg.Year))
+	found := stats.LoadAvatars([]string{filepath.Dir(cfg.Input)}, ".", messages)
+	userCount := stats.Count(messages, stats.FilterUser, stats.LabelID)
+	ids := make([]string, 0, len(userCount))
+	for id := range userCount {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if userCount[ids[i]] != userCount[ids[j]] {
+			return userCount[ids[i]] > userCount[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+
+	fmt.Fprintf(p.out, "\nУчастники в %d:\n", cfg.Year)
+	for _, id := range ids {
+		avatar := "заглушка"
+		if a, ok := found.ByID[id]; ok {
+			avatar = a
+		}
+		fmt.Fprintf(p.out, "  %6d  %-24s %-16s %s\n", userCount[id], found.Names[id], id, avatar)
+	}
+	if n := stats.Count(messages, stats.FilterTrue, stats.LabelID)[telegram.ChatSenderID]; n > 0 {
+		fmt.Fprintf(p.out, "  %6d  от имени чата (анонимные админы) — в номинациях не участвуют\n", n)
+	}
+
+	// 4. ники и аватарки
+	if ok, err := p.confirm("\nНастроить имена и аватарки участников?", true); err != nil {
+		return err
+	} else if ok {
+		fmt.Fprintln(p.out, "Enter — оставить как есть.")
+		for _, id := range ids {
+			name, err := p.ask(fmt.Sprintf("  %s: имя", id), found.Names[id])
+			if err != nil {
+				return err
+			}
+			avatar, err := p.ask(fmt.Sprintf("  %s: аватарка", id), found.ByID[id])
+			if err != nil {
+				return err
+			}
+
+			u := UserConfig{}
+			if name != found.Names[id] {
+				u.Name = name
+			}
+			if avatar != found.ByID[id] {
+				if _, err := os.Stat(avatar); err != nil {
+					fmt.Fprintf(p.out, "  внимание: %s не найден\n", avatar)
+				}
+				u.Avatar = avatar
+			}
+			if u != (UserConfig{}) {
+				cfg.Users[id] = u
+			}
+		}
+	}
+
+	// 5. куда писать страницу
+	var err error
+	if cfg.Output, err = p.ask("\nКуда сохранить страницу", "year_summary.html"); err != nil {
+		return err
+	}
+	if cfg.Template, err = p.ask("Шаблон", render.DefaultTemplate); err != nil {
+		return err
+	}
+
+	if err := saveConfig(path, cfg); err != nil {
+		return err
+	}
+	fmt.Fprintf(p.out, "\nКонфиг записан в %s. Дальше: year-summary generate\n", path)
+	return nil
+}
@@ -0,0 +1,20 @@
+module github.com/bebroedik/year-summary-2025
+
+go 1.23.0
+
+require (
+	github.com/json-iterator/go v1.1.12
+	github.com/rs/zerolog v1.34.0
+	golang.org/x/image v0.25.0
+	golang.org/x/net v0.20.0
+	golang.org/x/text v0.23.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.19 // indirect
+	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
+	github.com/modern-go/reflect2 v1.0.2 // indirect
+	golang.org/x/sys v0.16.0 // indirect
+)
@@ -0,0 +1,354 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+)
+
+// CardSize — сторона квадратной карточки в пикселях
+const CardSize = 1080
+
+// цвета как у template_v7
+var (
+	cardBg       = color.RGBA{0x08, 0x11, 0x2b, 0xff}
+	cardTitle    = color.RGBA{0xff, 0xe0, 0x66, 0xff}
+	cardSubtitle = color.RGBA{0xff, 0x4c, 0x6b, 0xff}
+	cardCaption  = color.RGBA{0xff, 0xd8, 0xa6, 0xff}
+)
+
+// WriteCards рисует каждую номинацию страницы отдельной картинкой
+// CardSize×CardSize (заголовок, аватарка, большое число, подпись) и пишет
+// их в dir как 01.png, 02.png, … по порядку страницы — удобно выкладывать
+// в чат по одной. Пути к аватаркам считаются от baseDir. Аватарка, которая
+// не прочиталась, заменяется кругом и попадает в warnings как *MediaError.
+func WriteCards(ctx context.Context, page stats.PageData, dir, baseDir string) (files []string, warnings []error, err error) {
+	noms := allNominations(page)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("cards dir: %w", err)
+	}
+
+	for i, n := range noms {
+		if err := ctx.Err(); err != nil {
+			return files, warnings, err
+		}
+		img, werr := Card(n, baseDir)
+		if werr != nil {
+			warnings = append(warnings, werr)
+		}
+		name := filepath.Join(dir, fmt.Sprintf("%02d.png", i+1))
+		if err := writePNG(name, img); err != nil {
+			return files, warnings, err
+		}
+		files = append(files, name)
+	}
+	return files, warnings, nil
+}
+
+// allNominations — номинации страницы и её разделов по порядку
+func allNominations(page stats.PageData) []stats.Nomination {
+	noms := append([]stats.Nomination(nil), page.Nominations...)
+	for _, s := range page.Sections {
+		noms = append(noms, s.Nominations...)
+	}
+	return noms
+}
+
+func writePNG(name string, img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return fmt.Errorf("encode %s: %w", name, err)
+	}
+	if err := writeFile(name, buf.Bytes()); err != nil {
+		return &MediaError{Path: name, Err: err}
+	}
+	return nil
+}
+
+// Card рисует одну номинацию. Ошибка — только про аватарку, картинка
+// возвращается всегда.
+func Card(n stats.Nomination, baseDir string) (*image.RGBA, error) {
+	faces := loadCardFaces()
+	img := image.NewRGBA(image.Rect(0, 0, CardSize, CardSize))
+	draw.Draw(img, img.Bounds(), image.NewUniform(cardBg), image.Point{}, draw.Src)
+
+	const pad = 80
+	x0, x1 := pad, CardSize-pad
+
+	y := pad
+	y = drawLines(img, faces.title, cardTitle, wrapText(faces.title, n.Title, x1-x0, 2), x0, x1, y, alignCenter)
+
+	// аватарка — круг с рамкой посередине
+	const size = 400
+	top := y + 40
+	err := drawAvatar(img, n, baseDir, image.Pt(CardSize/2, top+size/2), size/2, faces.initials)
+	y = top + size + 40
+
+	y = drawLines(img, faces.subtitle, cardSubtitle, wrapText(faces.subtitle, n.Subtitle, x1-x0, 1), x0, x1, y, alignCenter)
+	rest := (CardSize - pad - y) / lineHeight(faces.caption)
+	drawLines(img, faces.caption, cardCaption, wrapText(faces.caption, n.Caption, x1-x0, rest), x0, x1, y+20, alignCenter)
+	return img, err
+}
+
+// drawAvatar рисует аватарку номинации кругом радиуса r с рамкой;
+// заглушки и нечитаемые картинки — цветным кругом с инициалами
+func drawAvatar(img *image.RGBA, n stats.Nomination, baseDir string, center image.Point, r int, initials font.Face) error {
+	fillCircle(img, center, r+8, cardTitle)
+	avatar, err := loadAvatar(n.Avatar, baseDir)
+	if avatar == nil {
+		drawPlaceholder(img, center, r, n.Avatar, initials)
+		return err
+	}
+	if n.Redacted {
+		avatar = pixelate(avatar, 12)
+	}
+	drawCircleImage(img, avatar, center, r)
+	return nil
+}
+
+type cardFaces struct {
+	title, subtitle, caption font.Face
+	initials, initialsSmall  font.Face // на большой аватарке карточки и на маленьких в сторис
+}
+
+var (
+	cardFonts     cardFaces
+	cardFontsOnce sync.Once
+)
+
+// loadCardFaces разбирает встроенные шрифты Go один раз; в них есть
+// кириллица, эмодзи — нет
+func loadCardFaces() cardFaces {
+	cardFontsOnce.Do(func() {
+		bold, regular := mustParseFont(gobold.TTF), mustParseFont(goregular.TTF)
+		cardFonts = cardFaces{
+			title:         mustFace(bold, 64),
+			subtitle:      mustFace(bold, 96),
+			caption:       mustFace(regular, 40),
+			initials:      mustFace(bold, 160),
+			initialsSmall: mustFace(bold, 96),
+		}
+	})
+	return cardFonts
+}
+
+// шрифты вшиты, так что ошибка разбора — сломанная сборка, а не данные
+func mustParseFont(ttf []byte) *opentype.Font {
+	f, err := opentype.Parse(ttf)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+func mustFace(f *opentype.Font, size float64) font.Face {
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		panic(err)
+	}
+	return face
+}
+
+func lineHeight(face font.Face) int {
+	return face.Metrics().Height.Ceil()
+}
+
+const (
+	alignCenter = iota
+	alignLeft
+)
+
+// drawLines пишет строки между x0 и x1, начиная с y, и возвращает y под ними
+func drawLines(img *image.RGBA, face font.Face, c color.Color, lines []string, x0, x1, y, align int) int {
+	d := &font.Drawer{Dst: img, Src: image.NewUniform(c), Face: face}
+	h := lineHeight(face)
+	for _, line := range lines {
+		x := x0
+		if align == alignCenter {
+			x += (x1 - x0 - d.MeasureString(line).Ceil()) / 2
+		}
+		d.Dot = fixed.P(x, y+face.Metrics().Ascent.Ceil())
+		d.DrawString(line)
+		y += h
+	}
+	return y
+}
+
+// wrapText разбивает s по словам на строки не шире width, не больше max
+// строк; что не влезло, обрезается многоточием. Символы, которых нет в
+// шрифте (эмодзи), выбрасываются — иначе вместо них рисуются квадраты.
+func wrapText(face font.Face, s string, width, max int) []string {
+	s = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return ' '
+		}
+		if _, ok := face.GlyphAdvance(r); !ok {
+			return -1
+		}
+		return r
+	}, s)
+	if max < 1 {
+		return nil
+	}
+	fits := func(line string) bool { return font.MeasureString(face, line).Ceil() <= width }
+
+	var lines []string
+	line := ""
+	for _, w := range strings.Fields(s) {
+		next := w
+		if line != "" {
+			next = line + " " + w
+		}
+		if fits(next) || line == "" {
+			line = next
+			continue
+		}
+		lines = append(lines, line)
+		line = w
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	if len(lines) > max {
+		lines = lines[:max]
+		lines[max-1] += "…"
+	}
+	// одно длинное слово или обрезанная строка могут не влезть — укорачиваем
+	for i, l := range lines {
+		for !fits(l) && l != "" {
+			r := []rune(strings.TrimSuffix(l, "…"))
+			l = string(r[:len(r)-1]) + "…"
+		}
+		lines[i] = l
+	}
+	return lines
+}
+
+// loadAvatar читает картинку аватарки. Заглушки — SVG в data URL, их
+// прочитать нечем: nil без ошибки, вместо них рисуется drawPlaceholder.
+func loadAvatar(path, baseDir string) (image.Image, error) {
+	if path == "" || strings.HasPrefix(path, "data:image/svg") {
+		return nil, nil
+	}
+	var data []byte
+	if strings.HasPrefix(path, "data:") {
+		_, enc, ok := strings.Cut(path, ";base64,")
+		path = "data URL" // в ошибке весь base64 не нужен
+		if !ok {
+			return nil, &MediaError{Path: path, Err: fmt.Errorf("not base64")}
+		}
+		var err error
+		if data, err = base64.StdEncoding.DecodeString(enc); err != nil {
+			return nil, &MediaError{Path: path, Err: err}
+		}
+	} else {
+		file := filepath.FromSlash(path)
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(baseDir, file)
+		}
+		var err error
+		if data, err = os.ReadFile(file); err != nil {
+			return nil, &MediaError{Path: path, Err: err}
+		}
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, &MediaError{Path: path, Err: err}
+	}
+	return img, nil
+}
+
+var (
+	placeholderFillRe = regexp.MustCompile(`fill="(#[0-9a-fA-F]{6})"`)
+	placeholderTextRe = regexp.MustCompile(`>([^<]+)</text>`)
+)
+
+// drawPlaceholder рисует заглушку с тем же цветом и инициалами, что у SVG
+// из stats; без SVG — просто цветной круг
+func drawPlaceholder(img *image.RGBA, center image.Point, r int, svg string, face font.Face) {
+	svg, _ = url.PathUnescape(svg)
+	c := color.RGBA{0x8e, 0x7d, 0xff, 0xff}
+	if m := placeholderFillRe.FindStringSubmatch(svg); m != nil {
+		fmt.Sscanf(m[1], "#%02x%02x%02x", &c.R, &c.G, &c.B)
+	}
+	fillCircle(img, center, r, c)
+	m := placeholderTextRe.FindStringSubmatch(svg)
+	if m == nil {
+		return
+	}
+	lines := wrapText(face, m[1], 2*r, 1)
+	if len(lines) == 0 {
+		return
+	}
+	top := center.Y - lineHeight(face)/2
+	drawLines(img, face, color.White, lines, center.X-r, center.X+r, top, alignCenter)
+}
+
+// circle — маска круга для draw.DrawMask
+type circle struct {
+	center image.Point
+	r      int
+}
+
+func (c circle) ColorModel() color.Model { return color.AlphaModel }
+
+func (c circle) Bounds() image.Rectangle {
+	return image.Rect(c.center.X-c.r, c.center.Y-c.r, c.center.X+c.r, c.center.Y+c.r)
+}
+
+func (c circle) At(x, y int) color.Color {
+	dx, dy := x-c.center.X, y-c.center.Y
+	if dx*dx+dy*dy < c.r*c.r {
+		return color.Alpha{0xff}
+	}
+	return color.Alpha{}
+}
+
+func fillCircle(img *image.RGBA, center image.Point, r int, c color.Color) {
+	m := circle{center, r}
+	draw.DrawMask(img, m.Bounds(), image.NewUniform(c), image.Point{}, m, m.Bounds().Min, draw.Over)
+}
+
+// drawCircleImage вписывает src в круг: середина квадрата, отмасштабированная
+// до диаметра
+func drawCircleImage(img *image.RGBA, src image.Image, center image.Point, r int) {
+	b := src.Bounds()
+	side := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, side, side).Add(b.Min).Add(image.Pt((b.Dx()-side)/2, (b.Dy()-side)/2))
+	scaled := image.NewRGBA(image.Rect(0, 0, 2*r, 2*r))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), src, crop, draw.Src, nil)
+
+	m := circle{center, r}
+	draw.DrawMask(img, m.Bounds(), scaled, image.Point{}, m, m.Bounds().Min, draw.Over)
+}
+
+// pixelate размывает аватарку тех, кто отказался от участия, как blur на
+// странице: уменьшает до cells×cells и растягивает обратно
+func pixelate(src image.Image, cells int) image.Image {
+	small := image.NewRGBA(image.Rect(0, 0, cells, cells))
+	draw.ApproxBiLinear.Scale(small, small.Bounds(), src, src.Bounds(), draw.Src, nil)
+	out := image.NewRGBA(src.Bounds())
+	draw.NearestNeighbor.Scale(out, out.Bounds(), small, small.Bounds(), draw.Src, nil)
+	return out
+}
@@ -0,0 +1,44 @@
+package render
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+)
+
+// compareHTML — шаблон страницы сравнения двух чатов
+//
+//go:embed site/compare.html
+var compareHTML string
+
+var compareTemplate = template.Must(template.New("compare.html").Funcs(Funcs).Parse(compareHTML))
+
+// RenderCompare пишет страницу сравнения двух чатов: счёт и по строке на
+// каждый показатель, полосы навстречу друг другу
+func RenderCompare(w io.Writer, c stats.Comparison) error {
+	if err := compareTemplate.Execute(w, c); err != nil {
+		return newTemplateError("exec", err)
+	}
+	return nil
+}
+
+// GenerateCompare — RenderCompare в outFile, "-" — в stdout
+func GenerateCompare(outFile string, c stats.Comparison) error {
+	var out bytes.Buffer
+	if err := RenderCompare(&out, c); err != nil {
+		return err
+	}
+	if outFile == "-" {
+		_, err := os.Stdout.Write(out.Bytes())
+		return err
+	}
+	if err := writeFile(outFile, out.Bytes()); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+	return nil
+}
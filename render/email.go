@@ -0,0 +1,83 @@
+package render
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"maps"
+	"os"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+)
+
+// emailHTML — страница для рассылки: таблицы и стили в атрибутах
+//
+//go:embed site/email.html
+var emailHTML string
+
+// emailColors — цвета письма; почтовые клиенты не знают CSS-переменных,
+// поэтому тема подставляется значениями
+type emailColors struct {
+	Bg, Card, Title, Accent, Text, Muted string
+}
+
+// emailCards — данные части nominations: номинации и цвета
+type emailCards struct {
+	Colors      emailColors
+	Nominations []stats.Nomination
+}
+
+var emailTemplate = template.Must(template.New("email.html").Funcs(template.FuncMap{
+	"cards": func(c emailColors, noms []stats.Nomination) emailCards { return emailCards{c, noms} },
+}).Parse(emailHTML))
+
+// Email пишет страницу для рассылки: вёрстка таблицами, все стили в
+// атрибутах style, без скриптов, шрифтов и картинок — Gmail и Outlook
+// выбрасывают <style>, data URL и SVG, а внешние картинки прячут. Номинации
+// с пьедесталом, разделы чатов, хроника и «Как считали» идут столбцом в
+// 600 пикселей. Цвета — из data.Theme поверх тёмной палитры.
+func Email(w io.Writer, data stats.PageData) error {
+	if data.Labels == nil {
+		data.Labels = stats.Labels(data.Lang)
+	}
+	vars := maps.Clone(themes["dark"])
+	if data.Theme != nil {
+		maps.Copy(vars, data.Theme.Vars)
+	}
+	page := struct {
+		stats.PageData
+		Colors emailColors
+		Footer string
+	}{
+		PageData: data,
+		Colors: emailColors{
+			Bg: vars["bg"], Card: vars["bg2"], Title: vars["accent2"],
+			Accent: vars["accent"], Text: vars["text"], Muted: vars["muted"],
+		},
+	}
+	if data.Build.Version != "" {
+		page.Footer = buildLine(data)
+	}
+	if err := emailTemplate.Execute(w, page); err != nil {
+		return newTemplateError("exec", err)
+	}
+	return nil
+}
+
+// GenerateEmail — Email в outFile, "-" — в stdout
+func GenerateEmail(outFile string, data stats.PageData) error {
+	var out bytes.Buffer
+	if err := Email(&out, data); err != nil {
+		return err
+	}
+	if outFile == "-" {
+		_, err := os.Stdout.Write(out.Bytes())
+		return err
+	}
+	if err := writeFile(outFile, out.Bytes()); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+	return nil
+}
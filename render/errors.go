@@ -0,0 +1,55 @@
+package render
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TemplateError — шаблон не разобрался, не прошёл Lint или упал при
+// выполнении. Name и Line — где именно, если html/template это сообщил.
+type TemplateError struct {
+	Stage  string   // "parse", "lint" или "exec"
+	Name   string   // файл шаблона или частичный шаблон
+	Line   int      // строка, 0 — неизвестна
+	Issues []string // у lint — все найденные проблемы
+	Err    error
+}
+
+func (e *TemplateError) Error() string {
+	if e.Stage == "lint" {
+		return "template refers to missing data:\n\t" + strings.Join(e.Issues, "\n\t")
+	}
+	return e.Stage + " template: " + e.Err.Error()
+}
+
+func (e *TemplateError) Unwrap() error { return e.Err }
+
+// templateLocRe — «template: card.html:12:5: ...» у ошибок разбора,
+// «html/template:card.html:12:5: ...» у экранирования и «card.html:12:5: ...» у Lint
+var templateLocRe = regexp.MustCompile(`^(?:(?:html/)?template: ?)?([^:\s]+):(\d+)`)
+
+// newTemplateError достаёт имя и строку из текста ошибки
+func newTemplateError(stage string, err error, issues ...string) *TemplateError {
+	e := &TemplateError{Stage: stage, Err: err, Issues: issues}
+	msg := strings.Join(issues, "\n")
+	if err != nil {
+		msg = err.Error()
+	}
+	if m := templateLocRe.FindStringSubmatch(msg); m != nil {
+		e.Name = m[1]
+		e.Line, _ = strconv.Atoi(m[2])
+	}
+	return e
+}
+
+// MediaError — картинка для страницы не нашлась, не читается или не
+// записалась
+type MediaError struct {
+	Path string
+	Err  error
+}
+
+func (e *MediaError) Error() string { return e.Path + ": " + e.Err.Error() }
+
+func (e *MediaError) Unwrap() error { return e.Err }
@@ -0,0 +1,65 @@
+package render
+
+import (
+	"html/template"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+)
+
+// Funcs — функции, доступные в шаблоне и частичных шаблонах:
+//
+//	{{safeURL .Avatar}}                   — картинка data:image/... в src; без неё html/template заменит её на #ZgotmplZ
+//	{{chart .Chart .Title}}               — график номинации: SVG прямо в разметке, чтобы работали подсказки
+//	{{number .Value}}                     — 12 345 / 12,345 по языку страницы
+//	{{date .Time "02.01.2006"}}           — время по раскладке Go
+//	{{plural .Value "день" "дня" "дней"}} — форма слова для числа по языку страницы
+//	{{themeVars .Theme.Vars}}             — переменные темы объявлениями CSS для :root
+//
+// number и plural здесь — на русском; Render подменяет их функциями языка
+// страницы, см. langFuncs.
+var Funcs = template.FuncMap{
+	"safeURL":   safeURL,
+	"chart":     chart,
+	"number":    langFuncs(stats.DefaultLanguage)["number"],
+	"date":      func(t time.Time, layout string) string { return t.Format(layout) },
+	"plural":    langFuncs(stats.DefaultLanguage)["plural"],
+	"themeVars": themeVars,
+}
+
+// langFuncs — функции шаблона, которые зависят от языка страницы
+func langFuncs(lang string) template.FuncMap {
+	return template.FuncMap{
+		"number": func(n int) string { return stats.Number(lang, n) },
+		"plural": func(n int, forms ...string) string { return stats.Plural(lang, n, forms...) },
+	}
+}
+
+// safeURL пропускает как есть только встроенные картинки: их собирает сама
+// программа из аватарок и графиков. Остальные адреса html/template
+// проверяет как обычно, javascript: не пройдёт.
+func safeURL(s string) any {
+	if strings.HasPrefix(s, "data:image/") {
+		return template.URL(s)
+	}
+	return s
+}
+
+// chartPrefix — графики, которые рисует сама программа: SVG в data URL
+const chartPrefix = "data:image/svg+xml;utf8,"
+
+// chart выводит график номинации. Нарисованный программой SVG вставляется
+// в разметку как есть: у клеток календаря подсказки с точным числом, а в
+// <img> их не видно. Остальное — картинкой; адрес со схемой, кроме
+// data:image/ и http(s), заменяется на #ZgotmplZ, как у html/template.
+func chart(src, alt string) template.HTML {
+	if svg, err := url.PathUnescape(strings.TrimPrefix(src, chartPrefix)); err == nil && strings.HasPrefix(src, chartPrefix) && strings.HasPrefix(svg, "<svg ") {
+		return template.HTML(`<svg class="chart" role="img" aria-label="` + template.HTMLEscapeString(alt) + `" ` + strings.TrimPrefix(svg, "<svg "))
+	}
+	if u, err := url.Parse(src); err != nil || u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" && !strings.HasPrefix(src, "data:image/") {
+		src = "#ZgotmplZ"
+	}
+	return template.HTML(`<img class="chart" src="` + template.HTMLEscapeString(src) + `" alt="` + template.HTMLEscapeString(alt) + `"/>`)
+}
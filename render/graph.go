@@ -0,0 +1,94 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+)
+
+// DOT пишет граф для Graphviz: у узла подпись — имя, у ребра — вес,
+// толщина линии растёт с весом
+func DOT(w io.Writer, g stats.Graph) error {
+	var b bytes.Buffer
+	b.WriteString("digraph chat {\n\tnode [shape=ellipse];\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "\t%s [label=%s, messages=%d];\n", strconv.Quote(n.ID), strconv.Quote(n.Name), n.Messages)
+	}
+	top := maxWeight(g)
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "\t%s -> %s [weight=%d, label=%d, penwidth=%.1f, replies=%d, mentions=%d];\n",
+			strconv.Quote(e.From), strconv.Quote(e.To), e.Weight, e.Weight, 1+float64(e.Weight)/float64(top)*4, e.Replies, e.Mentions)
+	}
+	b.WriteString("}\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// GraphML пишет граф в GraphML для Gephi и yEd: имя и число сообщений у
+// узлов, вес, ответы и упоминания у рёбер
+func GraphML(w io.Writer, g stats.Graph) error {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	b.WriteString(`  <key id="name" for="node" attr.name="label" attr.type="string"/>` + "\n")
+	b.WriteString(`  <key id="messages" for="node" attr.name="messages" attr.type="int"/>` + "\n")
+	b.WriteString(`  <key id="weight" for="edge" attr.name="weight" attr.type="int"/>` + "\n")
+	b.WriteString(`  <key id="replies" for="edge" attr.name="replies" attr.type="int"/>` + "\n")
+	b.WriteString(`  <key id="mentions" for="edge" attr.name="mentions" attr.type="int"/>` + "\n")
+	b.WriteString(`  <graph id="chat" edgedefault="directed">` + "\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, `    <node id="%s"><data key="name">%s</data><data key="messages">%d</data></node>`+"\n", xmlEscape(n.ID), xmlEscape(n.Name), n.Messages)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, `    <edge source="%s" target="%s"><data key="weight">%d</data><data key="replies">%d</data><data key="mentions">%d</data></edge>`+"\n",
+			xmlEscape(e.From), xmlEscape(e.To), e.Weight, e.Replies, e.Mentions)
+	}
+	b.WriteString("  </graph>\n</graphml>\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// GenerateGraph пишет граф в outFile: .graphml — GraphML, иначе (.dot, .gv,
+// "-" — в stdout) — DOT
+func GenerateGraph(outFile string, g stats.Graph) error {
+	var out bytes.Buffer
+	write := DOT
+	switch strings.ToLower(filepath.Ext(outFile)) {
+	case ".graphml":
+		write = GraphML
+	case ".dot", ".gv", "":
+	default:
+		return fmt.Errorf("unsupported graph format %q, use .dot, .gv or .graphml", filepath.Ext(outFile))
+	}
+	if err := write(&out, g); err != nil {
+		return err
+	}
+	if outFile == "-" {
+		_, err := os.Stdout.Write(out.Bytes())
+		return err
+	}
+	if err := writeFile(outFile, out.Bytes()); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+	return nil
+}
+
+func maxWeight(g stats.Graph) int {
+	if len(g.Edges) == 0 {
+		return 0
+	}
+	return g.Edges[0].Weight // рёбра идут по убыванию веса
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package render
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+)
+
+// Inline заменяет пути к картинкам страницы (обложка, аватарки, пьедестал,
+// графики) на data URL: страница становится одним файлом, который можно
+// переслать в чат или по почте без папки images/. Пути считаются от
+// baseDir — папки, куда пишется HTML. Нарисованные заглушки уже data URL.
+// Картинка, которая не прочиталась, остаётся путём и попадает в ошибки
+// как *MediaError — страница от этого не ломается.
+func Inline(page *stats.PageData, baseDir string) []error {
+	in := inliner{base: baseDir, done: map[string]string{}}
+	eachURL(page, in.url)
+	return in.errs
+}
+
+// eachURL вызывает fn для каждой ссылки на картинку страницы: обложка,
+// аватарки, пьедестал, графики
+func eachURL(page *stats.PageData, fn func(*string)) {
+	fn(&page.Cover)
+	cards := func(noms []stats.Nomination) {
+		for i := range noms {
+			fn(&noms[i].Avatar)
+			fn(&noms[i].Chart)
+			for j := range noms[i].Podium {
+				fn(&noms[i].Podium[j].Avatar)
+			}
+		}
+	}
+	cards(page.Nominations)
+	for i := range page.Sections {
+		cards(page.Sections[i].Nominations)
+	}
+}
+
+// localPath — путь к файлу, а не data URL и не адрес в сети
+func localPath(s string) bool {
+	return s != "" && !strings.HasPrefix(s, "data:") && !strings.Contains(s, "://")
+}
+
+type inliner struct {
+	base string
+	done map[string]string // путь → data URL, одна аватарка встречается много раз
+	errs []error
+}
+
+func (in *inliner) url(s *string) {
+	path := *s
+	if !localPath(path) {
+		return
+	}
+	if u, ok := in.done[path]; ok {
+		*s = u
+		return
+	}
+	file := filepath.FromSlash(path)
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(in.base, file)
+	}
+	u, err := dataURL(file)
+	if err != nil {
+		in.errs = append(in.errs, &MediaError{Path: path, Err: err})
+		in.done[path] = path // второй раз не читаем и не жалуемся
+		return
+	}
+	in.done[path] = u
+	*s = u
+}
+
+// dataURL — файл картинки как data:<mime>;base64,...
+func dataURL(file string) (string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	typ := mime.TypeByExtension(strings.ToLower(filepath.Ext(file)))
+	if !strings.HasPrefix(typ, "image/") {
+		typ = http.DetectContentType(data)
+	}
+	if typ, _, _ = strings.Cut(typ, ";"); !strings.HasPrefix(typ, "image/") {
+		return "", fmt.Errorf("not an image: %s", typ)
+	}
+	return "data:" + typ + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
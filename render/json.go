@@ -0,0 +1,51 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+)
+
+// statsDump — страница и числа под ней одним JSON
+type statsDump struct {
+	stats.PageData
+	Aggregates *stats.Aggregates `json:"aggregates,omitempty"`
+}
+
+// GenerateJSON пишет в outFile ("-" — stdout) все номинации страницы и
+// агрегаты, из которых они посчитаны: для своего фронтенда или таблицы
+func GenerateJSON(outFile string, data stats.PageData, agg *stats.Aggregates) error {
+	out, err := marshalStats(data, agg)
+	if err != nil {
+		return err
+	}
+	if outFile == "-" {
+		_, err := os.Stdout.Write(out)
+		return err
+	}
+	if err := writeFile(outFile, out); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+	return nil
+}
+
+// JSON — то же, что GenerateJSON, в w: для /stats.json в serve
+func JSON(w io.Writer, data stats.PageData, agg *stats.Aggregates) error {
+	out, err := marshalStats(data, agg)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+func marshalStats(data stats.PageData, agg *stats.Aggregates) ([]byte, error) {
+	out, err := json.MarshalIndent(statsDump{PageData: data, Aggregates: agg}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal stats: %w", err)
+	}
+	return append(out, '\n'), nil
+}
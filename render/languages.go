@@ -0,0 +1,67 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+)
+
+// languagesHTML — обёртка страницы на нескольких языках
+//
+//go:embed site/languages.html
+var languagesHTML string
+
+var languagesTemplate = template.Must(template.New("languages.html").Parse(languagesHTML))
+
+// RenderLanguages пишет одну страницу на нескольких языках: каждая из pages
+// (та же страница, посчитанная на своём языке) собирается шаблоном tmpl
+// целиком и встраивается в рамку, переключатель в углу показывает нужную.
+// Так работает любой шаблон, в том числе свой. Выбор запоминается в
+// браузере, при первом открытии язык берётся из настроек браузера.
+func RenderLanguages(ctx context.Context, w io.Writer, tmpl *Templates, pages []stats.PageData) error {
+	if len(pages) == 0 {
+		return errors.New("no pages to render")
+	}
+	type version struct {
+		Lang, Name, Title, HTML string
+	}
+	versions := make([]version, len(pages))
+	for i, page := range pages {
+		var out bytes.Buffer
+		if err := RenderContext(ctx, &out, tmpl, page); err != nil {
+			return fmt.Errorf("%s: %w", page.Lang, err)
+		}
+		name := page.Labels["language"]
+		if name == "" {
+			name = page.Lang
+		}
+		versions[i] = version{Lang: page.Lang, Name: name, Title: page.Title, HTML: out.String()}
+	}
+	if err := languagesTemplate.Execute(w, versions); err != nil {
+		return newTemplateError("exec", err)
+	}
+	return nil
+}
+
+// GenerateLanguages — RenderLanguages в outFile, "-" — в stdout
+func GenerateLanguages(ctx context.Context, tmpl *Templates, outFile string, pages []stats.PageData) error {
+	var out bytes.Buffer
+	if err := RenderLanguages(ctx, &out, tmpl, pages); err != nil {
+		return err
+	}
+	if outFile == "-" {
+		_, err := os.Stdout.Write(out.Bytes())
+		return err
+	}
+	if err := writeFile(outFile, out.Bytes()); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+	return nil
+}
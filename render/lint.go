@@ -0,0 +1,206 @@
+package render
+
+import (
+	"fmt"
+	"html/template"
+	"reflect"
+	"text/template/parse"
+)
+
+// Lint проверяет, что шаблон обращается только к тем полям, которые
+// есть в данных: проходит по дереву шаблона, следя за типом точки и
+// переменных, и собирает ошибки вида
+//
+//	template_v7.html:120:24: Nomination has no field Captoin
+//
+// html/template нашёл бы их только при выполнении и только первую.
+func Lint(t *template.Template, data any) []string {
+	l := &templateLinter{tmpl: t, seen: map[string]bool{}}
+	l.tree(t.Name(), reflect.TypeOf(data))
+	return l.issues
+}
+
+type templateLinter struct {
+	tmpl   *template.Template
+	issues []string
+	seen   map[string]bool // шаблон+тип точки, уже проверенные
+}
+
+func (l *templateLinter) tree(name string, dot reflect.Type) {
+	key := fmt.Sprintf("%s\x00%v", name, dot)
+	if l.seen[key] {
+		return
+	}
+	l.seen[key] = true
+
+	t := l.tmpl.Lookup(name)
+	if t == nil || t.Tree == nil {
+		return
+	}
+	l.walk(t.Tree, t.Tree.Root, dot, map[string]reflect.Type{"$": dot})
+}
+
+func (l *templateLinter) report(tree *parse.Tree, n parse.Node, format string, args ...any) {
+	loc, _ := tree.ErrorContext(n)
+	l.issues = append(l.issues, loc+": "+fmt.Sprintf(format, args...))
+}
+
+// walk проверяет узел; nil в типе значит «неизвестно», там проверки не идут
+func (l *templateLinter) walk(tree *parse.Tree, node parse.Node, dot reflect.Type, vars map[string]reflect.Type) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			l.walk(tree, c, dot, vars)
+		}
+	case *parse.ActionNode:
+		l.pipe(tree, n.Pipe, dot, vars)
+	case *parse.IfNode:
+		l.pipe(tree, n.Pipe, dot, vars)
+		l.walk(tree, n.List, dot, scope(vars))
+		l.walk(tree, n.ElseList, dot, scope(vars))
+	case *parse.WithNode:
+		inner := scope(vars)
+		t := l.pipe(tree, n.Pipe, dot, inner)
+		l.walk(tree, n.List, t, inner)
+		l.walk(tree, n.ElseList, dot, scope(vars))
+	case *parse.RangeNode:
+		inner := scope(vars)
+		key, elem := rangeTypes(l.pipeValue(tree, n.Pipe, dot, vars))
+		switch len(n.Pipe.Decl) {
+		case 1:
+			inner[n.Pipe.Decl[0].Ident[0]] = elem
+		case 2:
+			inner[n.Pipe.Decl[0].Ident[0]] = key
+			inner[n.Pipe.Decl[1].Ident[0]] = elem
+		}
+		l.walk(tree, n.List, elem, inner)
+		l.walk(tree, n.ElseList, dot, scope(vars))
+	case *parse.TemplateNode:
+		var arg reflect.Type
+		if n.Pipe != nil {
+			arg = l.pipe(tree, n.Pipe, dot, vars)
+		}
+		if l.tmpl.Lookup(n.Name) == nil {
+			l.report(tree, n, "no template %q", n.Name)
+			return
+		}
+		l.tree(n.Name, arg)
+	}
+}
+
+// pipe — тип результата конвейера; объявленные в нём переменные попадают в vars
+func (l *templateLinter) pipe(tree *parse.Tree, p *parse.PipeNode, dot reflect.Type, vars map[string]reflect.Type) reflect.Type {
+	t := l.pipeValue(tree, p, dot, vars)
+	for _, v := range p.Decl {
+		vars[v.Ident[0]] = t
+	}
+	return t
+}
+
+func (l *templateLinter) pipeValue(tree *parse.Tree, p *parse.PipeNode, dot reflect.Type, vars map[string]reflect.Type) reflect.Type {
+	var t reflect.Type
+	for _, cmd := range p.Cmds {
+		for _, a := range cmd.Args[1:] {
+			l.arg(tree, a, dot, vars)
+		}
+		if _, isFunc := cmd.Args[0].(*parse.IdentifierNode); isFunc {
+			t = nil // что вернёт функция, не отслеживаем
+			continue
+		}
+		t = l.arg(tree, cmd.Args[0], dot, vars)
+	}
+	return t
+}
+
+func (l *templateLinter) arg(tree *parse.Tree, node parse.Node, dot reflect.Type, vars map[string]reflect.Type) reflect.Type {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return l.fields(tree, n, dot, n.Ident)
+	case *parse.VariableNode:
+		return l.fields(tree, n, vars[n.Ident[0]], n.Ident[1:])
+	case *parse.ChainNode:
+		return l.fields(tree, n, l.arg(tree, n.Node, dot, vars), n.Field)
+	case *parse.PipeNode:
+		return l.pipeValue(tree, n, dot, vars)
+	case *parse.StringNode:
+		return reflect.TypeOf("")
+	case *parse.BoolNode:
+		return reflect.TypeOf(true)
+	}
+	return nil
+}
+
+// fields проходит цепочку .A.B.C от типа t
+func (l *templateLinter) fields(tree *parse.Tree, n parse.Node, t reflect.Type, names []string) reflect.Type {
+	for _, name := range names {
+		for t != nil && t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t == nil {
+			return nil
+		}
+
+		switch t.Kind() {
+		case reflect.Struct:
+			if f, ok := t.FieldByName(name); ok && f.IsExported() {
+				t = f.Type
+				continue
+			}
+			if m, ok := reflect.PointerTo(t).MethodByName(name); ok && m.Type.NumOut() > 0 {
+				t = m.Type.Out(0)
+				continue
+			}
+			l.report(tree, n, "%s has no field %s", typeName(t), name)
+			return nil
+		case reflect.Map:
+			t = t.Elem()
+		case reflect.Interface:
+			return nil
+		default:
+			l.report(tree, n, "can't evaluate field %s in type %s", name, typeName(t))
+			return nil
+		}
+	}
+	return t
+}
+
+// rangeTypes — типы ключа и элемента для {{range}}
+func rangeTypes(t reflect.Type) (key, elem reflect.Type) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return nil, nil
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return reflect.TypeOf(0), t.Elem()
+	case reflect.Map:
+		return t.Key(), t.Elem()
+	case reflect.Chan:
+		return nil, t.Elem()
+	case reflect.Int:
+		return nil, t
+	}
+	return nil, nil
+}
+
+func scope(vars map[string]reflect.Type) map[string]reflect.Type {
+	inner := make(map[string]reflect.Type, len(vars))
+	for k, v := range vars {
+		inner[k] = v
+	}
+	return inner
+}
+
+func typeName(t reflect.Type) string {
+	if t.Name() != "" {
+		return t.Name()
+	}
+	return t.String()
+}
@@ -0,0 +1,162 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+)
+
+// Markdown пишет страницу в Markdown: заголовок на каждую номинацию,
+// пьедестал — таблицей, потом хроника и «Как считали». Картинок нет:
+// в вики, Notion или сообщение Telegram они всё равно не переносятся.
+func Markdown(w io.Writer, data stats.PageData) error {
+	if data.Labels == nil {
+		data.Labels = stats.Labels(data.Lang)
+	}
+	md := &mdWriter{w: w}
+
+	md.line("# " + mdEscape(data.Title))
+	if data.Preview != "" {
+		md.line("> " + mdEscape(data.Preview))
+	}
+	md.nominations("##", data.Nominations, data.Labels)
+	for _, s := range data.Sections {
+		md.line("## " + mdEscape(s.Title))
+		md.nominations("###", s.Nominations, data.Labels)
+	}
+
+	if len(data.Timeline) > 0 {
+		md.line("## " + mdEscape(data.Labels["timeline"]))
+		for _, e := range data.Timeline {
+			if e.Kind == "month" {
+				md.line("### " + mdEscape(e.Title))
+				continue
+			}
+			item := "- **" + mdEscape(e.Day) + "** — " + mdEscape(e.Title)
+			if e.Text != "" {
+				item += ": " + mdEscape(e.Text)
+			}
+			md.item(item)
+		}
+	}
+
+	if len(data.Methodology) > 0 {
+		md.line("## " + mdEscape(data.Labels["methodology"]))
+		for _, n := range data.Methodology {
+			md.item("- **" + mdEscape(n.Title) + "** — " + mdEscape(n.Text))
+		}
+	}
+
+	if data.Build.Version != "" {
+		md.line("---")
+		md.line("_" + mdEscape(buildLine(data)) + "_")
+	}
+	return md.err
+}
+
+// buildLine — подпись внизу: когда и какой версией собрано
+func buildLine(data stats.PageData) string {
+	b := data.Build
+	s := data.Labels["generated"] + " " + b.Date + " · year-summary " + b.Version
+	if b.Commit != "" {
+		s += " (" + b.Commit + ")"
+	}
+	return s
+}
+
+// GenerateMarkdown — Generate для Markdown: пишет страницу в outFile, "-" — в stdout
+func GenerateMarkdown(outFile string, data stats.PageData) error {
+	var out bytes.Buffer
+	if err := Markdown(&out, data); err != nil {
+		return err
+	}
+	if outFile == "-" {
+		_, err := os.Stdout.Write(out.Bytes())
+		return err
+	}
+	if err := writeFile(outFile, out.Bytes()); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+	return nil
+}
+
+// mdWriter разделяет блоки пустой строкой, а пункты списка идут подряд;
+// первая ошибка записи запоминается, остальное не пишется
+type mdWriter struct {
+	w      io.Writer
+	err    error
+	inList bool
+}
+
+func (md *mdWriter) write(s string) {
+	if md.err == nil {
+		_, md.err = io.WriteString(md.w, s)
+	}
+}
+
+// line — отдельный абзац или заголовок
+func (md *mdWriter) line(s string) {
+	if md.inList {
+		md.write("\n")
+		md.inList = false
+	}
+	md.write(s + "\n\n")
+}
+
+// item — пункт списка
+func (md *mdWriter) item(s string) {
+	md.inList = true
+	md.write(s + "\n")
+}
+
+func (md *mdWriter) nominations(level string, noms []stats.Nomination, labels map[string]string) {
+	for _, n := range noms {
+		md.line(level + " " + mdEscape(n.Title))
+		if n.Subtitle != "" {
+			md.line("**" + mdEscape(n.Subtitle) + "**")
+		}
+		if n.Caption != "" {
+			md.line(mdEscape(n.Caption))
+		}
+		if len(n.Podium) > 0 {
+			md.line(podiumTable(n.Podium, labels))
+		}
+	}
+}
+
+// podiumTable — пьедестал таблицей: место, участник, значение
+func podiumTable(podium []stats.Place, labels map[string]string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "| %s | %s | %s |\n|---:|---|---:|", mdCell(labels["place"]), mdCell(labels["member"]), mdCell(labels["value"]))
+	for _, p := range podium {
+		name := p.Name
+		if name == "" {
+			name = p.ID
+		}
+		fmt.Fprintf(&b, "\n| %d | %s | %s |", p.Rank, mdCell(name), mdCell(p.Label))
+	}
+	return b.String()
+}
+
+// mdSpecial — символы, которые Markdown понял бы как разметку
+var mdSpecial = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "#", `\#`, "|", `\|`, "~", `\~`,
+)
+
+// mdEscape экранирует разметку в тексте из чата: имена и цитаты бывают любыми
+func mdEscape(s string) string {
+	return mdSpecial.Replace(strings.Join(strings.Fields(s), " "))
+}
+
+// mdCell — mdEscape для ячейки таблицы
+func mdCell(s string) string {
+	if s = mdEscape(s); s == "" {
+		return " "
+	}
+	return s
+}
@@ -0,0 +1,90 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+)
+
+// Post — одно сообщение для чата в HTML-разметке Telegram (parse_mode
+// HTML); у сообщения про номинацию есть Nomination — к нему можно
+// приложить карточку
+type Post struct {
+	Text       string
+	Nomination *stats.Nomination
+}
+
+// Posts — страница сообщениями для Telegram, как их выкладывать на
+// «церемонии»: заголовок, по сообщению на номинацию, перед номинациями
+// каждого чата (-per-chat) — его название
+func Posts(data stats.PageData) []Post {
+	posts := []Post{{Text: "🎉 <b>" + html.EscapeString(data.Title) + "</b>"}}
+	if data.Preview != "" {
+		posts[0].Text += "\n\n<i>" + html.EscapeString(data.Preview) + "</i>"
+	}
+	add := func(noms []stats.Nomination) {
+		for i := range noms {
+			posts = append(posts, Post{Text: postText(noms[i]), Nomination: &noms[i]})
+		}
+	}
+	add(data.Nominations)
+	for _, s := range data.Sections {
+		posts = append(posts, Post{Text: "💬 <b>" + html.EscapeString(s.Title) + "</b>"})
+		add(s.Nominations)
+	}
+	return posts
+}
+
+// medals — значки первых трёх мест пьедестала
+var medals = []string{"🥇", "🥈", "🥉"}
+
+func postText(n stats.Nomination) string {
+	var b strings.Builder
+	b.WriteString("🏆 <b>" + html.EscapeString(n.Title) + "</b>")
+	if n.Subtitle != "" {
+		b.WriteString("\n<b>" + html.EscapeString(n.Subtitle) + "</b>")
+	}
+	if n.Caption != "" {
+		b.WriteString("\n\n" + html.EscapeString(n.Caption))
+	}
+	if len(n.Podium) > 0 {
+		b.WriteString("\n")
+	}
+	for _, p := range n.Podium {
+		b.WriteString("\n" + placeLine(p))
+	}
+	return b.String()
+}
+
+// placeLine — место пьедестала строкой: «🥇 Саша — 42»
+func placeLine(p stats.Place) string {
+	name := p.Name
+	if name == "" {
+		name = p.ID
+	}
+	mark := fmt.Sprintf("%d.", p.Rank)
+	if p.Rank >= 1 && p.Rank <= len(medals) {
+		mark = medals[p.Rank-1]
+	}
+	return mark + " " + html.EscapeString(name) + " — " + html.EscapeString(p.Label)
+}
+
+// OnThisDayPost — «Год назад» одним сообщением в HTML-разметке Telegram:
+// сколько написали, кто громче всех и сообщение с наибольшим числом реакций
+func OnThisDayPost(d stats.OnThisDay) string {
+	var b strings.Builder
+	b.WriteString("📅 <b>" + html.EscapeString(d.Title) + "</b>\n" + html.EscapeString(d.Summary))
+	if len(d.Loudest) > 0 {
+		b.WriteString("\n\n🗣 <b>" + html.EscapeString(d.Labels["loudest"]) + "</b>")
+	}
+	for _, p := range d.Loudest {
+		b.WriteString("\n" + placeLine(p))
+	}
+	if t := d.Top; t != nil {
+		fmt.Fprintf(&b, "\n\n🔥 <b>%s</b> · %s, %s · %d ❤\n<blockquote>%s</blockquote>",
+			html.EscapeString(d.Labels["topMessage"]), html.EscapeString(t.Name), html.EscapeString(t.Day), t.Reactions, html.EscapeString(t.Text))
+	}
+	return b.String()
+}
@@ -0,0 +1,109 @@
+// Package render превращает stats.PageData в HTML по шаблону.
+package render
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+)
+
+// Render проверяет шаблон по данным и пишет страницу в w
+func Render(w io.Writer, tmpl *Templates, data stats.PageData) error {
+	return RenderContext(context.Background(), w, tmpl, data)
+}
+
+// ctxWriter обрывает вывод шаблона, как только ctx отменён
+type ctxWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (c ctxWriter) Write(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.w.Write(p)
+}
+
+// RenderContext — Render, который можно прервать
+func RenderContext(ctx context.Context, w io.Writer, tmpl *Templates, data stats.PageData) error {
+	t, err := tmpl.Load()
+	if err != nil {
+		return err
+	}
+	// страницы из старого result.json и собранные вручную — без подписей
+	if data.Lang == "" {
+		data.Lang = stats.DefaultLanguage
+	}
+	if data.Labels == nil {
+		data.Labels = stats.Labels(data.Lang)
+	}
+	if data.Theme == nil {
+		if data.Theme, err = LoadTheme(tmpl.Theme, tmpl.ThemeFile); err != nil {
+			return err
+		}
+	}
+	if issues := Lint(t, data); len(issues) > 0 {
+		return newTemplateError("lint", nil, issues...)
+	}
+
+	t.Funcs(langFuncs(data.Lang))
+	if err := t.Execute(ctxWriter{ctx, w}, data); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return newTemplateError("exec", err)
+	}
+	return nil
+}
+
+// Generate пишет страницу в outFile, "-" — в stdout. Файл не трогается,
+// если шаблон не удалось выполнить.
+func Generate(tmpl *Templates, outFile string, data stats.PageData) error {
+	return GenerateContext(context.Background(), tmpl, outFile, data)
+}
+
+// GenerateContext — Generate, который можно прервать; прерванный вывод
+// тоже не трогает старый файл
+func GenerateContext(ctx context.Context, tmpl *Templates, outFile string, data stats.PageData) error {
+	var out bytes.Buffer
+	if err := RenderContext(ctx, &out, tmpl, data); err != nil {
+		return err
+	}
+
+	if outFile == "-" {
+		_, err := os.Stdout.Write(out.Bytes())
+		return err
+	}
+	if err := writeFile(outFile, out.Bytes()); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+	return nil
+}
+
+// writeFile пишет во временный файл рядом и переименовывает
+func writeFile(name string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".year-summary-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
@@ -0,0 +1,159 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"hash/fnv"
+	"html/template"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+)
+
+// memberHTML — шаблон страницы участника на сайте
+//
+//go:embed site/member.html
+var memberHTML string
+
+var memberTemplate = template.Must(template.New("member.html").Funcs(Funcs).Parse(memberHTML))
+
+// WriteSite пишет в dir статический сайт: index.html — страница номинаций
+// по шаблону tmpl со слайдом-списком участников, members/<id>.html —
+// страница каждого участника из members, assets/ — картинки, на которые
+// ссылаются страницы. Ссылки относительные: папку можно выложить на любой
+// статический хостинг как есть. Пути к картинкам считаются от baseDir;
+// картинка, которую не удалось скопировать, попадает в warnings как
+// *MediaError, ссылка на неё остаётся прежней.
+func WriteSite(ctx context.Context, tmpl *Templates, dir, baseDir string, page stats.PageData, members []stats.MemberPage) (files []string, warnings []error, err error) {
+	if err := os.MkdirAll(filepath.Join(dir, "members"), 0755); err != nil {
+		return nil, nil, fmt.Errorf("site dir: %w", err)
+	}
+	a := &assetCopier{base: baseDir, dir: dir, done: map[string]string{}, used: map[string]bool{}}
+
+	eachURL(&page, a.url)
+	page.Members = make([]stats.MemberLink, len(members))
+	for i := range members {
+		m := &members[i]
+		a.url(&m.Avatar)
+		m.Awards = append([]stats.Nomination(nil), m.Awards...) // карточки общие с page
+		for j := range m.Awards {
+			a.url(&m.Awards[j].Avatar)
+		}
+		file := "members/" + memberFile(m.ID)
+		page.Members[i] = stats.MemberLink{ID: m.ID, Name: m.Name, Avatar: m.Avatar, URL: file}
+	}
+	if a.err != nil {
+		return nil, a.errs, a.err
+	}
+
+	index := filepath.Join(dir, "index.html")
+	if err := GenerateContext(ctx, tmpl, index, page); err != nil {
+		return nil, a.errs, err
+	}
+	files = append(files, index)
+
+	theme, err := LoadTheme(tmpl.Theme, tmpl.ThemeFile)
+	if err != nil {
+		return files, a.errs, err
+	}
+	for _, m := range members {
+		if err := ctx.Err(); err != nil {
+			return files, a.errs, err
+		}
+		// страницы участников лежат на уровень ниже
+		m.Index = "../index.html"
+		m.Theme = theme
+		m.Avatar = up(m.Avatar)
+		for j := range m.Awards {
+			m.Awards[j].Avatar = up(m.Awards[j].Avatar)
+		}
+		var out bytes.Buffer
+		if err := memberTemplate.Execute(&out, m); err != nil {
+			return files, a.errs, newTemplateError("exec", err)
+		}
+		name := filepath.Join(dir, "members", memberFile(m.ID))
+		if err := writeFile(name, out.Bytes()); err != nil {
+			return files, a.errs, fmt.Errorf("write file: %w", err)
+		}
+		files = append(files, name)
+	}
+	return files, a.errs, nil
+}
+
+// up — ссылка со страницы в подпапке
+func up(s string) string {
+	if !localPath(s) || path.IsAbs(s) {
+		return s
+	}
+	return "../" + s
+}
+
+// memberFile — имя файла страницы участника: from_id, если он годится в
+// имя файла, иначе хеш (у WhatsApp вместо id имена из телефона)
+func memberFile(id string) string {
+	safe := id != ""
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return id + ".html"
+	}
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	return fmt.Sprintf("u%x.html", h.Sum64())
+}
+
+// assetCopier копирует картинки страницы в dir/assets и переписывает ссылки
+type assetCopier struct {
+	base, dir string
+	done      map[string]string // исходная ссылка → новая
+	used      map[string]bool   // занятые имена в assets
+	errs      []error
+	err       error // не удалось писать в dir: дальше не копируем
+}
+
+func (a *assetCopier) url(s *string) {
+	src := *s
+	if !localPath(src) || a.err != nil {
+		return
+	}
+	if u, ok := a.done[src]; ok {
+		*s = u
+		return
+	}
+	file := filepath.FromSlash(src)
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(a.base, file)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		a.errs = append(a.errs, &MediaError{Path: src, Err: err})
+		a.done[src] = src
+		return
+	}
+
+	name := filepath.Base(file)
+	ext := filepath.Ext(name)
+	for n := 2; a.used[name]; n++ {
+		name = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(filepath.Base(file), ext), n, ext)
+	}
+	a.used[name] = true
+	if err := os.MkdirAll(filepath.Join(a.dir, "assets"), 0755); err != nil {
+		a.err = fmt.Errorf("site assets: %w", err)
+		return
+	}
+	if err := writeFile(filepath.Join(a.dir, "assets", name), data); err != nil {
+		a.err = fmt.Errorf("site assets: %w", err)
+		return
+	}
+	a.done[src] = "assets/" + name
+	*s = a.done[src]
+}
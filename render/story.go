@@ -0,0 +1,147 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/image/draw"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+)
+
+// размер вертикального слайда, как у сторис в Instagram и Telegram
+const (
+	StoryWidth  = 1080
+	StoryHeight = 1920
+)
+
+// StoryPerSlide — сколько номинаций на одном слайде сторис
+const StoryPerSlide = 3
+
+// фоны слайдов по очереди: верх градиента и пятно акцентного цвета
+var storyBackgrounds = []struct{ top, spot color.RGBA }{
+	{color.RGBA{0x0a, 0x1f, 0x3f, 0xff}, cardSubtitle},
+	{color.RGBA{0x1d, 0x0f, 0x3a, 0xff}, color.RGBA{0x6b, 0xf2, 0xff, 0xff}},
+	{color.RGBA{0x2b, 0x0b, 0x24, 0xff}, cardTitle},
+	{color.RGBA{0x0b, 0x29, 0x2b, 0xff}, color.RGBA{0x8e, 0x7d, 0xff, 0xff}},
+}
+
+// WriteStories собирает номинации страницы в короткую колоду вертикальных
+// слайдов StoryWidth×StoryHeight: первый — обложка с заголовком страницы,
+// дальше по StoryPerSlide номинаций на слайд крупным шрифтом. Файлы
+// story-01.png, story-02.png, … в dir; их удобно выложить в сторис одну за
+// другой. Пути к картинкам считаются от baseDir, нечитаемые картинки
+// попадают в warnings как *MediaError.
+func WriteStories(ctx context.Context, page stats.PageData, dir, baseDir string) (files []string, warnings []error, err error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("stories dir: %w", err)
+	}
+	write := func(img *image.RGBA) error {
+		name := filepath.Join(dir, fmt.Sprintf("story-%02d.png", len(files)+1))
+		if err := writePNG(name, img); err != nil {
+			return err
+		}
+		files = append(files, name)
+		return nil
+	}
+
+	cover, werr := storyCover(page, baseDir)
+	if werr != nil {
+		warnings = append(warnings, werr)
+	}
+	if err := write(cover); err != nil {
+		return files, warnings, err
+	}
+
+	noms := allNominations(page)
+	for start := 0; start < len(noms); start += StoryPerSlide {
+		if err := ctx.Err(); err != nil {
+			return files, warnings, err
+		}
+		slide, errs := StorySlide(page.Title, noms[start:min(start+StoryPerSlide, len(noms))], len(files), baseDir)
+		warnings = append(warnings, errs...)
+		if err := write(slide); err != nil {
+			return files, warnings, err
+		}
+	}
+	return files, warnings, nil
+}
+
+// StorySlide рисует один слайд: заголовок страницы сверху и до
+// StoryPerSlide номинаций — аватарка слева, название, число и подпись
+// справа. index выбирает фон, чтобы соседние слайды различались.
+func StorySlide(title string, noms []stats.Nomination, index int, baseDir string) (*image.RGBA, []error) {
+	faces := loadCardFaces()
+	img := storyBackground(index)
+
+	const pad = 80
+	drawLines(img, faces.caption, cardCaption, wrapText(faces.caption, title, StoryWidth-2*pad, 1), pad, StoryWidth-pad, pad, alignCenter)
+
+	const (
+		top    = 220
+		r      = 130 // радиус аватарки
+		textX0 = pad + 2*r + 56
+		textX1 = StoryWidth - pad
+	)
+	block := (StoryHeight - top - pad) / StoryPerSlide
+	var errs []error
+	for i, n := range noms {
+		y, end := top+i*block, top+(i+1)*block-24
+		if err := drawAvatar(img, n, baseDir, image.Pt(pad+r, y+r+16), r, faces.initialsSmall); err != nil {
+			errs = append(errs, err)
+		}
+		width := textX1 - textX0
+		y = drawLines(img, faces.title, cardTitle, wrapText(faces.title, n.Title, width, 2), textX0, textX1, y, alignLeft)
+		y = drawLines(img, faces.subtitle, cardSubtitle, wrapText(faces.subtitle, n.Subtitle, width, 2), textX0, textX1, y+8, alignLeft)
+		rest := (end - y - 8) / lineHeight(faces.caption)
+		drawLines(img, faces.caption, cardCaption, wrapText(faces.caption, n.Caption, width, rest), textX0, textX1, y+8, alignLeft)
+	}
+	return img, errs
+}
+
+// storyCover — первый слайд: обложка страницы, если она есть, и заголовок
+func storyCover(page stats.PageData, baseDir string) (*image.RGBA, error) {
+	faces := loadCardFaces()
+	img := storyBackground(0)
+
+	const pad = 80
+	lines := wrapText(faces.subtitle, page.Title, StoryWidth-2*pad, 5)
+	height := len(lines) * lineHeight(faces.subtitle)
+
+	cover, err := loadAvatar(page.Cover, baseDir)
+	y := (StoryHeight - height) / 2
+	if cover != nil {
+		const r, gap = 300, 60
+		y = (StoryHeight - height - 2*r - gap) / 2
+		center := image.Pt(StoryWidth/2, y+r)
+		fillCircle(img, center, r+8, cardTitle)
+		drawCircleImage(img, cover, center, r)
+		y += 2*r + gap
+	}
+	drawLines(img, faces.subtitle, cardTitle, lines, pad, StoryWidth-pad, y, alignCenter)
+	return img, err
+}
+
+// storyBackground — вертикальный градиент к цвету страницы и два
+// полупрозрачных пятна акцентного цвета
+func storyBackground(index int) *image.RGBA {
+	bg := storyBackgrounds[index%len(storyBackgrounds)]
+	img := image.NewRGBA(image.Rect(0, 0, StoryWidth, StoryHeight))
+	for y := 0; y < StoryHeight; y++ {
+		c := mix(bg.top, cardBg, float64(y)/StoryHeight)
+		draw.Draw(img, image.Rect(0, y, StoryWidth, y+1), image.NewUniform(c), image.Point{}, draw.Src)
+	}
+	spot := color.NRGBA{bg.spot.R, bg.spot.G, bg.spot.B, 0x28}
+	fillCircle(img, image.Pt(StoryWidth, 0), 520, spot)
+	fillCircle(img, image.Pt(0, StoryHeight), 620, spot)
+	return img
+}
+
+func mix(a, b color.RGBA, t float64) color.RGBA {
+	lerp := func(x, y uint8) uint8 { return uint8(float64(x) + (float64(y)-float64(x))*t) }
+	return color.RGBA{lerp(a.R, b.R), lerp(a.G, b.G), lerp(a.B, b.B), 0xff}
+}
@@ -0,0 +1,136 @@
+package render
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+)
+
+// CSV пишет таблицу: первая строка — header. В начале BOM, иначе Excel
+// читает кириллицу в имени как cp1251.
+func CSV(w io.Writer, header []string, rows [][]any) error {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	record := make([]string, len(header))
+	for _, row := range rows {
+		for i, v := range row {
+			record[i] = cellText(v)
+		}
+		if err := cw.Write(record[:len(row)]); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// XLSX пишет таблицу книгой Excel с одним листом: числа — числами, чтобы
+// их сразу можно было сортировать и складывать. Без стилей и общих строк —
+// минимум, который открывают Excel, LibreOffice и Google Таблицы.
+func XLSX(w io.Writer, header []string, rows [][]any) error {
+	var sheet bytes.Buffer
+	sheet.WriteString(xml.Header)
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews><sheetData>`)
+	writeRow := func(n int, cells []any) {
+		fmt.Fprintf(&sheet, `<row r="%d">`, n)
+		for i, v := range cells {
+			ref := xlsxColumn(i) + strconv.Itoa(n)
+			switch v.(type) {
+			case int, float64:
+				fmt.Fprintf(&sheet, `<c r="%s"><v>%s</v></c>`, ref, cellText(v))
+			default:
+				fmt.Fprintf(&sheet, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+				xml.EscapeText(&sheet, []byte(cellText(v)))
+				sheet.WriteString(`</t></is></c>`)
+			}
+		}
+		sheet.WriteString(`</row>`)
+	}
+	cells := make([]any, len(header))
+	for i, h := range header {
+		cells[i] = h
+	}
+	writeRow(1, cells)
+	for i, row := range rows {
+		writeRow(i+2, row)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	z := zip.NewWriter(w)
+	for _, f := range []struct{ name, data string }{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Members" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+		{"xl/worksheets/sheet1.xml", sheet.String()},
+	} {
+		fw, err := z.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.data); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+// GenerateTable пишет таблицу участников в outFile ("-" — stdout): format
+// "csv" или "xlsx"
+func GenerateTable(outFile, format string, agg *stats.Aggregates) error {
+	var out bytes.Buffer
+	if err := Table(&out, format, agg); err != nil {
+		return err
+	}
+	if outFile == "-" {
+		_, err := os.Stdout.Write(out.Bytes())
+		return err
+	}
+	if err := writeFile(outFile, out.Bytes()); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+	return nil
+}
+
+// Table пишет таблицу участников в w: format "csv" или "xlsx"
+func Table(w io.Writer, format string, agg *stats.Aggregates) error {
+	header, rows := agg.UserTable()
+	switch format {
+	case "csv":
+		return CSV(w, header, rows)
+	case "xlsx":
+		return XLSX(w, header, rows)
+	}
+	return fmt.Errorf("unknown table format %q", format)
+}
+
+func cellText(v any) string {
+	switch v := v.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	}
+	return fmt.Sprint(v)
+}
+
+// xlsxColumn — имя столбца по номеру с нуля: A, B, …, Z, AA, …
+func xlsxColumn(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
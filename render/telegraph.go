@@ -0,0 +1,97 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+)
+
+// TelegraphNode — элемент страницы Telegraph (createPage, content); в
+// Children — строки и другие элементы
+type TelegraphNode struct {
+	Tag      string            `json:"tag"`
+	Attrs    map[string]string `json:"attrs,omitempty"`
+	Children []any             `json:"children,omitempty"`
+}
+
+func node(tag string, children ...any) TelegraphNode {
+	return TelegraphNode{Tag: tag, Children: children}
+}
+
+// Telegraph — страница для telegra.ph: номинации с пьедесталом, разделы
+// чатов, хроника и «Как считали». Заголовок страницы Telegraph задаётся
+// отдельно, в content его нет. Картинок нет, как и в Markdown: им нужен
+// адрес в сети.
+func Telegraph(data stats.PageData) []any {
+	if data.Labels == nil {
+		data.Labels = stats.Labels(data.Lang)
+	}
+	var content []any
+	if data.Preview != "" {
+		content = append(content, node("aside", data.Preview))
+	}
+	content = telegraphNominations(content, "h3", data.Nominations)
+	for _, s := range data.Sections {
+		content = append(content, node("hr"), node("h3", s.Title))
+		content = telegraphNominations(content, "h4", s.Nominations)
+	}
+
+	if len(data.Timeline) > 0 {
+		content = append(content, node("hr"), node("h3", data.Labels["timeline"]))
+		var items []any
+		flush := func() {
+			if len(items) > 0 {
+				content = append(content, node("ul", items...))
+				items = nil
+			}
+		}
+		for _, e := range data.Timeline {
+			if e.Kind == "month" {
+				flush()
+				content = append(content, node("h4", e.Title))
+				continue
+			}
+			item := node("li", node("b", e.Day), " — "+e.Title)
+			if e.Text != "" {
+				item.Children = append(item.Children, ": "+e.Text)
+			}
+			items = append(items, item)
+		}
+		flush()
+	}
+
+	if len(data.Methodology) > 0 {
+		content = append(content, node("hr"), node("h3", data.Labels["methodology"]))
+		var items []any
+		for _, n := range data.Methodology {
+			items = append(items, node("li", node("b", n.Title), " — "+n.Text))
+		}
+		content = append(content, node("ul", items...))
+	}
+	return content
+}
+
+func telegraphNominations(content []any, level string, noms []stats.Nomination) []any {
+	for _, n := range noms {
+		content = append(content, node(level, n.Title))
+		if n.Subtitle != "" {
+			content = append(content, node("p", node("strong", n.Subtitle)))
+		}
+		if n.Caption != "" {
+			content = append(content, node("p", n.Caption))
+		}
+		if len(n.Podium) == 0 {
+			continue
+		}
+		var places []any
+		for _, p := range n.Podium {
+			name := p.Name
+			if name == "" {
+				name = p.ID
+			}
+			places = append(places, node("li", fmt.Sprintf("%s — %s", name, p.Label)))
+		}
+		content = append(content, node("ol", places...))
+	}
+	return content
+}
@@ -0,0 +1,123 @@
+package render
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultTemplate — шаблон, если -template не задан
+const DefaultTemplate = "template_v7.html"
+
+// builtin — шаблоны, встроенные в программу: template_v7.html и
+// template_v9.html работают без файлов рядом. CSS в самих шаблонах,
+// заглушки аватарок рисует stats.
+//
+//go:embed templates/*.html
+var builtin embed.FS
+
+// Builtin — имена встроенных шаблонов
+func Builtin() []string {
+	files, _ := fs.Glob(builtin, "templates/*.html")
+	for i, f := range files {
+		files[i] = filepath.Base(f)
+	}
+	return files
+}
+
+// Templates — основной шаблон и папка с частичными шаблонами поверх него.
+//
+// В шаблонах выделены части, которые можно переопределить, не трогая сам шаблон:
+//
+//	card    — карточка номинации (аватарка, заголовок, подписи)
+//	section — раздел номинаций одного чата (-per-chat)
+//	cover   — слайд с обложкой из images.cover
+//	timeline — слайд «Хроника года» (timeline в конфиге)
+//	methodology — слайд «Как считали» (methodology в конфиге)
+//	leaderboard — слайд с общим рейтингом участников (leaderboard в конфиге)
+//	members — слайд со ссылками на страницы участников (команда site)
+//	styles  — дополнительный CSS в <head>, по умолчанию пусто
+//
+// Шаблоны — html/template: всё из сообщений и имён экранируется, <script>
+// в сообщении останется текстом. Функции шаблонов — см. Funcs.
+//
+// Файл card.html в Dir (-templates-dir) заменяет card: либо просто разметкой карточки,
+// либо через {{define "card"}}...{{end}}, тогда в одном файле можно
+// переопределить сразу несколько частей.
+//
+// File — файл шаблона; просто имя встроенного шаблона (template_v9.html)
+// без такого файла на диске берёт встроенный, пусто — DefaultTemplate.
+//
+// Theme и ThemeFile — палитра и CSS-файл с переменными поверх неё, см.
+// LoadTheme; в шаблон попадают как .Theme. Файл, как и части, читается
+// заново при каждой отрисовке.
+type Templates struct {
+	File      string
+	Dir       string
+	Theme     string
+	ThemeFile string
+}
+
+// read — текст основного шаблона: файл на диске, если он есть, иначе встроенный
+func (t *Templates) read() (name, text string, err error) {
+	file := t.File
+	if file == "" {
+		file = DefaultTemplate
+	}
+	name = filepath.Base(file)
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) && name == file {
+		if b, embedErr := builtin.ReadFile("templates/" + name); embedErr == nil {
+			return name, string(b), nil
+		}
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return name, string(data), nil
+}
+
+// Load разбирает основной шаблон и накладывает на него части из Dir
+func (t *Templates) Load() (*template.Template, error) {
+	name, text, err := t.read()
+	if err != nil {
+		return nil, newTemplateError("parse", err)
+	}
+	tmpl, err := template.New(name).Funcs(Funcs).Parse(text)
+	if err != nil {
+		return nil, newTemplateError("parse", err)
+	}
+	if t.Dir == "" {
+		return tmpl, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(t.Dir, "*.html"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no *.html partials in %s", t.Dir)
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("read partial: %w", err)
+		}
+		text := string(data)
+
+		// файл без define целиком становится частью с именем файла
+		name := strings.TrimSuffix(filepath.Base(f), filepath.Ext(f))
+		if !strings.Contains(text, "{{define") && !strings.Contains(text, "{{- define") {
+			text = `{{define "` + name + `"}}` + text + `{{end}}`
+		}
+		if _, err := tmpl.New(filepath.Base(f)).Parse(text); err != nil {
+			return nil, newTemplateError("parse", err)
+		}
+	}
+	return tmpl, nil
+}
@@ -0,0 +1,119 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+)
+
+// termBar — ширина столбика первого места на пьедестале
+const termBar = 24
+
+// цвета ANSI
+const (
+	ansiBold   = "\x1b[1m"
+	ansiDim    = "\x1b[2m"
+	ansiCyan   = "\x1b[36m"
+	ansiYellow = "\x1b[33m"
+	ansiReset  = "\x1b[0m"
+)
+
+// Term пишет номинации для терминала, чтобы проверить цифры, не открывая
+// браузер: название и число в две колонки, подпись, пьедестал столбиками.
+// color — с цветами ANSI; в файл и в пайп их лучше не писать.
+func Term(w io.Writer, data stats.PageData, color bool) error {
+	t := &termWriter{color: color}
+	t.line(t.paint(ansiBold, data.Title))
+	t.line(strings.Repeat("═", utf8.RuneCountInString(data.Title)))
+	if data.Preview != "" {
+		t.line(t.paint(ansiDim, data.Preview))
+	}
+	t.nominations(data.Nominations)
+	for _, s := range data.Sections {
+		t.line("")
+		t.line(t.paint(ansiBold, s.Title))
+		t.line(strings.Repeat("─", utf8.RuneCountInString(s.Title)))
+		t.nominations(s.Nominations)
+	}
+	if data.Build.Version != "" {
+		if data.Labels == nil {
+			data.Labels = stats.Labels(data.Lang)
+		}
+		t.line("")
+		t.line(t.paint(ansiDim, buildLine(data)))
+	}
+	_, err := io.WriteString(w, t.b.String())
+	return err
+}
+
+type termWriter struct {
+	b     strings.Builder
+	color bool
+}
+
+func (t *termWriter) line(s string) {
+	t.b.WriteString(s + "\n")
+}
+
+func (t *termWriter) paint(code, s string) string {
+	if !t.color || s == "" {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+func (t *termWriter) nominations(noms []stats.Nomination) {
+	width := 0
+	for _, n := range noms {
+		width = max(width, utf8.RuneCountInString(n.Title))
+	}
+	for _, n := range noms {
+		t.line("")
+		t.line(t.paint(ansiCyan+ansiBold, padRight(n.Title, width)) + "  " + t.paint(ansiYellow, n.Subtitle))
+		if n.Caption != "" {
+			t.line("  " + t.paint(ansiDim, strings.Join(strings.Fields(n.Caption), " ")))
+		}
+		t.podium(n.Podium)
+	}
+}
+
+// podium — места столбиками, длина — доля от значения первого места
+func (t *termWriter) podium(places []stats.Place) {
+	if len(places) == 0 {
+		return
+	}
+	top, width := 0, 0
+	for _, p := range places {
+		top = max(top, p.Value)
+		width = max(width, utf8.RuneCountInString(placeName(p)))
+	}
+	for _, p := range places {
+		bar := 0
+		if top > 0 {
+			bar = p.Value * termBar / top
+		}
+		if bar == 0 && p.Value > 0 {
+			bar = 1
+		}
+		t.line(fmt.Sprintf("  %2d. %s  %s %s", p.Rank, padRight(placeName(p), width),
+			t.paint(ansiYellow, strings.Repeat("█", bar))+strings.Repeat(" ", termBar-bar), p.Label))
+	}
+}
+
+func placeName(p stats.Place) string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.ID
+}
+
+// padRight дополняет пробелами до width символов
+func padRight(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
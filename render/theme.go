@@ -0,0 +1,116 @@
+package render
+
+import (
+	"fmt"
+	"html/template"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+)
+
+// themes — встроенные палитры. Переменные те же, что в :root встроенных
+// шаблонов; dark — их собственные цвета, его удобно взять за основу
+// своего файла темы.
+var themes = map[string]map[string]string{
+	"dark": {
+		"bg":        "#08112b",
+		"bg2":       "#0a1f3f",
+		"accent":    "#ff4c6b",
+		"accent2":   "#ffe066",
+		"text":      "#fff",
+		"muted":     "#ffd8a6",
+		"highlight": "#6bf2ff",
+		"surface":   "rgba(255,255,255,0.05)",
+		"glow":      "rgba(255,215,0,0.7)",
+		"track":     "rgba(255,255,255,0.15)",
+		"dot":       "rgba(255,255,255,0.3)",
+	},
+	"light": {
+		"bg":        "#eef1f8",
+		"bg2":       "#ffffff",
+		"accent":    "#e23d5c",
+		"accent2":   "#5b3cc4",
+		"text":      "#1d2033",
+		"muted":     "#5a607a",
+		"highlight": "#1a9fb5",
+		"surface":   "rgba(0,0,0,0.03)",
+		"glow":      "rgba(91,60,196,0.25)",
+		"track":     "rgba(0,0,0,0.08)",
+		"dot":       "rgba(0,0,0,0.2)",
+	},
+}
+
+// Themes — имена встроенных палитр
+func Themes() []string {
+	return slices.Sorted(maps.Keys(themes))
+}
+
+// LoadTheme — палитра name с переменными из CSS-файла file поверх. Любое
+// из двух может быть пустым; оба пустые — nil, шаблон рисуется своими
+// цветами. В файле нужны только объявления переменных:
+//
+//	:root {
+//	  --bg: #1b1b1b;
+//	  --accent: #ff8a00;
+//	}
+func LoadTheme(name, file string) (*stats.Theme, error) {
+	if name == "" && file == "" {
+		return nil, nil
+	}
+	theme := &stats.Theme{Name: name, Vars: map[string]string{}}
+	if name != "" {
+		vars, ok := themes[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown theme %q, known: %s", name, strings.Join(Themes(), ", "))
+		}
+		maps.Copy(theme.Vars, vars)
+	}
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read theme: %w", err)
+		}
+		vars, err := parseThemeVars(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("theme %s: %w", file, err)
+		}
+		maps.Copy(theme.Vars, vars)
+	}
+	return theme, nil
+}
+
+var (
+	cssComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	cssVar     = regexp.MustCompile(`--([A-Za-z0-9_-]+)\s*:\s*([^;{}]*)`)
+)
+
+// parseThemeVars достаёт из CSS объявления --имя: значение. Значения
+// попадают прямо в <style> страницы, поэтому </style> и скобки в них не
+// пропускаются.
+func parseThemeVars(css string) (map[string]string, error) {
+	vars := map[string]string{}
+	for _, m := range cssVar.FindAllStringSubmatch(cssComment.ReplaceAllString(css, ""), -1) {
+		value := strings.TrimSpace(m[2])
+		if value == "" || strings.ContainsAny(value, "<>\\") {
+			return nil, fmt.Errorf("bad value of --%s: %q", m[1], value)
+		}
+		vars[m[1]] = value
+	}
+	if len(vars) == 0 {
+		return nil, fmt.Errorf("no CSS variables (--name: value;) found")
+	}
+	return vars, nil
+}
+
+// themeVars — переменные темы объявлениями CSS для :root, по порядку имён
+func themeVars(vars map[string]string) template.CSS {
+	var b strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(vars)) {
+		fmt.Fprintf(&b, "--%s: %s; ", name, vars[name])
+	}
+	return template.CSS(strings.TrimSpace(b.String()))
+}
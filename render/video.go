@@ -0,0 +1,159 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+)
+
+// VideoFPS — кадров в секунду ролика: слайды неподвижны, кадры нужны только
+// для переходов
+const VideoFPS = 10
+
+// VideoSeconds — сколько по умолчанию держится на экране одна номинация
+const VideoSeconds = 3.0
+
+// videoFade — кадров на переход между слайдами, полсекунды
+const videoFade = VideoFPS / 2
+
+// videoCodecs — чем кодировать по расширению файла
+var videoCodecs = map[string][]string{
+	".mp4":  {"-c:v", "libx264", "-pix_fmt", "yuv420p", "-movflags", "+faststart"},
+	".webm": {"-c:v", "libvpx-vp9", "-pix_fmt", "yuv420p", "-b:v", "0", "-crf", "32"},
+}
+
+// VideoOptions — как собирать ролик
+type VideoOptions struct {
+	Seconds float64 // сколько держать номинацию, 0 — VideoSeconds
+	FFmpeg  string  // путь к ffmpeg, "" — ищется в PATH
+}
+
+// WriteVideo собирает номинации страницы в вертикальный ролик
+// StoryWidth×StoryHeight: обложка, потом по номинации на слайд — карточка
+// как у WriteCards на фоне сторис, — с плавными переходами. Кадры рисуются
+// здесь и сырыми идут в ffmpeg, он кодирует их в outFile: .mp4 (H.264) или
+// .webm (VP9). Пути к картинкам считаются от baseDir, нечитаемые картинки
+// попадают в warnings как *MediaError.
+func WriteVideo(ctx context.Context, page stats.PageData, outFile, baseDir string, opt VideoOptions) (warnings []error, err error) {
+	codec, ok := videoCodecs[strings.ToLower(filepath.Ext(outFile))]
+	if !ok {
+		return nil, fmt.Errorf("unsupported video format %q, use .mp4 or .webm", filepath.Ext(outFile))
+	}
+	if opt.Seconds <= 0 {
+		opt.Seconds = VideoSeconds
+	}
+	if opt.FFmpeg == "" {
+		opt.FFmpeg = "ffmpeg"
+	}
+	ffmpeg, err := exec.LookPath(opt.FFmpeg)
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg is needed for video: %w", err)
+	}
+
+	args := []string{"-y", "-loglevel", "error",
+		"-f", "rawvideo", "-pix_fmt", "rgba", "-s", fmt.Sprintf("%dx%d", StoryWidth, StoryHeight),
+		"-framerate", strconv.Itoa(VideoFPS), "-i", "-"}
+	args = append(append(args, codec...), outFile)
+	cmd := exec.CommandContext(ctx, ffmpeg, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("ffmpeg: %w", err)
+	}
+
+	warnings, werr := writeFrames(ctx, stdin, page, baseDir, int(opt.Seconds*VideoFPS))
+	stdin.Close()
+	// если ffmpeg упал, кадры не запишутся, а причина — в его выводе
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return warnings, ctx.Err()
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return warnings, fmt.Errorf("ffmpeg: %w: %s", err, msg)
+		}
+		return warnings, fmt.Errorf("ffmpeg: %w", err)
+	}
+	return warnings, werr
+}
+
+// writeFrames пишет кадры ролика: каждый слайд hold кадров, между слайдами —
+// videoFade кадров наплыва
+func writeFrames(ctx context.Context, w io.Writer, page stats.PageData, baseDir string, hold int) (warnings []error, err error) {
+	cover, werr := storyCover(page, baseDir)
+	if werr != nil {
+		warnings = append(warnings, werr)
+	}
+	frame := image.NewRGBA(cover.Bounds())
+	prev := cover
+	show := func(slide *image.RGBA, fade bool) error {
+		if fade {
+			for i := 1; i <= videoFade; i++ {
+				blend(frame, prev, slide, float64(i)/(videoFade+1))
+				if _, err := w.Write(frame.Pix); err != nil {
+					return err
+				}
+			}
+		}
+		for i := 0; i < hold; i++ {
+			if _, err := w.Write(slide.Pix); err != nil {
+				return err
+			}
+		}
+		prev = slide
+		return nil
+	}
+
+	if err := show(cover, false); err != nil {
+		return warnings, fmt.Errorf("write frames: %w", err)
+	}
+	for i, n := range allNominations(page) {
+		if err := ctx.Err(); err != nil {
+			return warnings, err
+		}
+		slide, werr := videoSlide(page.Title, n, i+1, baseDir)
+		if werr != nil {
+			warnings = append(warnings, werr)
+		}
+		if err := show(slide, true); err != nil {
+			return warnings, fmt.Errorf("write frames: %w", err)
+		}
+	}
+	return warnings, nil
+}
+
+// videoSlide — слайд одной номинации: заголовок страницы сверху и карточка
+// посередине на фоне сторис; index выбирает фон
+func videoSlide(title string, n stats.Nomination, index int, baseDir string) (*image.RGBA, error) {
+	faces := loadCardFaces()
+	img := storyBackground(index)
+
+	const pad = 80
+	drawLines(img, faces.caption, cardCaption, wrapText(faces.caption, title, StoryWidth-2*pad, 1), pad, StoryWidth-pad, pad, alignCenter)
+
+	card, err := Card(n, baseDir)
+	top := (StoryHeight - CardSize) / 2
+	draw.Draw(img, image.Rect(0, top, CardSize, top+CardSize), card, image.Point{}, draw.Src)
+	return img, err
+}
+
+// blend смешивает два кадра одного размера: t=0 — a, t=1 — b
+func blend(dst, a, b *image.RGBA, t float64) {
+	k := int(t * 256)
+	for i := range dst.Pix {
+		dst.Pix[i] = uint8((int(a.Pix[i])*(256-k) + int(b.Pix[i])*k) >> 8)
+	}
+}
@@ -0,0 +1,89 @@
+package stats
+
+import (
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// Номинации считаются за общий проход по сообщениям: каждая, которая это
+// умеет (Streamer), заводит свой Accumulator, FormPage скармливает ему
+// сообщения по одному и в конце забирает карточку. Время работы растёт
+// с числом сообщений, а не с числом сообщений × номинаций. Проходов —
+// по одному на горутину пула, см. computeAll.
+
+// Accumulator копит статистику одной номинации по сообщениям по порядку;
+// Result — как Compute, false — карточку не показывать
+type Accumulator interface {
+	Add(m telegram.Message)
+	Result() (Nomination, bool)
+}
+
+// Streamer — номинация, которую можно посчитать за общий проход.
+// NewAccumulator может вернуть nil, тогда номинацию считает Compute:
+// так остаются номинации, которым нужны все сообщения сразу (корреляции,
+// отток), и плагины, написанные до накопителей.
+type Streamer interface {
+	Nominator
+	NewAccumulator(s *Settings) Accumulator
+}
+
+// AccumulatorFunc делает номинацию из конструктора накопителя
+func AccumulatorFunc(name string, acc func(*Settings) Accumulator) Nominator {
+	return funcNominator{name: name, acc: acc}
+}
+
+// accFunc — накопитель из двух замыканий над общим состоянием
+type accFunc struct {
+	add    func(m telegram.Message)
+	result func() (Nomination, bool)
+}
+
+func (a accFunc) Add(m telegram.Message)     { a.add(m) }
+func (a accFunc) Result() (Nomination, bool) { return a.result() }
+
+// tally — накопитель «сколько у кого»: сообщение добавляет weight к ключу
+// key (0 — не в счёт), result получает итоговые суммы
+func tally(key func(telegram.Message) string, weight func(telegram.Message) int, result func(map[string]int) (Nomination, bool)) Accumulator {
+	counts := map[string]int{}
+	return accFunc{
+		add: func(m telegram.Message) {
+			if w := weight(m); w != 0 {
+				counts[key(m)] += w
+			}
+		},
+		result: func() (Nomination, bool) { return result(counts) },
+	}
+}
+
+// each — вес 1 для сообщений, прошедших filter
+func each(filter func(telegram.Message) bool) func(telegram.Message) int {
+	return func(m telegram.Message) int {
+		if filter(m) {
+			return 1
+		}
+		return 0
+	}
+}
+
+// boardResult — карточка name по таблице лидеров; если required, без
+// единого подходящего сообщения карточки нет
+func (s *Settings) boardResult(name string, findMax, required bool) func(map[string]int) (Nomination, bool) {
+	return func(counts map[string]int) (Nomination, bool) {
+		board := Leaderboard(s, counts, findMax)
+		if required && len(board) == 0 {
+			return Nomination{}, false
+		}
+		return s.boardCard(name, board), true
+	}
+}
+
+// feed прогоняет сообщения через накопитель; так Compute считает
+// номинацию-накопитель отдельно от страницы
+func feed(acc Accumulator, msg []telegram.Message) (Nomination, bool) {
+	for _, m := range msg {
+		acc.Add(m)
+	}
+	return acc.Result()
+}
+
+// accumulateCheck — как часто проход проверяет отмену, в сообщениях
+const accumulateCheck = 1 << 14
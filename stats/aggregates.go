@@ -0,0 +1,242 @@
+package stats
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// Aggregates — числа, из которых собираются номинации: счётчики по
+// участникам, сообщения по дням и часам, таблицы эмодзи. Для своей
+// вёрстки или таблицы, см. generate -out-format json. Отказавшиеся от
+// участия (Options.OptOut) и сообщения от имени чата в users не попадают, но
+// в общих суммах есть.
+type Aggregates struct {
+	Messages  int          `json:"messages"`
+	Users     []UserStats  `json:"users"`     // по убыванию числа сообщений
+	Days      []DayCount   `json:"days"`      // только дни с сообщениями, по порядку
+	Hours     [24]int      `json:"hours"`     // сообщения по часам суток
+	Months    [12]int      `json:"months"`    // сообщения по месяцам, с января
+	Week      [7][24]int   `json:"week"`      // сообщения по дням недели с понедельника и часам
+	Media     MediaCounts  `json:"media"`     // сообщения с вложениями по видам
+	Emoji     []EmojiCount `json:"emoji"`     // эмодзи в текстах, от частых
+	Reactions []EmojiCount `json:"reactions"` // реакции под сообщениями, от частых
+
+	ReactionMonths [12]string     `json:"reaction_months"` // самая частая реакция каждого месяца, "" — реакций не было
+	ReactionMatrix ReactionMatrix `json:"reaction_matrix"` // кто кому ставил реакции, все участники
+
+	lang string // язык заголовков UserTable
+}
+
+// UserStats — счётчики одного участника
+type UserStats struct {
+	ID                string `json:"id"`
+	Name              string `json:"name,omitempty"`
+	Messages          int    `json:"messages"`
+	Words             int    `json:"words"`
+	Texts             int    `json:"texts"` // сообщений с текстом
+	Chars             int    `json:"chars"` // символов в них
+	Photos            int    `json:"photos"`
+	Videos            int    `json:"videos"` // кружочки
+	Voice             int    `json:"voice"`  // голосовые
+	VoiceSeconds      int    `json:"voice_seconds"`
+	Stickers          int    `json:"stickers"`
+	GIFs              int    `json:"gifs"`
+	Forwards          int    `json:"forwards"`
+	ReactionsReceived int    `json:"reactions_received"`
+	ReactionsGiven    int    `json:"reactions_given"` // по recent в экспорте, то есть не все
+	ActiveDays        int    `json:"active_days"`
+}
+
+// DayCount — сообщений за день
+type DayCount struct {
+	Day      string `json:"day"` // 2025-01-31
+	Messages int    `json:"messages"`
+}
+
+// EmojiCount — сколько раз встретился эмодзи
+type EmojiCount struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+}
+
+// ComputeAggregates считает Aggregates за один проход по шине событий
+func ComputeAggregates(s *Settings, msg []telegram.Message) *Aggregates {
+	a := newAggregator(s)
+	var b Bus
+	a.subscribe(&b)
+	for i := range msg {
+		b.Publish(&msg[i])
+	}
+	return a.result()
+}
+
+type aggregator struct {
+	s         *Settings
+	total     int
+	users     map[string]*UserStats
+	days      map[string]map[string]bool // участник → дни
+	perDay    map[string]int
+	hours     [24]int
+	perMonth  [12]int
+	week      [7][24]int
+	media     MediaCounts
+	emoji     map[string]int
+	reactions map[string]int
+	months    [12]map[string]int // реакции по месяцам
+	pairs     reactionPairs
+}
+
+func newAggregator(s *Settings) *aggregator {
+	return &aggregator{
+		s:         s,
+		users:     map[string]*UserStats{},
+		days:      map[string]map[string]bool{},
+		perDay:    map[string]int{},
+		emoji:     map[string]int{},
+		reactions: map[string]int{},
+		pairs:     reactionPairs{},
+	}
+}
+
+func (a *aggregator) user(id string) *UserStats {
+	if a.users[id] == nil {
+		a.users[id] = &UserStats{ID: id}
+		a.days[id] = map[string]bool{}
+	}
+	return a.users[id]
+}
+
+func (a *aggregator) subscribe(b *Bus) {
+	b.On(MessageEvent, func(e BusEvent) {
+		m := e.Message
+		day := m.Date.Format(time.DateOnly)
+		a.total++
+		a.perDay[day]++
+		a.hours[m.Date.Hour()]++
+		a.perMonth[m.Date.Month()-1]++
+		a.week[mondayFirst(m.Date.Weekday())][m.Date.Hour()]++
+		if !FilterUser(*m) {
+			return
+		}
+		u := a.user(m.FromID)
+		a.days[m.FromID][day] = true
+		a.media.add(*m)
+		u.Messages++
+		if filterUserForward(*m) {
+			u.Forwards++
+		}
+		switch {
+		case m.Photo != "":
+			u.Photos++
+		case filterVideo(*m):
+			u.Videos++
+		case m.MediaType == "voice_message":
+			u.Voice++
+			u.VoiceSeconds += max(m.DurationSeconds, 0)
+		case m.MediaType == "sticker":
+			u.Stickers++
+		case filterGIF(*m):
+			u.GIFs++
+		}
+	})
+	b.On(TextMessage, func(e BusEvent) {
+		m := e.Message
+		for _, r := range m.Text {
+			if isEmoji(r) {
+				a.emoji[string(r)]++
+			}
+		}
+		if FilterUser(*m) {
+			u := a.user(m.FromID)
+			u.Words += len(strings.Fields(a.s.NormalizeText(m.Text)))
+			u.Texts++
+			u.Chars += utf8.RuneCountInString(m.Text)
+		}
+	})
+	b.On(Reaction, func(e BusEvent) {
+		r := e.Reaction
+		key := reactionKey(r)
+		a.reactions[key] += r.Count
+		if r.Count > 0 {
+			month := e.Message.Date.Month() - 1
+			if a.months[month] == nil {
+				a.months[month] = map[string]int{}
+			}
+			a.months[month][key] += r.Count
+		}
+		if FilterUser(*e.Message) {
+			a.user(e.Message.FromID).ReactionsReceived += r.Count
+		}
+		for _, u := range r.Recent {
+			if u.FromID != "" && u.FromID != telegram.ChatSenderID {
+				a.user(u.FromID).ReactionsGiven++
+			}
+		}
+		a.pairs.add(e)
+	})
+}
+
+func (a *aggregator) result() *Aggregates {
+	res := &Aggregates{
+		Messages:  a.total,
+		Users:     []UserStats{},
+		Days:      []DayCount{},
+		Hours:     a.hours,
+		Months:    a.perMonth,
+		Week:      a.week,
+		Media:     a.media,
+		Emoji:     emojiTable(a.emoji),
+		Reactions: emojiTable(a.reactions),
+
+		ReactionMonths: dominantReactions(&a.months),
+		ReactionMatrix: a.pairs.matrix(a.s, 0),
+
+		lang: a.s.lang,
+	}
+	for id, u := range a.users {
+		if a.s.optedOut(id) {
+			continue
+		}
+		u.Name = a.s.avatars.Names[id]
+		u.ActiveDays = len(a.days[id])
+		res.Users = append(res.Users, *u)
+	}
+	sort.Slice(res.Users, func(i, j int) bool {
+		if res.Users[i].Messages != res.Users[j].Messages {
+			return res.Users[i].Messages > res.Users[j].Messages
+		}
+		return res.Users[i].ID < res.Users[j].ID
+	})
+	for day, n := range a.perDay {
+		res.Days = append(res.Days, DayCount{Day: day, Messages: n})
+	}
+	sort.Slice(res.Days, func(i, j int) bool { return res.Days[i].Day < res.Days[j].Day })
+	return res
+}
+
+// emojiTable — counts от частых к редким, при равенстве — по эмодзи
+func emojiTable(counts map[string]int) []EmojiCount {
+	list := make([]EmojiCount, 0, len(counts))
+	for e, n := range counts {
+		list = append(list, EmojiCount{Emoji: e, Count: n})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Emoji < list[j].Emoji
+	})
+	return list
+}
+
+// AvgLength — средняя длина текста в символах
+func (u UserStats) AvgLength() float64 {
+	if u.Texts == 0 {
+		return 0
+	}
+	return float64(u.Chars) / float64(u.Texts)
+}
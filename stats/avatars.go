@@ -0,0 +1,266 @@
+package stats
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// AvatarSet находит аватарку пользователя: сначала в profile_pictures
+// экспорта, потом в images/<id>.jpg, иначе рисует заглушку с инициалами.
+// Там же общие картинки страницы из images и nominations конфига.
+type AvatarSet struct {
+	BaseDir string            // папка, относительно которой пишутся пути в HTML
+	ByID    map[string]string // from_id → путь к фото
+	Names   map[string]string // from_id → имя для заглушки
+	missing map[string]bool   // пути из конфига, файлов по которым нет
+	mu      sync.Mutex        // missing пишут номинации из разных горутин
+
+	CommonPath      string            // картинка номинаций без участника
+	CoverPath       string            // обложка
+	PlaceholderPath string            // картинка вместо заглушки с инициалами
+	Nominations     map[string]string // имя номинации в нижнем регистре → картинка
+}
+
+// NewAvatarSet — пустой набор, пути в HTML считаются от baseDir
+func NewAvatarSet(baseDir string) *AvatarSet {
+	return &AvatarSet{
+		BaseDir:     baseDir,
+		ByID:        map[string]string{},
+		Names:       map[string]string{},
+		missing:     map[string]bool{},
+		Nominations: map[string]string{},
+	}
+}
+
+var digitsRe = regexp.MustCompile(`\d+`)
+
+// LoadAvatars собирает аватарки для пользователей из сообщений.
+// exportDirs — папки с result.json, baseDir — папка выходного HTML.
+func LoadAvatars(exportDirs []string, baseDir string, msg []telegram.Message) *AvatarSet {
+	set := NewAvatarSet(baseDir)
+
+	// числовой id → from_id ("123" → "user123"); у id из одних букв (имена
+	// WhatsApp и Discord) числа нет, их фото ищутся только в images
+	ids := map[string]string{}
+	for _, m := range msg {
+		if !FilterUser(m) {
+			continue
+		}
+		if m.From != "" {
+			set.Names[m.FromID] = m.From
+		} else if _, ok := set.Names[m.FromID]; !ok {
+			set.Names[m.FromID] = ""
+		}
+		if num := strings.TrimLeftFunc(m.FromID, unicode.IsLetter); num != "" {
+			ids[num] = m.FromID
+		}
+	}
+
+	// profile_pictures/*: ищем в имени файла числовой id пользователя
+	var files []string
+	for _, dir := range exportDirs {
+		found, _ := filepath.Glob(filepath.Join(dir, "profile_pictures", "*"))
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+	for _, f := range files {
+		for _, num := range digitsRe.FindAllString(filepath.Base(f), -1) {
+			id, ok := ids[num]
+			if !ok {
+				continue
+			}
+			if _, seen := set.ByID[id]; !seen {
+				set.ByID[id] = set.Rel(f)
+			}
+		}
+	}
+
+	// images/<from_id>.jpg — старая схема, заполняется руками
+	for id := range set.Names {
+		if _, ok := set.ByID[id]; ok {
+			continue
+		}
+		f := filepath.Join(baseDir, "images", id+".jpg")
+		if _, err := os.Stat(f); err == nil {
+			set.ByID[id] = set.Rel(f)
+		}
+	}
+
+	return set
+}
+
+// Rel — путь для <img src> относительно выходного HTML
+func (s *AvatarSet) Rel(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	base, err := filepath.Abs(s.BaseDir)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	r, err := filepath.Rel(base, abs)
+	if err != nil {
+		return filepath.ToSlash(abs)
+	}
+	return filepath.ToSlash(r)
+}
+
+// Get — аватарка участника или заглушка, если фото нет
+func (s *AvatarSet) Get(id string) string {
+	if p, ok := s.ByID[id]; ok && s.exists(p) {
+		return p
+	}
+	return s.placeholder(id)
+}
+
+// placeholder — картинка из images.placeholder или заглушка с инициалами
+func (s *AvatarSet) placeholder(id string) string {
+	if s.PlaceholderPath != "" && s.exists(s.PlaceholderPath) {
+		return s.PlaceholderPath
+	}
+	return placeholderAvatar(id, s.Names[id])
+}
+
+// Find — from_id участника по @нику из упоминания: ник может быть самим
+// from_id или именем, под которым участник пишет
+func (s *AvatarSet) Find(nick string) (string, bool) {
+	nick = strings.TrimPrefix(nick, "@")
+	if _, ok := s.Names[nick]; ok {
+		return nick, true
+	}
+	var found []string
+	for id, name := range s.Names {
+		if name != "" && name == nick {
+			found = append(found, id)
+		}
+	}
+	if len(found) != 1 {
+		return "", false // никого или тёзки — не угадываем
+	}
+	return found[0], true
+}
+
+// Common — картинка для номинаций, у которых нет победителя-участника
+func (s *AvatarSet) Common() string {
+	if s.CommonPath != "" && s.exists(s.CommonPath) {
+		return s.CommonPath
+	}
+	return commonArt
+}
+
+// Cover — обложка страницы; пустая строка, если её нет
+func (s *AvatarSet) Cover() string {
+	if s.CoverPath != "" && s.exists(s.CoverPath) {
+		return s.CoverPath
+	}
+	return ""
+}
+
+func (s *AvatarSet) NominationAvatar(name string) (string, bool) {
+	p, ok := s.Nominations[strings.ToLower(name)]
+	if !ok || !s.exists(p) {
+		return "", false
+	}
+	return p, true
+}
+
+// exists проверяет, что картинка на месте; пропавшие файлы запоминаются,
+// чтобы предупредить о них разом
+func (s *AvatarSet) exists(path string) bool {
+	if strings.Contains(path, ":") && !filepath.IsAbs(path) {
+		return true // data: или http(s): — проверить нечем
+	}
+	full := path
+	if !filepath.IsAbs(full) {
+		full = filepath.Join(s.BaseDir, path)
+	}
+	if _, err := os.Stat(full); err != nil {
+		s.mu.Lock()
+		s.missing[path] = true
+		s.mu.Unlock()
+		return false
+	}
+	return true
+}
+
+// Local — пути картинок набора, которые страница берёт из файлов рядом с
+// собой: без data: и http(s): и без выхода за BaseDir
+func (s *AvatarSet) Local() []string {
+	all := []string{s.CommonPath, s.CoverPath, s.PlaceholderPath}
+	for _, p := range s.ByID {
+		all = append(all, p)
+	}
+	for _, p := range s.Nominations {
+		all = append(all, p)
+	}
+	var local []string
+	for _, p := range all {
+		if p == "" || strings.Contains(p, ":") || !filepath.IsLocal(filepath.FromSlash(p)) {
+			continue
+		}
+		local = append(local, p)
+	}
+	sort.Strings(local)
+	return slices.Compact(local)
+}
+
+// TakeMissing возвращает и забывает пропавшие файлы картинок
+func (s *AvatarSet) TakeMissing() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]string, 0, len(s.missing))
+	for p := range s.missing {
+		list = append(list, p)
+	}
+	sort.Strings(list)
+	clear(s.missing)
+	return list
+}
+
+var placeholderColors = []string{"#ff4c6b", "#6bf2ff", "#ffe066", "#8e7dff", "#4cd08a", "#ff9f43"}
+
+// commonArt — общая картинка по умолчанию, если в конфиге нет images.avatar
+var commonArt = svgAvatar("#8e7dff", "🎉")
+
+// placeholderAvatar — SVG-заглушка с инициалами, цвет зависит от id
+func placeholderAvatar(id, name string) string {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	color := placeholderColors[h.Sum32()%uint32(len(placeholderColors))]
+
+	initials := ""
+	for _, w := range strings.Fields(name) {
+		r := []rune(w)
+		if unicode.IsLetter(r[0]) || unicode.IsDigit(r[0]) {
+			initials += string(unicode.ToUpper(r[0]))
+		}
+		if len([]rune(initials)) == 2 {
+			break
+		}
+	}
+	if initials == "" {
+		initials = "?"
+	}
+
+	return svgAvatar(color, initials)
+}
+
+func svgAvatar(color, text string) string {
+	svg := fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="400" height="400">`+
+		`<rect width="100%%" height="100%%" fill="%s"/>`+
+		`<text x="50%%" y="50%%" font-size="160" font-family="sans-serif" fill="white" dominant-baseline="middle" text-anchor="middle">%s</text>`+
+		`</svg>`, color, text)
+	return "data:image/svg+xml;utf8," + url.PathEscape(svg)
+}
@@ -0,0 +1,44 @@
+package stats_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+)
+
+func TestLoadAvatars(t *testing.T) {
+	dir := t.TempDir()
+	export := filepath.Join(dir, "export")
+	for _, f := range []string{
+		"export/profile_pictures/photo_123@01-01-2025.jpg",
+		"images/anna.jpg",
+		"images/bob.jpg",
+		"images/user456.jpg",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("jpg"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// id из одних букв, как у WhatsApp и Discord, не мешают друг другу
+	set := stats.LoadAvatars([]string{export}, dir, chat(msg("user123"), msg("user456"), msg("anna"), msg("bob"), msg("carl")))
+	for id, want := range map[string]string{
+		"user123": "export/profile_pictures/photo_123@01-01-2025.jpg",
+		"user456": "images/user456.jpg",
+		"anna":    "images/anna.jpg",
+		"bob":     "images/bob.jpg",
+	} {
+		if got := set.ByID[id]; got != want {
+			t.Errorf("%s: avatar %q, want %q", id, got, want)
+		}
+	}
+	if got, ok := set.ByID["carl"]; ok {
+		t.Errorf("carl: avatar %q, want none", got)
+	}
+}
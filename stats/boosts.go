@@ -0,0 +1,24 @@
+package stats
+
+// boosters — кто бустит чат: сумма бустов из "boost_apply". Проход отдаёт
+// коллектору только service-сообщения чатов из msg, так что в разделе чата
+// его бусты; без бустов карточки нет.
+func boosters(s *Settings) Collector {
+	counts := map[string]int{}
+	return collectorFunc{
+		subscribe: func(b *Bus) {
+			b.On(ServiceAction, func(e BusEvent) {
+				m := e.Message
+				if m.Action != "boost_apply" || m.ActorID == "" {
+					return
+				}
+				n := m.Boosts
+				if n <= 0 {
+					n = 1 // в экспорте без boosts — один буст
+				}
+				counts[m.ActorID] += n
+			})
+		},
+		result: func() (Nomination, bool) { return s.boardResult("boosters", true, true)(counts) },
+	}
+}
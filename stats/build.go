@@ -0,0 +1,52 @@
+package stats
+
+import (
+	"os"
+	"runtime/debug"
+	"strconv"
+	"time"
+)
+
+// Version и Commit — чем собрана страница. Задаются при сборке:
+//
+//	go build -ldflags "-X github.com/bebroedik/year-summary-2025/stats.Version=v1.4.0 -X github.com/bebroedik/year-summary-2025/stats.Commit=$(git rev-parse --short HEAD)"
+//
+// Без них берутся из debug.ReadBuildInfo: версия модуля у go install …@v1.4.0,
+// vcs.revision у сборки из git.
+var Version, Commit string
+
+// BuildInfo — подпись внизу страницы: по ней видно, какой код её собрал
+type BuildInfo struct {
+	Version   string    `json:"version"`
+	Commit    string    `json:"commit,omitempty"`
+	Generated time.Time `json:"generated"`
+	Date      string    `json:"date"` // Generated для подписи: «2025-12-31 18:00 UTC»
+}
+
+// Build — версия, коммит и время сборки страницы. Время берётся из
+// SOURCE_DATE_EPOCH, если он задан: так страница по-прежнему
+// воспроизводится байт в байт.
+func Build() BuildInfo {
+	b := BuildInfo{Version: Version, Commit: Commit, Generated: time.Now().UTC().Truncate(time.Second)}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if b.Version == "" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			b.Version = info.Main.Version
+		}
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" && b.Commit == "" {
+				b.Commit = s.Value
+			}
+		}
+	}
+	if b.Version == "" {
+		b.Version = "devel"
+	}
+	if len(b.Commit) > 12 {
+		b.Commit = b.Commit[:12]
+	}
+	if sec, err := strconv.ParseInt(os.Getenv("SOURCE_DATE_EPOCH"), 10, 64); err == nil {
+		b.Generated = time.Unix(sec, 0).UTC()
+	}
+	b.Date = b.Generated.Format("2006-01-02 15:04 UTC")
+	return b
+}
@@ -0,0 +1,104 @@
+package stats
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// activityCalendar — «Календарь года»: сообщения по дням клетками, как
+// календарь вкладов на GitHub; главное число — самый разговорчивый день
+func activityCalendar(s *Settings) Accumulator {
+	var days [366]int
+	year := 0
+	return accFunc{
+		add: func(m telegram.Message) {
+			if year == 0 {
+				year = m.Date.Year()
+			}
+			if m.Date.Year() == year {
+				days[m.Date.YearDay()-1]++
+			}
+		},
+		result: func() (Nomination, bool) {
+			busiest, active := 0, 0
+			for i, n := range days {
+				if n > 0 {
+					active++
+				}
+				if n > days[busiest] {
+					busiest = i
+				}
+			}
+			if active == 0 {
+				return Nomination{}, false
+			}
+			start := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+			d := s.newTextData("")
+			d.Count = days[busiest]
+			d.Date = s.dayLabel(start.AddDate(0, 0, busiest))
+			d.Value = YearCoverage(s.lang, active, year).String()
+			nom := s.card("activityCalendar", d)
+			nom.Avatar = s.avatars.Common()
+			nom.Chart = s.calendarChart(year, days[:])
+			return nom, true
+		},
+	}
+}
+
+// calendarChart рисует год year клетками: столбец — неделя с понедельника,
+// строка — день недели, цвет — сколько в тот день написали (counts по дню
+// года с нуля). У каждой клетки подсказка с точным числом; самый
+// разговорчивый день выделен.
+func (s *Settings) calendarChart(year int, counts []int) string {
+	const cell, step, left, top = 11, 13, 24, 18
+	start := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	total := YearCoverage(s.lang, 0, year).Total
+	offset := mondayFirst(start.Weekday()) // сколько клеток первой недели до 1 января
+	weeks := (offset + total + 6) / 7
+	w, h := left+weeks*step, top+7*step
+	highest := 0
+	for _, v := range counts[:total] {
+		highest = max(highest, v)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="10" fill="#9aa4c8">`, w, h, w, h)
+	// подписи: пн, ср, пт слева и месяц над неделей, где он начинается
+	for _, row := range []int{0, 2, 4} {
+		fmt.Fprintf(&b, `<text x="0" y="%d">%s</text>`, top+row*step+cell-2,
+			html.EscapeString(s.text("weekdaysShort."+strconv.Itoa((row+1)%7), nil)))
+	}
+	for m := time.January; m <= time.December; m++ {
+		day := time.Date(year, m, 1, 0, 0, 0, 0, time.UTC).YearDay() - 1
+		fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`, left+(offset+day)/7*step, top-6, html.EscapeString(s.shortMonth(m)))
+	}
+	for i, v := range counts[:total] {
+		x, y := left+(offset+i)/7*step, top+(offset+i)%7*step
+		d := s.newTextData("")
+		d.Count, d.Date = v, s.weekdayLabel(start.AddDate(0, 0, i))
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="2" %s><title>%s</title></rect>`,
+			x, y, cell, cell, heatFill(v, highest), html.EscapeString(s.text("nominations.activityCalendar.cell", d)))
+	}
+	b.WriteString(`</svg>`)
+	return "data:image/svg+xml;utf8," + url.PathEscape(b.String())
+}
+
+// heatFill — цвет клетки тепловой карты: пустая еле видна, дальше четыре
+// оттенка по доле от самой заполненной, она сама выделена
+func heatFill(v, highest int) string {
+	switch {
+	case v == 0:
+		return `fill="#9aa4c8" fill-opacity="0.15"`
+	case v == highest:
+		return `fill="#ffe066"`
+	}
+	level := math.Ceil(float64(v) * 4 / float64(highest))
+	return fmt.Sprintf(`fill="%s" fill-opacity="%.2f"`, chartColors[0], level/4)
+}
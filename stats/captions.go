@@ -0,0 +1,129 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// NameForms — формы имени участника для подписей: «Саша написал»,
+// «больше всех у Саши», «Саше поставили». Незаданные формы остаются
+// пустыми, и подпись обходится без них.
+type NameForms struct {
+	Nom string `yaml:"nom,omitempty"` // именительный: Саша
+	Gen string `yaml:"gen,omitempty"` // родительный: (у) Саши
+	Dat string `yaml:"dat,omitempty"` // дательный: Саше
+}
+
+// femaleUsers — участницы по полу из users конфига: m или f (male/female,
+// м/ж); ключ — from_id. У остальных глаголы в мужском роде, как было всегда
+func femaleUsers(genders map[string]string) (map[string]bool, error) {
+	female := map[string]bool{}
+	for id, g := range genders {
+		switch strings.ToLower(strings.TrimSpace(g)) {
+		case "f", "female", "ж":
+			female[id] = true
+		case "m", "male", "м", "":
+		default:
+			return nil, fmt.Errorf("user %s: unknown gender %q, want m or f", id, g)
+		}
+	}
+	return female, nil
+}
+
+// compileCaptions разбирает свои подписи номинаций; ключ — имя номинации,
+// значение — text/template с полями captionData:
+// "{{.Nom}} {{.Verb "написал" "написала"}} больше всех"
+func compileCaptions(list map[string]string) (map[string]*template.Template, error) {
+	captions := map[string]*template.Template{}
+	for name, text := range list {
+		t, err := template.New(name).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("caption of %s: %w", name, err)
+		}
+		captions[strings.ToLower(name)] = t
+	}
+	return captions, nil
+}
+
+// captionData — что доступно в шаблоне подписи
+type captionData struct {
+	Name     string // имя победителя, как его подписывают на странице; при ничьей — «Саша и Лена»
+	Nom      string // именительный падеж, по умолчанию Name
+	Gen      string // родительный, пусто, если не задан
+	Dat      string // дательный, пусто, если не задан
+	Subtitle string // число или дата номинации
+	Caption  string // встроенная подпись
+	Female   bool   // победительница, см. Verb
+	Many     bool   // победителей несколько (ничья), см. Verb
+}
+
+// Verb выбирает окончание по полу победителя: {{.Verb "написал" "написала"}};
+// третья форма — для ничьей: {{.Verb "написал" "написала" "написали"}}
+func (d captionData) Verb(masculine, feminine string, plural ...string) string {
+	switch {
+	case d.Many && len(plural) > 0:
+		return plural[0]
+	case d.Female:
+		return feminine
+	}
+	return masculine
+}
+
+func (s *Settings) newCaptionData(n Nomination) captionData {
+	if len(n.Winners) > 1 {
+		return s.tieCaptionData(n)
+	}
+	forms := s.nameForms[n.Winner]
+	d := captionData{
+		Name:     s.avatars.Names[n.Winner],
+		Nom:      forms.Nom,
+		Gen:      forms.Gen,
+		Dat:      forms.Dat,
+		Subtitle: n.Subtitle,
+		Caption:  n.Caption,
+		Female:   s.female[n.Winner],
+	}
+	if n.Winner == telegram.ChatSenderID {
+		d.Name = s.text("chatSender", nil)
+	}
+	if d.Nom == "" {
+		d.Nom = d.Name
+	}
+	return d
+}
+
+// tieCaptionData — подписи при ничьей: имена через «и»; падежи — только
+// если они заданы у всех победителей
+func (s *Settings) tieCaptionData(n Nomination) captionData {
+	d := captionData{Subtitle: n.Subtitle, Caption: n.Caption, Female: true, Many: true}
+	var names, nom, gen, dat []string
+	for _, id := range n.Winners {
+		one := s.newCaptionData(Nomination{Winner: id})
+		names = append(names, one.Name)
+		nom = append(nom, one.Nom)
+		if one.Gen != "" {
+			gen = append(gen, one.Gen)
+		}
+		if one.Dat != "" {
+			dat = append(dat, one.Dat)
+		}
+		d.Female = d.Female && one.Female
+	}
+	d.Name, d.Nom = s.joinNames(names), s.joinNames(nom)
+	if len(gen) == len(n.Winners) {
+		d.Gen = s.joinNames(gen)
+	}
+	if len(dat) == len(n.Winners) {
+		d.Dat = s.joinNames(dat)
+	}
+	return d
+}
+
+// execCaption подставляет в шаблон формы имени победителя; при ошибке
+// остаётся прежняя подпись
+func (s *Settings) execCaption(t *template.Template, n Nomination) string {
+	return execTemplate(t, s.newCaptionData(n), n.Caption)
+}
@@ -0,0 +1,100 @@
+package stats
+
+import (
+	"sort"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// ChurnOptions — пороги карточки «Мы скучаем»: кто в четвёртом квартале
+// стал писать заметно реже, чем в первых трёх
+type ChurnOptions struct {
+	Drop        float64 `yaml:"drop,omitempty"`         // на какую долю упала активность, по умолчанию 0.6 — на 60%
+	MinMessages int     `yaml:"min_messages,omitempty"` // сколько сообщений нужно до октября, по умолчанию 50
+	Max         int     `yaml:"max,omitempty"`          // сколько человек перечислить, по умолчанию 5
+}
+
+// ChurnNominator — номинация weMissYou; карточки нет, если никто не пропал
+func ChurnNominator(opts ChurnOptions) Nominator {
+	if opts.Drop == 0 {
+		opts.Drop = 0.6
+	}
+	if opts.MinMessages == 0 {
+		opts.MinMessages = 50
+	}
+	if opts.Max == 0 {
+		opts.Max = 5
+	}
+	params := struct {
+		ChurnOptions
+		DropPercent float64
+	}{opts, opts.Drop * 100}
+	return funcNominator{name: "weMissYou", params: params, compute: func(s *Settings, msg []telegram.Message) (Nomination, bool) {
+		return s.weMissYou(msg, opts)
+	}}
+}
+
+func (s *Settings) weMissYou(msg []telegram.Message, opts ChurnOptions) (Nomination, bool) {
+	// месяцы, в которых чат вообще жил: экспорт мог закончиться в ноябре
+	before, q4 := map[int]bool{}, map[int]bool{}
+	early, late := map[string]int{}, map[string]int{}
+	for _, m := range msg {
+		month := int(m.Date.Month())
+		if month >= 10 {
+			q4[month] = true
+		} else {
+			before[month] = true
+		}
+		if !FilterUser(m) {
+			continue
+		}
+		if month >= 10 {
+			late[m.FromID]++
+		} else {
+			early[m.FromID]++
+		}
+	}
+	if len(q4) == 0 || len(before) == 0 {
+		return Nomination{}, false
+	}
+
+	type drop struct {
+		id    string
+		ratio float64 // доля прежней активности, оставшаяся в Q4
+	}
+	var drops []drop
+	for id, n := range early {
+		if n < opts.MinMessages || s.optedOut(id) {
+			continue
+		}
+		was := float64(n) / float64(len(before))
+		now := float64(late[id]) / float64(len(q4))
+		if ratio := now / was; ratio <= 1-opts.Drop {
+			drops = append(drops, drop{id, ratio})
+		}
+	}
+	if len(drops) == 0 {
+		return Nomination{}, false
+	}
+	sort.Slice(drops, func(i, j int) bool {
+		if drops[i].ratio != drops[j].ratio {
+			return drops[i].ratio < drops[j].ratio
+		}
+		return drops[i].id < drops[j].id
+	})
+	if len(drops) > opts.Max {
+		drops = drops[:opts.Max]
+	}
+
+	d := s.newTextData(drops[0].id)
+	for _, drop := range drops {
+		d.Names = append(d.Names, s.avatars.Names[drop.id])
+	}
+	if len(drops) == 1 {
+		return s.winnerCard("weMissYou", []string{drops[0].id}, d), true
+	}
+	nom := s.card("weMissYou", d)
+	nom.Caption = s.text("nominations.weMissYou.captionMany", d)
+	nom.Avatar = s.userAvatar(drops[0].id)
+	return nom, true
+}
@@ -0,0 +1,238 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// responseWindow — ответ ли это: сообщение другого участника позже этого
+// срока — уже новый разговор, а не ответ
+const responseWindow = 6 * time.Hour
+
+// fastResponse — ответ, который считается быстрым
+const fastResponse = 5 * time.Minute
+
+// Comparison — два чата друг против друга (команда compare): разделы
+// показателей и счёт по ним
+type Comparison struct {
+	Title  string         `json:"title"`
+	Chats  [2]string      `json:"chats"`
+	Wins   [2]int         `json:"wins"`   // в скольких строках победил каждый чат
+	Result string         `json:"result"` // «Семья побеждает со счётом 7:4»
+	Groups []CompareGroup `json:"groups"`
+	Theme  *Theme         `json:"theme,omitempty"` // ставит команда compare
+
+	Lang   string            `json:"lang"`
+	Labels map[string]string `json:"labels"`
+}
+
+// CompareGroup — раздел сравнения: объём, медиа, скорость ответов
+type CompareGroup struct {
+	Title string       `json:"title"`
+	Rows  []CompareRow `json:"rows"`
+}
+
+// CompareRow — один показатель двух чатов
+type CompareRow struct {
+	Key    string    `json:"key"`
+	Label  string    `json:"label"`
+	Values [2]string `json:"values"`
+	Bars   [2]int    `json:"bars"`   // длина полосы, процент от большего из двух
+	Winner int       `json:"winner"` // 0 или 1, -1 — поровну или не с чем сравнивать
+}
+
+// chatFigures — из чего складываются строки сравнения одного чата
+type chatFigures struct {
+	agg       *Aggregates
+	responses []time.Duration // паузы перед ответами, по возрастанию
+}
+
+func newChatFigures(s *Settings, msg []telegram.Message) chatFigures {
+	f := chatFigures{agg: ComputeAggregates(s, msg)}
+	var prev *telegram.Message
+	for i := range msg {
+		m := &msg[i]
+		if !FilterUser(*m) {
+			continue
+		}
+		if prev != nil && prev.FromID != m.FromID {
+			if gap := m.Date.Sub(prev.Date); gap >= 0 && gap <= responseWindow {
+				f.responses = append(f.responses, gap)
+			}
+		}
+		prev = m
+	}
+	slices.Sort(f.responses)
+	return f
+}
+
+// users складывает поле value по всем участникам
+func (f chatFigures) users(value func(UserStats) int) int {
+	n := 0
+	for _, u := range f.agg.Users {
+		n += value(u)
+	}
+	return n
+}
+
+// share — доля сообщений с полем value
+func (f chatFigures) share(value func(UserStats) int) float64 {
+	if f.agg.Messages == 0 {
+		return 0
+	}
+	return float64(f.users(value)) / float64(f.agg.Messages)
+}
+
+// compareMetric — строка сравнения: как её посчитать и как показать
+type compareMetric struct {
+	key    string
+	value  func(chatFigures) (float64, bool) // false — посчитать не из чего
+	format func(l string, v float64) string  // значение на языке l
+	lower  bool                              // выигрывает меньшее значение
+}
+
+func formatCount(_ string, v float64) string   { return strconv.Itoa(int(v)) }
+func formatPercent(_ string, v float64) string { return fmt.Sprintf("%.0f%%", v*100) }
+
+// countMetric — строка-число: больше всего сообщений, слов, …
+func countMetric(key string, value func(chatFigures) int) compareMetric {
+	return compareMetric{key: key, value: func(f chatFigures) (float64, bool) { return float64(value(f)), true }, format: formatCount}
+}
+
+// shareMetric — строка-доля сообщений одного вида
+func shareMetric(key string, value func(UserStats) int) compareMetric {
+	return compareMetric{key: key, value: func(f chatFigures) (float64, bool) { return f.share(value), f.agg.Messages > 0 }, format: formatPercent}
+}
+
+// compareGroups — разделы сравнения по порядку страницы, подписи — в
+// каталоге, compare.<раздел> и compare.<строка>
+var compareGroups = []struct {
+	key     string
+	metrics []compareMetric
+}{
+	{"volume", []compareMetric{
+		countMetric("messages", func(f chatFigures) int { return f.agg.Messages }),
+		countMetric("members", func(f chatFigures) int { return len(f.agg.Users) }),
+		countMetric("words", func(f chatFigures) int { return f.users(func(u UserStats) int { return u.Words }) }),
+		countMetric("activeDays", func(f chatFigures) int { return len(f.agg.Days) }),
+		{key: "perDay", value: func(f chatFigures) (float64, bool) {
+			if len(f.agg.Days) == 0 {
+				return 0, false
+			}
+			return float64(f.agg.Messages) / float64(len(f.agg.Days)), true
+		}, format: func(_ string, v float64) string { return strconv.FormatFloat(v, 'f', 1, 64) }},
+		{key: "reactions", value: func(f chatFigures) (float64, bool) {
+			if f.agg.Messages == 0 {
+				return 0, false
+			}
+			return float64(f.users(func(u UserStats) int { return u.ReactionsReceived })) / float64(f.agg.Messages), true
+		}, format: func(_ string, v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }},
+	}},
+	{"media", []compareMetric{
+		shareMetric("photos", func(u UserStats) int { return u.Photos }),
+		shareMetric("videos", func(u UserStats) int { return u.Videos }),
+		shareMetric("voice", func(u UserStats) int { return u.Voice }),
+		shareMetric("stickers", func(u UserStats) int { return u.Stickers }),
+		shareMetric("gifs", func(u UserStats) int { return u.GIFs }),
+		shareMetric("forwards", func(u UserStats) int { return u.Forwards }),
+	}},
+	{"speed", []compareMetric{
+		{key: "response", lower: true, value: func(f chatFigures) (float64, bool) {
+			if len(f.responses) == 0 {
+				return 0, false
+			}
+			return float64(f.responses[len(f.responses)/2]), true
+		}, format: func(l string, v float64) string { return HumanDuration(l, time.Duration(v)) }},
+		{key: "fastResponses", value: func(f chatFigures) (float64, bool) {
+			if len(f.responses) == 0 {
+				return 0, false
+			}
+			fast, _ := slices.BinarySearch(f.responses, fastResponse+1)
+			return float64(fast) / float64(len(f.responses)), true
+		}, format: formatPercent},
+	}},
+}
+
+// CompareChats сравнивает сообщения двух чатов a и b, names — их названия.
+// Объём, доли медиа и скорость ответов: ответом считается сообщение
+// другого участника не позже чем через responseWindow, скорость — медиана
+// этих пауз. Каждая строка — очко тому, у кого больше (у времени ответа —
+// меньше).
+func CompareChats(s *Settings, a, b []telegram.Message, names [2]string) Comparison {
+	figures := [2]chatFigures{newChatFigures(s, a), newChatFigures(s, b)}
+	year := yearOf(a)
+	if len(a) == 0 {
+		year = yearOf(b)
+	}
+	c := Comparison{
+		Title: s.text("compare.title", struct {
+			A, B string
+			Year int
+		}{names[0], names[1], year}),
+		Chats:  names,
+		Lang:   s.lang,
+		Labels: Labels(s.lang),
+	}
+	for _, g := range compareGroups {
+		group := CompareGroup{Title: s.text("compare."+g.key, nil)}
+		for _, m := range g.metrics {
+			row := CompareRow{Key: m.key, Label: s.text("compare."+m.key, nil), Winner: -1}
+			var values [2]float64
+			var ok [2]bool
+			for i, f := range figures {
+				values[i], ok[i] = m.value(f)
+				row.Values[i] = "—"
+				if ok[i] {
+					row.Values[i] = m.format(s.lang, values[i])
+				}
+			}
+			if top := math.Max(values[0], values[1]); top > 0 {
+				row.Bars = [2]int{int(math.Round(values[0] / top * 100)), int(math.Round(values[1] / top * 100))}
+			}
+			if ok[0] && ok[1] && values[0] != values[1] {
+				row.Winner = 0
+				if (values[1] > values[0]) != m.lower {
+					row.Winner = 1
+				}
+				c.Wins[row.Winner]++
+			}
+			group.Rows = append(group.Rows, row)
+		}
+		c.Groups = append(c.Groups, group)
+	}
+
+	result := struct {
+		Name         string
+		Wins, Losses int
+	}{Wins: c.Wins[0], Losses: c.Wins[1]}
+	switch {
+	case c.Wins[0] > c.Wins[1]:
+		result.Name = names[0]
+		c.Result = s.text("compare.winner", result)
+	case c.Wins[1] > c.Wins[0]:
+		result.Name, result.Wins, result.Losses = names[1], c.Wins[1], c.Wins[0]
+		c.Result = s.text("compare.winner", result)
+	default:
+		c.Result = s.text("compare.draw", result)
+	}
+	return c
+}
+
+// CompareExports — CompareChats по сообщениям нескольких экспортов: в них
+// должно быть ровно два чата
+func CompareExports(s *Settings, msg []telegram.Message) (Comparison, error) {
+	chats := chatNames(msg)
+	if len(chats) != 2 {
+		return Comparison{}, fmt.Errorf("compare needs exactly two chats, got %d: %q", len(chats), chats)
+	}
+	var split [2][]telegram.Message
+	for i, chat := range chats {
+		split[i] = FilterMessages(msg, func(m telegram.Message) bool { return m.Chat == chat })
+	}
+	return CompareChats(s, split[0], split[1], [2]string{chats[0], chats[1]}), nil
+}
@@ -0,0 +1,175 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// Сообщения для Compute — telegram.Message, к которому приводятся все
+// форматы экспортов. Свои источники (боты, базы) заполняют то же самое:
+//
+//	Type            "message" (пусто — тоже сообщение) или "service"
+//	Date            время сообщения, обязательно; дни и часы считаются в его часовом поясе
+//	FromID          постоянный id автора, обязательно: по нему номинации, аватарки и opt_out
+//	From            имя автора для подписей
+//	Text            текст целиком
+//	TextEntities    разметка; нужны только {Type: "mention", Text: "@ник"} и "custom_emoji"
+//	MediaType       пусто для текста, иначе sticker, animation, video_file,
+//	                video_message, voice_message, audio_file, story
+//	Photo           непусто, если в сообщении фото
+//	DurationSeconds длительность голосовых и кружков
+//	FileSize        размер вложения в байтах
+//	ForwardedFrom   непусто у пересланных; ForwardedFromID — "channel…" у репостов из каналов
+//	Reactions       реакции: Emoji, Count и Recent — кто поставил
+//	Chat            название чата, если сообщения из нескольких чатов
+//
+// У service-сообщений — Actor, ActorID, Action и поля действия (Title,
+// Members, Boosts); из них хроника и бусты. Остальные поля можно не заполнять.
+
+// Options — настройки Compute; нулевое значение — страница по умолчанию.
+// Из них же NewSettings собирает Settings для номинаций.
+type Options struct {
+	Year        int                  // год итогов; 0 — последний полный год (DetectYear)
+	Language    string               // язык подписей, ru (по умолчанию) или en
+	Nominations []string             // только эти номинации и в этом порядке; пусто — все включённые в Registry
+	Registry    *Registry            // номинации страницы; nil — Nominators
+	Title       string               // заголовок страницы вместо «<чат> — итоги <год>»
+	Avatars     *AvatarSet           // картинки участников; nil — поиск в AvatarDirs
+	AvatarDirs  []string             // папки экспортов, аватарки ищутся в их profile_pictures/; пусто — заглушки с инициалами
+	OptOut      []string             // from_id или @ник тех, кого не показывать
+	Timeline    bool                 // добавить хронику года; с Minimal не добавляется
+	Methodology bool                 // добавить приложение «Как считали»
+	Leaderboard int                  // добавить общий рейтинг из стольких участников; 0 — без него
+	Workers     int                  // сколько номинаций считать одновременно; 0 — по числу процессоров (GOMAXPROCS)
+	Thresholds  Thresholds           // пороги карточек и хроники; нулевые поля — по умолчанию
+	Normalize   []string             // шаги нормализации текста: nfc, yo, lower, spaces; nil — DefaultNormalization, "none" — без неё
+	NameForms   map[string]NameForms // формы имён для подписей, ключ — from_id
+	Genders     map[string]string    // пол участников для окончаний глаголов: m или f (male/female, м/ж); ключ — from_id
+	Captions    map[string]string    // свои подписи номинаций, см. captionData; ключ — имя номинации
+	Podium      int                  // сколько мест показывать под карточками с участниками; 0 — только победителя
+	Podiums     map[string]int       // то же у отдельных номинаций, поверх Podium; ключ — имя номинации
+	Minimal     bool                 // только числа: цитаты заменяются длиной текста, см. MinimizeMessages
+}
+
+// registry — Registry или общий Nominators
+func (o Options) registry() *Registry {
+	if o.Registry != nil {
+		return o.Registry
+	}
+	return Nominators
+}
+
+// Result — что посчитал Compute
+type Result struct {
+	Page     PageData
+	Year     int      // за какой год
+	Messages int      // сколько сообщений вошло в подсчёт
+	Timings  []Timing // сколько считалась каждая номинация
+	Missing  []string // картинки из конфига, которых нет на диске, см. AvatarSet.TakeMissing
+}
+
+// Compute считает итоги года по готовым сообщениям из любого источника.
+// msg может содержать и service-сообщения; пустые From и FromID Compute
+// дополняет прямо в msg (BackfillSenders). Результат зависит только от msg
+// и opts, так что вызовы с разными opts могут идти параллельно.
+func Compute(msg []telegram.Message, opts Options) (Result, error) {
+	return ComputeContext(context.Background(), msg, opts)
+}
+
+// ComputeContext — Compute, который можно прервать
+func ComputeContext(ctx context.Context, msg []telegram.Message, opts Options) (Result, error) {
+	registry := opts.registry()
+	var list []Nominator
+	for _, name := range opts.Nominations {
+		n, ok := registry.Lookup(name)
+		if !ok {
+			return Result{}, fmt.Errorf("unknown nomination %q, known: %s", name, strings.Join(registry.Names(), ", "))
+		}
+		list = append(list, n)
+	}
+	if len(list) == 0 {
+		list = registry.Enabled()
+	}
+
+	BackfillSenders(msg)
+	var messages, service []telegram.Message
+	for _, m := range msg {
+		if m.Date.IsZero() {
+			return Result{}, fmt.Errorf("message %d: no date", m.ID)
+		}
+		switch m.Type {
+		case "", "message":
+			m.Type = "message"
+			messages = append(messages, m)
+		case "service":
+			service = append(service, m)
+		}
+	}
+
+	year := opts.Year
+	if year == 0 {
+		year = DetectYear(messages)
+	}
+	messages = FilterMessages(messages, FilterYear(year))
+	service = FilterMessages(service, FilterYear(year))
+	if len(messages) == 0 {
+		return Result{}, fmt.Errorf("no messages for %d", year)
+	}
+
+	s, err := NewSettings(opts, messages, service)
+	if err != nil {
+		return Result{}, err
+	}
+	page, err := formPage(ctx, s, messages, list)
+	if err != nil {
+		return Result{}, err
+	}
+	if opts.Title != "" {
+		page.Title = opts.Title
+	}
+	// в хронике цитаты лучших постов, имена и названия чата — как и CLI,
+	// в обезличенном отчёте её нет
+	if opts.Timeline && !opts.Minimal {
+		page.Timeline = Timeline(s, append(service, messages...))
+	}
+	if opts.Methodology {
+		page.Methodology = Methodology(s, page.Nominations)
+	}
+	if opts.Leaderboard > 0 {
+		page.TopUsers = TopUsers(s, messages, opts.Leaderboard)
+	}
+	return Result{Page: page, Year: year, Messages: len(messages), Timings: s.TakeTimings(), Missing: s.avatars.TakeMissing()}, nil
+}
+
+// Evaluate считает одну номинацию name по msg с настройками opts, как
+// Compute, но без фильтра по году и без страницы: для тестов номинаций
+// (см. statstest). ok — false, если карточки не было бы на странице.
+func Evaluate(name string, msg []telegram.Message, opts Options) (nom Nomination, ok bool, err error) {
+	registry := opts.registry()
+	n, found := registry.Lookup(name)
+	if !found {
+		return Nomination{}, false, fmt.Errorf("unknown nomination %q, known: %s", name, strings.Join(registry.Names(), ", "))
+	}
+	msg = append([]telegram.Message(nil), msg...) // BackfillSenders правит на месте
+	BackfillSenders(msg)
+	var messages, service []telegram.Message
+	for _, m := range msg {
+		switch m.Type {
+		case "", "message":
+			m.Type = "message"
+			messages = append(messages, m)
+		case "service":
+			service = append(service, m)
+		}
+	}
+
+	s, err := NewSettings(opts, messages, service)
+	if err != nil {
+		return Nomination{}, false, err
+	}
+	nom, ok = Nominate(s, n, messages)
+	return nom, ok, nil
+}
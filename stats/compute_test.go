@@ -0,0 +1,33 @@
+package stats_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+)
+
+// В режиме Minimal на странице нет текстов сообщений, даже если просили
+// хронику: в ней цитаты постов с реакциями
+func TestComputeMinimalTimeline(t *testing.T) {
+	const secret = "секретный текст"
+	messages := chat(
+		msg("anna", text(secret), reaction("❤", "bob", "carl")),
+		msg("bob", text(secret+" в ответ"), reaction("👍", "anna")),
+		msg("carl", text("просто так")),
+	)
+	for _, minimal := range []bool{false, true} {
+		res, err := stats.Compute(append(messages[:0:0], messages...), stats.Options{Timeline: true, Minimal: minimal})
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := json.Marshal(res.Page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if leaked := strings.Contains(string(data), secret); leaked == minimal {
+			t.Errorf("minimal %v: message text on the page = %v", minimal, leaked)
+		}
+	}
+}
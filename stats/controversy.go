@@ -0,0 +1,90 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// controversialPost — «Спорный пост года»: сообщение, реакции под которым
+// разошлись сильнее всего. Разброс — энтропия Шеннона по эмодзи реакций:
+// поровну 👍 и 🤡 дают больше, чем десять 👍 и один 🤡. Учитываются
+// сообщения хотя бы с s.limits.ControversyMinReactions реакциями двух видов;
+// при равной энтропии выигрывает сообщение с большим числом реакций, потом —
+// более раннее.
+func controversialPost(s *Settings) Collector {
+	var (
+		best      telegram.Message
+		bestTable []EmojiCount
+		bestH     float64
+		bestTotal int
+		found     bool
+	)
+	return collectorFunc{
+		subscribe: func(b *Bus) {
+			b.On(MessageEvent, func(e BusEvent) {
+				m := e.Message
+				if len(m.Reactions) < 2 {
+					return
+				}
+				counts := map[string]int{}
+				total := 0
+				for i := range m.Reactions {
+					if n := m.Reactions[i].Count; n > 0 {
+						counts[reactionKey(&m.Reactions[i])] += n
+						total += n
+					}
+				}
+				if len(counts) < 2 || total < s.limits.ControversyMinReactions {
+					return
+				}
+				table := emojiTable(counts)
+				h := entropy(table, total)
+				if found && (h < bestH || h == bestH && (total < bestTotal || total == bestTotal && !m.Date.Before(best.Date))) {
+					return
+				}
+				best, bestTable, bestH, bestTotal, found = *m, table, h, total, true
+			})
+		},
+		result: func() (Nomination, bool) {
+			if !found {
+				return Nomination{}, false
+			}
+			parts := make([]string, len(bestTable))
+			for i, e := range bestTable {
+				parts[i] = fmt.Sprintf("%s %d", e.Emoji, e.Count)
+			}
+			d := s.newTextData(best.FromID)
+			if d.Name == "" {
+				d.Name = best.From
+			}
+			d.Count, d.Rate, d.Date = bestTotal, bestH, s.dayLabel(best.Date)
+			d.Value = strings.Join(parts, " · ")
+			nom := s.card("controversialPost", d)
+			if best.Text != "" {
+				quoted := []rune(s.quote(best.Text))
+				if len(quoted) > topTextRunes {
+					quoted = append(quoted[:topTextRunes], '…')
+				}
+				d.Value = string(quoted)
+				nom.Caption = s.text("nominations.controversialPost.quote", d)
+			}
+			nom.Avatar = s.userAvatar(best.FromID)
+			nom.Winner = best.FromID
+			return s.redact(nom, best.FromID), true
+		},
+	}
+}
+
+// entropy — энтропия Шеннона распределения table в битах; таблица
+// упорядочена, так что при равных долях и сумма выходит одна и та же
+func entropy(table []EmojiCount, total int) float64 {
+	h := 0.0
+	for _, e := range table {
+		p := float64(e.Count) / float64(total)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
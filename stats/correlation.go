@@ -0,0 +1,143 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// syncedSouls — пара участников, которые пишут в одни и те же дни:
+// наибольшая корреляция Пирсона по числу сообщений за день
+func syncedSouls(s *Settings, msg []telegram.Message) (Nomination, bool) {
+	if len(msg) == 0 {
+		return Nomination{}, false
+	}
+	start := msg[0].Date
+	for _, m := range msg {
+		if m.Date.Before(start) {
+			start = m.Date
+		}
+	}
+	start = start.Truncate(24 * time.Hour)
+
+	daily := map[string][]float64{}
+	total := map[string]int{}
+	for _, m := range msg {
+		if !FilterUser(m) {
+			continue
+		}
+		day := int(m.Date.Sub(start).Hours() / 24)
+		s := daily[m.FromID]
+		for len(s) <= day {
+			s = append(s, 0)
+		}
+		s[day]++
+		daily[m.FromID] = s
+		total[m.FromID]++
+	}
+
+	var users []string
+	days := 0
+	for id, n := range total {
+		if n >= s.limits.CorrMinMessages && !s.optedOut(id) {
+			users = append(users, id)
+			days = max(days, len(daily[id]))
+		}
+	}
+	sort.Strings(users)
+
+	bestR, a, b := math.Inf(-1), "", ""
+	for i := range users {
+		for j := i + 1; j < len(users); j++ {
+			r := pearson(pad(daily[users[i]], days), pad(daily[users[j]], days))
+			if r > bestR {
+				bestR, a, b = r, users[i], users[j]
+			}
+		}
+	}
+	if a == "" || math.IsNaN(bestR) || bestR < s.limits.CorrMinR {
+		return Nomination{}, false
+	}
+
+	d := s.newTextData(a)
+	d.Names = []string{s.avatars.Names[a], s.avatars.Names[b]}
+	d.Rate = bestR
+	nom := s.winnerCard("syncedSouls", []string{a}, d)
+	nom.Chart = lineChart(
+		weekly(pad(daily[a], days)), weekly(pad(daily[b], days)),
+	)
+	return nom, true
+}
+
+func pad(s []float64, n int) []float64 {
+	for len(s) < n {
+		s = append(s, 0)
+	}
+	return s
+}
+
+func pearson(x, y []float64) float64 {
+	n := float64(len(x))
+	var sx, sy float64
+	for i := range x {
+		sx += x[i]
+		sy += y[i]
+	}
+	mx, my := sx/n, sy/n
+	var cov, vx, vy float64
+	for i := range x {
+		cov += (x[i] - mx) * (y[i] - my)
+		vx += (x[i] - mx) * (x[i] - mx)
+		vy += (y[i] - my) * (y[i] - my)
+	}
+	if vx == 0 || vy == 0 {
+		return math.NaN()
+	}
+	return cov / math.Sqrt(vx*vy)
+}
+
+// weekly складывает дни по неделям, чтобы график читался
+func weekly(daily []float64) []float64 {
+	var weeks []float64
+	for i, v := range daily {
+		if i%7 == 0 {
+			weeks = append(weeks, 0)
+		}
+		weeks[len(weeks)-1] += v
+	}
+	return weeks
+}
+
+var chartColors = []string{"#ff4c6b", "#6bf2ff"}
+
+// lineChart рисует ряды одним SVG, каждый в своём масштабе; как и аватарки,
+// отдаётся data URL, чтобы страница оставалась одним файлом
+func lineChart(series ...[]float64) string {
+	const w, h = 400, 120
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, w, h, w, h)
+	for i, s := range series {
+		top := 0.0
+		for _, v := range s {
+			top = max(top, v)
+		}
+		if top == 0 || len(s) < 2 {
+			continue
+		}
+		points := make([]string, len(s))
+		for j, v := range s {
+			x := float64(j) * w / float64(len(s)-1)
+			y := h - 4 - v/top*(h-8)
+			points[j] = fmt.Sprintf("%.1f,%.1f", x, y)
+		}
+		fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="3" stroke-linejoin="round" points="%s"/>`,
+			chartColors[i%len(chartColors)], strings.Join(points, " "))
+	}
+	b.WriteString(`</svg>`)
+	return "data:image/svg+xml;utf8," + url.PathEscape(b.String())
+}
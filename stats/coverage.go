@@ -0,0 +1,85 @@
+package stats
+
+import (
+	"math"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// Coverage — сколько дней года заняты: «312 из 365 дней (85%)». Общий
+// расчёт для номинаций по дням, чтобы везде были одни и те же числа.
+type Coverage struct {
+	Days  int // дней с сообщениями
+	Total int // дней в году
+
+	lang string // язык String
+}
+
+// Percent — доля дней в процентах, с округлением
+func (c Coverage) Percent() int {
+	if c.Total == 0 {
+		return 0
+	}
+	return int(math.Round(float64(c.Days) * 100 / float64(c.Total)))
+}
+
+func (c Coverage) String() string {
+	return textIn(c.lang, "coverage", c)
+}
+
+// YearCoverage — days дней из всех дней года year, подпись на языке l
+func YearCoverage(l string, days, year int) Coverage {
+	start := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	total := int(start.AddDate(1, 0, 0).Sub(start) / (24 * time.Hour))
+	return Coverage{Days: days, Total: total, lang: l}
+}
+
+// ActiveDays — число разных дней с сообщениями по ключу label
+func ActiveDays(msg []telegram.Message, label func(telegram.Message) string) map[string]int {
+	days := map[string]map[string]bool{}
+	for _, m := range msg {
+		key := label(m)
+		if days[key] == nil {
+			days[key] = map[string]bool{}
+		}
+		days[key][m.Date.Format("2006-01-02")] = true
+	}
+	cnt := map[string]int{}
+	for key, d := range days {
+		cnt[key] = len(d)
+	}
+	return cnt
+}
+
+// DetectYear — последний полный год в данных: год последнего сообщения,
+// если оно написано в декабре, а иначе (год ещё идёт) — предыдущий, если
+// за него есть сообщения
+func DetectYear(msg []telegram.Message) int {
+	if len(msg) == 0 {
+		return time.Now().Year()
+	}
+	last := msg[0].Date
+	for _, m := range msg {
+		if m.Date.After(last) {
+			last = m.Date
+		}
+	}
+	if last.Month() == time.December {
+		return last.Year()
+	}
+	for _, m := range msg {
+		if m.Date.Year() == last.Year()-1 {
+			return last.Year() - 1
+		}
+	}
+	return last.Year()
+}
+
+// yearOf — год сообщений; страница всегда собирается по одному году
+func yearOf(msg []telegram.Message) int {
+	if len(msg) == 0 {
+		return time.Now().Year()
+	}
+	return msg[0].Date.Year()
+}
@@ -0,0 +1,218 @@
+package stats
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// CustomNomination — номинация из конфига: какие сообщения считать, как
+// сложить их по участникам и кто побеждает. Заголовок, подзаголовок и подпись —
+// шаблоны с теми же полями, что у подписей, плюс .Value.
+type CustomNomination struct {
+	Name      string       `yaml:"name"`                // имя для -only, order и nominations
+	Title     string       `yaml:"title"`               // заголовок карточки
+	Subtitle  string       `yaml:"subtitle,omitempty"`  // по умолчанию само число
+	Caption   string       `yaml:"caption,omitempty"`   // подпись
+	Filter    CustomFilter `yaml:"filter,omitempty"`    // какие сообщения считать; пусто — все
+	Aggregate string       `yaml:"aggregate,omitempty"` // count (по умолчанию), length — сумма длины текста, days — разные дни
+	Direction string       `yaml:"direction,omitempty"` // max (по умолчанию) или min
+}
+
+// CustomFilter — условия на сообщение, все должны выполниться
+type CustomFilter struct {
+	MediaType string `yaml:"media_type,omitempty"` // voice_message, sticker, photo, …
+	Text      string `yaml:"text,omitempty"`       // регулярное выражение Go по тексту
+	Reaction  string `yaml:"reaction,omitempty"`   // на сообщении есть реакция этим эмодзи
+}
+
+type customNominator struct {
+	name    string
+	filters []func(telegram.Message) bool
+	findMax bool
+
+	title, subtitle, caption *template.Template
+
+	// для Method: условия и что складываем (count, length, days)
+	filter    CustomFilter
+	aggregate string
+}
+
+// customData — поля шаблонов своей номинации
+type customData struct {
+	captionData
+	Value    int      // число победителя
+	Coverage Coverage // для aggregate: days — Value из дней года: {{.Coverage}}, {{.Coverage.Percent}}
+}
+
+// CompileCustom проверяет номинацию из конфига и делает из неё Nominator.
+// filter.text ищется по тексту после цепочки нормализации normalize, как в
+// Options.Normalize, и сам шаблон подстраивается под неё.
+func CompileCustom(c CustomNomination, normalize []string) (Nominator, error) {
+	if c.Name == "" {
+		return nil, fmt.Errorf("custom nomination needs a name")
+	}
+	if c.Title == "" {
+		return nil, fmt.Errorf("custom nomination %s: needs a title", c.Name)
+	}
+	n := &customNominator{name: c.Name, filter: c.Filter}
+
+	if c.Filter.MediaType != "" {
+		mediaType := c.Filter.MediaType
+		n.filters = append(n.filters, func(m telegram.Message) bool {
+			// у фото в экспорте Telegram нет media_type, только photo
+			return m.MediaType == mediaType || (mediaType == "photo" && m.Photo != "")
+		})
+	}
+	if c.Filter.Text != "" {
+		norm, err := newNormalizer(normalize)
+		if err != nil {
+			return nil, fmt.Errorf("custom nomination %s: normalize: %w", c.Name, err)
+		}
+		re, err := regexp.Compile(norm.pattern(c.Filter.Text))
+		if err != nil {
+			return nil, fmt.Errorf("custom nomination %s: filter text: %w", c.Name, err)
+		}
+		n.filters = append(n.filters, func(m telegram.Message) bool { return re.MatchString(norm.text(m.Text)) })
+	}
+	if c.Filter.Reaction != "" {
+		emoji := c.Filter.Reaction
+		n.filters = append(n.filters, func(m telegram.Message) bool {
+			for _, r := range m.Reactions {
+				if r.Emoji == emoji {
+					return true
+				}
+			}
+			return false
+		})
+	}
+
+	switch n.aggregate = strings.ToLower(c.Aggregate); n.aggregate {
+	case "", "count":
+		n.aggregate = "count"
+	case "length", "days":
+	default:
+		return nil, fmt.Errorf("custom nomination %s: unknown aggregate %q, want count, length or days", c.Name, c.Aggregate)
+	}
+
+	switch strings.ToLower(c.Direction) {
+	case "", "max":
+		n.findMax = true
+	case "min":
+	default:
+		return nil, fmt.Errorf("custom nomination %s: unknown direction %q, want max or min", c.Name, c.Direction)
+	}
+
+	subtitle := c.Subtitle
+	if subtitle == "" {
+		subtitle = "{{.Value}}"
+	}
+	var err error
+	for _, t := range []struct {
+		dst  **template.Template
+		text string
+	}{{&n.title, c.Title}, {&n.subtitle, subtitle}, {&n.caption, c.Caption}} {
+		if *t.dst, err = template.New(c.Name).Parse(t.text); err != nil {
+			return nil, fmt.Errorf("custom nomination %s: %w", c.Name, err)
+		}
+	}
+	return n, nil
+}
+
+func (n *customNominator) Name() string { return n.name }
+
+func (n *customNominator) Method(s *Settings) string {
+	direction := "min"
+	if n.findMax {
+		direction = "max"
+	}
+	return s.text("custom.method", struct{ Filter, Aggregate, Direction string }{
+		s.describeFilter(n.filter),
+		s.text("custom.aggregate."+n.aggregate, nil),
+		s.text("custom.direction."+direction, nil),
+	})
+}
+
+func (n *customNominator) Compute(s *Settings, msg []telegram.Message) (Nomination, bool) {
+	return feed(n.NewAccumulator(s), msg)
+}
+
+func (n *customNominator) NewAccumulator(s *Settings) Accumulator {
+	return &customAcc{n: n, s: s, values: map[string]int{}, days: map[string]map[string]bool{}}
+}
+
+// customAcc складывает подходящие сообщения по участникам, как велит aggregate
+type customAcc struct {
+	n      *customNominator
+	s      *Settings
+	values map[string]int
+	days   map[string]map[string]bool // для aggregate: days — дни по участникам
+	year   int
+}
+
+func (a *customAcc) Add(m telegram.Message) {
+	if a.year == 0 {
+		a.year = m.Date.Year()
+	}
+	if !a.n.findMax && FilterUser(m) {
+		// для min участвуют и те, у кого ни одного подходящего сообщения
+		a.values[m.FromID] += 0
+	}
+	for _, f := range a.n.filters {
+		if !f(m) {
+			return
+		}
+	}
+	switch a.n.aggregate {
+	case "count":
+		a.values[m.FromID]++
+	case "length":
+		a.values[m.FromID] += utf8.RuneCountInString(m.Text)
+	case "days":
+		if a.days[m.FromID] == nil {
+			a.days[m.FromID] = map[string]bool{}
+		}
+		a.days[m.FromID][labelDay(m)] = true
+	}
+}
+
+func (a *customAcc) Result() (Nomination, bool) {
+	n, s, values := a.n, a.s, a.values
+	for id, d := range a.days {
+		values[id] = len(d)
+	}
+	delete(values, "")
+	if a.year == 0 {
+		a.year = yearOf(nil)
+	}
+
+	board := Leaderboard(s, values, n.findMax)
+	users, value := winners(board)
+	if len(users) == 0 {
+		return Nomination{}, false
+	}
+
+	nom := Nomination{Avatar: s.userAvatar(users[0]), Winner: users[0], board: board}
+	if len(users) > 1 {
+		nom.Winners = users
+	}
+	data := customData{captionData: s.newCaptionData(nom), Value: value, Coverage: YearCoverage(s.lang, value, a.year)}
+	nom.Title = execTemplate(n.title, data, n.name)
+	nom.Subtitle = execTemplate(n.subtitle, data, strconv.Itoa(value))
+	nom.Caption = execTemplate(n.caption, data, "")
+	return nom, true
+}
+
+// execTemplate выполняет шаблон; при ошибке — fallback
+func execTemplate(t *template.Template, data any, fallback string) string {
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return fallback
+	}
+	return b.String()
+}
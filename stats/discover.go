@@ -0,0 +1,250 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// Поиск необычных фактов: для каждой метрики из discoveryMetrics считается,
+// насколько участник или месяц выбивается из остальных (z-оценка), и самые
+// необычные факты становятся карточками, которые никто не писал руками.
+
+// metric — кандидат в факт: value даёт вклад одного сообщения. Заголовки
+// карточек про участника и про месяц и что считаем — в каталоге,
+// discover.<name>.user, .month и .noun.
+type metric struct {
+	name  string
+	value func(s *Settings, m telegram.Message) float64
+}
+
+func is(ok bool) float64 {
+	if ok {
+		return 1
+	}
+	return 0
+}
+
+var discoveryMetrics = []metric{
+	{"voice", func(s *Settings, m telegram.Message) float64 { return is(m.MediaType == "voice_message") }},
+	{"video", func(s *Settings, m telegram.Message) float64 { return is(m.MediaType == "video_message") }},
+	{"sticker", func(s *Settings, m telegram.Message) float64 { return is(m.MediaType == "sticker") }},
+	{"photo", func(s *Settings, m telegram.Message) float64 { return is(m.Photo != "") }},
+	{"forward", func(s *Settings, m telegram.Message) float64 { return is(m.ForwardedFrom != "") }},
+	{"links", func(s *Settings, m telegram.Message) float64 { return is(s.textContains(m, "http")) }},
+	{"questions", func(s *Settings, m telegram.Message) float64 { return is(strings.Contains(m.Text, "?")) }},
+	{"night", func(s *Settings, m telegram.Message) float64 { return is(m.Date.Hour() < 5) }},
+	{"weekend", func(s *Settings, m telegram.Message) float64 {
+		return is(m.Date.Weekday() == time.Saturday || m.Date.Weekday() == time.Sunday)
+	}},
+	{"caps", func(s *Settings, m telegram.Message) float64 { return is(isCaps(m.Text)) }},
+	{"laugh", func(s *Settings, m telegram.Message) float64 { return is(isLaugh(s.NormalizeText(m.Text))) }},
+	{"emoji", func(s *Settings, m telegram.Message) float64 { return is(countEmoji(m.Text) > 0) }},
+	{"reactions", func(s *Settings, m telegram.Message) float64 {
+		total := 0
+		for _, r := range m.Reactions {
+			total += r.Count
+		}
+		return float64(total)
+	}},
+}
+
+func isCaps(s string) bool {
+	letters, upper := 0, 0
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	return letters >= 5 && upper == letters
+}
+
+func isLaugh(s string) bool {
+	s = strings.ToLower(s)
+	return strings.Contains(s, "хах") || strings.Contains(s, "ахах") ||
+		strings.Contains(s, "))") || strings.Contains(s, "lol")
+}
+
+type fact struct {
+	metric string
+	score  float64 // z-оценка
+	nom    Nomination
+}
+
+// discover находит самые необычные факты о чате, по одному на метрику,
+// от самого необычного
+func (s *Settings) discover(msg []telegram.Message) []fact {
+	var facts []fact
+	for _, mt := range discoveryMetrics {
+		best := fact{score: s.limits.DiscoverMinZ}
+		for _, f := range append(s.userFacts(msg, mt), s.monthFacts(msg, mt)...) {
+			if f.score > best.score {
+				best = f
+			}
+		}
+		if best.metric != "" {
+			facts = append(facts, best)
+		}
+	}
+	sort.SliceStable(facts, func(i, j int) bool { return facts[i].score > facts[j].score })
+	return facts
+}
+
+// userFacts сравнивает долю сообщений участника с метрикой с долей у остальных
+func (s *Settings) userFacts(msg []telegram.Message, mt metric) []fact {
+	sum := map[string]float64{}
+	total := map[string]int{}
+	for _, m := range msg {
+		if !FilterUser(m) {
+			continue
+		}
+		sum[m.FromID] += mt.value(s, m)
+		total[m.FromID]++
+	}
+
+	var ids []string
+	var rates []float64
+	for id, n := range total {
+		if n >= s.limits.DiscoverMinMessages {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		rates = append(rates, sum[id]/float64(total[id]))
+	}
+	mean, std := meanStd(rates)
+	if len(rates) < 3 || std == 0 {
+		return nil
+	}
+
+	var facts []fact
+	for i, id := range ids {
+		r := rates[i]
+		if s.optedOut(id) {
+			continue
+		}
+		others := (mean*float64(len(rates)) - r) / float64(len(rates)-1)
+		if others <= 0 {
+			continue
+		}
+		d := s.newTextData(id)
+		d.Percent, d.Rate, d.Times = int(math.Round(r*100)), r, r/others
+		d.Value = s.text("discover."+mt.name+".noun", nil)
+		subtitle := s.text("discover.userSubtitle", d)
+		if mt.name == "reactions" {
+			subtitle = s.text("discover.userSubtitleRate", d)
+		}
+		facts = append(facts, fact{
+			metric: mt.name,
+			score:  (r - mean) / std,
+			nom: Nomination{
+				Title:    s.text("discover."+mt.name+".user", d),
+				Subtitle: subtitle,
+				Caption:  s.text("discover.userCaption", d),
+				Avatar:   s.userAvatar(id),
+				Winner:   id,
+			},
+		})
+	}
+	return facts
+}
+
+// monthFacts сравнивает долю сообщений с метрикой в месяце с остальными
+// месяцами года, чтобы самый болтливый месяц не выигрывал во всём
+func (s *Settings) monthFacts(msg []telegram.Message, mt metric) []fact {
+	sums := map[time.Month]float64{}
+	total := map[time.Month]int{}
+	for _, m := range msg {
+		sums[m.Date.Month()] += mt.value(s, m)
+		total[m.Date.Month()]++
+	}
+	var months []time.Month
+	var values []float64
+	for month := time.January; month <= time.December; month++ {
+		if total[month] >= s.limits.DiscoverMinMessages {
+			months = append(months, month)
+			values = append(values, sums[month]/float64(total[month]))
+		}
+	}
+	mean, std := meanStd(values)
+	if len(values) < 4 || std == 0 {
+		return nil
+	}
+
+	var facts []fact
+	for i, month := range months {
+		v := values[i]
+		others := (mean*float64(len(values)) - v) / float64(len(values)-1)
+		if others <= 0 {
+			continue
+		}
+		d := textData{Count: int(math.Round(sums[month])), Times: v / others}
+		d.Value = s.text("discover."+mt.name+".noun", nil)
+		facts = append(facts, fact{
+			metric: mt.name,
+			score:  (v - mean) / std,
+			nom: Nomination{
+				Title:    s.text("discover."+mt.name+".month", d),
+				Subtitle: s.monthName(month),
+				Caption:  s.text("discover.monthCaption", d),
+				Avatar:   s.avatars.Common(),
+			},
+		})
+	}
+	return facts
+}
+
+func meanStd(values []float64) (mean, std float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	for _, v := range values {
+		std += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(std / float64(len(values)))
+}
+
+// discoveryNominator — rank-й по необычности факт о чате
+type discoveryNominator struct {
+	rank int
+}
+
+func (d discoveryNominator) Name() string { return fmt.Sprintf("discovered%d", d.rank+1) }
+
+func (d discoveryNominator) Method(s *Settings) string {
+	return s.text("nominations.discovered.method", struct {
+		MinMessages, Rank int
+		MinZ              float64
+	}{s.limits.DiscoverMinMessages, d.rank + 1, s.limits.DiscoverMinZ})
+}
+
+func (d discoveryNominator) Compute(s *Settings, msg []telegram.Message) (Nomination, bool) {
+	facts := s.discover(msg)
+	if d.rank >= len(facts) {
+		return Nomination{}, false
+	}
+	return facts[d.rank].nom, true
+}
+
+// DiscoveryNominators — k номинаций discovered1…discoveredK с самыми
+// необычными фактами о чате; фактов может найтись и меньше
+func DiscoveryNominators(k int) []Nominator {
+	list := make([]Nominator, k)
+	for i := range list {
+		list[i] = discoveryNominator{rank: i}
+	}
+	return list
+}
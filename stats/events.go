@@ -0,0 +1,213 @@
+package stats
+
+import (
+	"context"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// Шина событий: проход по сообщениям разбирает каждое сообщение один раз
+// на события — текст, медиа, каждая реакция, service-действие — и раздаёт
+// их подписчикам. Номинации-коллекторы подписываются только на то, что им
+// нужно, и не перебирают сами реакции или service-сообщения. Накопители
+// (Accumulator) — те же подписчики на MessageEvent.
+
+// EventKind — вид события на шине
+type EventKind int
+
+const (
+	MessageEvent  EventKind = iota // любое обычное сообщение
+	TextMessage                    // сообщение с текстом
+	MediaMessage                   // фото, видео, голосовое, стикер, файл
+	Reaction                       // одна реакция под сообщением, в BusEvent.Reaction
+	ServiceAction                  // service-сообщение: бусты, вступления, см. NewSettings
+
+	eventKinds
+)
+
+// BusEvent — одно событие шины (Event — событие хроники); Message не меняют, он общий для всех подписчиков
+type BusEvent struct {
+	Kind     EventKind
+	Message  *telegram.Message
+	Reaction *telegram.Reaction // только у Reaction
+}
+
+// Bus раздаёт события подписчикам в порядке подписки. Не для нескольких
+// горутин: у каждого прохода своя шина.
+type Bus struct {
+	handlers [eventKinds][]handler
+	owner    int             // чей Subscribe сейчас идёт, для замеров
+	timing   []time.Duration // не nil — мерить время обработчиков по owner
+}
+
+type handler struct {
+	owner int
+	fn    func(BusEvent)
+}
+
+// On подписывает fn на события kind
+func (b *Bus) On(kind EventKind, fn func(BusEvent)) {
+	b.handlers[kind] = append(b.handlers[kind], handler{owner: b.owner, fn: fn})
+}
+
+// has — есть ли подписчики на kind
+func (b *Bus) has(kind EventKind) bool { return len(b.handlers[kind]) > 0 }
+
+// Publish разбирает сообщение на события. Service-сообщение даёт только
+// ServiceAction, обычное — MessageEvent и то, что в нём есть.
+func (b *Bus) Publish(m *telegram.Message) {
+	if m.Type == "service" {
+		b.emit(BusEvent{Kind: ServiceAction, Message: m})
+		return
+	}
+	b.emit(BusEvent{Kind: MessageEvent, Message: m})
+	if m.Text != "" {
+		b.emit(BusEvent{Kind: TextMessage, Message: m})
+	}
+	if m.MediaType != "" || m.Photo != "" {
+		b.emit(BusEvent{Kind: MediaMessage, Message: m})
+	}
+	if b.has(Reaction) {
+		for i := range m.Reactions {
+			b.emit(BusEvent{Kind: Reaction, Message: m, Reaction: &m.Reactions[i]})
+		}
+	}
+}
+
+func (b *Bus) emit(e BusEvent) {
+	for _, h := range b.handlers[e.Kind] {
+		if b.timing != nil {
+			start := time.Now()
+			h.fn(e)
+			b.timing[h.owner] += time.Since(start)
+			continue
+		}
+		h.fn(e)
+	}
+}
+
+// Collector — номинация-подписчик шины: Subscribe вешает обработчики,
+// Result — как у Accumulator
+type Collector interface {
+	Subscribe(b *Bus)
+	Result() (Nomination, bool)
+}
+
+// CollectorNominator — номинация, которую считает Collector. NewCollector
+// может вернуть nil, тогда номинация считается как раньше (Streamer или Compute).
+type CollectorNominator interface {
+	Nominator
+	NewCollector(s *Settings) Collector
+}
+
+// CollectorFunc делает номинацию из конструктора коллектора
+func CollectorFunc(name string, col func(*Settings) Collector) Nominator {
+	return funcNominator{name: name, col: col}
+}
+
+// collectorFunc — коллектор из двух замыканий над общим состоянием, как accFunc
+type collectorFunc struct {
+	subscribe func(b *Bus)
+	result    func() (Nomination, bool)
+}
+
+func (c collectorFunc) Subscribe(b *Bus)           { c.subscribe(b) }
+func (c collectorFunc) Result() (Nomination, bool) { return c.result() }
+
+// accCollector — накопитель на шине: всё сообщение целиком
+type accCollector struct{ Accumulator }
+
+func (a accCollector) Subscribe(b *Bus) {
+	b.On(MessageEvent, func(e BusEvent) { a.Add(*e.Message) })
+}
+
+// collectorOf — коллектор номинации n или nil, если её считает только Compute
+func collectorOf(s *Settings, n Nominator) Collector {
+	if c, ok := n.(CollectorNominator); ok {
+		if col := c.NewCollector(s); col != nil {
+			return col
+		}
+	}
+	if st, ok := n.(Streamer); ok {
+		if acc := st.NewAccumulator(s); acc != nil {
+			return accCollector{acc}
+		}
+	}
+	return nil
+}
+
+// serviceOf — service-сообщения настроек s (см. NewSettings) из чатов msg:
+// в разделе чата номинации видят только его бусты и вступления
+func (s *Settings) serviceOf(msg []telegram.Message) []telegram.Message {
+	if len(s.service) == 0 {
+		return nil
+	}
+	chats := map[string]bool{}
+	for _, m := range msg {
+		chats[m.Chat] = true
+	}
+	var list []telegram.Message
+	for _, m := range s.service {
+		if chats[m.Chat] {
+			list = append(list, m)
+		}
+	}
+	return list
+}
+
+// collect прогоняет msg и его service-сообщения через один коллектор; так
+// Compute считает номинацию-коллектор отдельно от страницы
+func collect(s *Settings, c Collector, msg []telegram.Message) (Nomination, bool) {
+	var b Bus
+	c.Subscribe(&b)
+	for i := range msg {
+		b.Publish(&msg[i])
+	}
+	if b.has(ServiceAction) {
+		sv := s.serviceOf(msg)
+		for i := range sv {
+			b.Publish(&sv[i])
+		}
+	}
+	return c.Result()
+}
+
+// Collect считает номинации list прямо из потока сообщений — например, из
+// telegram.Source.Load, не собирая экспорт в срез. Service-сообщения идут
+// в тот же поток. Номинации, которым нужны все сообщения сразу (без
+// коллектора и накопителя), пропускаются. Карточки — в порядке list.
+func Collect(ctx context.Context, s *Settings, list []Nominator, ch <-chan telegram.Message) ([]Nomination, error) {
+	var b Bus
+	type owned struct {
+		n Nominator
+		c Collector
+	}
+	var cols []owned
+	for _, n := range list {
+		if c := collectorOf(s, n); c != nil {
+			b.owner = len(cols)
+			c.Subscribe(&b)
+			cols = append(cols, owned{n, c})
+		}
+	}
+read:
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case m, ok := <-ch:
+			if !ok {
+				break read
+			}
+			b.Publish(&m)
+		}
+	}
+	var noms []Nomination
+	for _, o := range cols {
+		if nom, ok := o.c.Result(); ok {
+			noms = append(noms, s.finish(o.n, nom))
+		}
+	}
+	return noms, nil
+}
@@ -0,0 +1,137 @@
+package stats
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// Graph — кто кому отвечает и кого упоминает: направленный граф для Gephi
+// или Graphviz (generate -graph)
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode — участник; упомянутый @ник, которого нет среди писавших,
+// тоже узел, с ID и Name — сам @ник
+type GraphNode struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Messages int    `json:"messages"`
+}
+
+// GraphEdge — From отвечал To или упоминал его; Weight — всё вместе
+type GraphEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Replies  int    `json:"replies"`
+	Mentions int    `json:"mentions"`
+	Weight   int    `json:"weight"`
+}
+
+// SocialGraph строит граф ответов и упоминаний по сообщениям msg. Ответ —
+// ребро к автору сообщения, на которое ответили, если оно есть в выгрузке
+// того же чата. Упоминание — к участнику с таким именем или id (@ник, если
+// платформа пишет в упоминании имя, или mention_name) или к отдельному
+// узлу-нику. Себе рёбер нет, отказавшихся от участия в графе нет.
+func SocialGraph(s *Settings, msg []telegram.Message) Graph {
+	type msgKey struct {
+		chat string
+		id   int64
+	}
+	authors := map[msgKey]string{}
+	messages := map[string]int{}
+	byName := map[string]string{}
+	for _, m := range msg {
+		if !FilterUser(m) {
+			continue
+		}
+		authors[msgKey{m.Chat, m.ID}] = m.FromID
+		messages[m.FromID]++
+		if name := s.avatars.Names[m.FromID]; name != "" {
+			byName[name] = m.FromID
+		}
+	}
+
+	edges := map[[2]string]*GraphEdge{}
+	edge := func(from, to string) *GraphEdge {
+		key := [2]string{from, to}
+		if edges[key] == nil {
+			edges[key] = &GraphEdge{From: from, To: to}
+		}
+		return edges[key]
+	}
+	skip := func(from, to string) bool {
+		return to == "" || from == to || s.optedOut(from) || s.optedOut(to)
+	}
+	for _, m := range msg {
+		if !FilterUser(m) {
+			continue
+		}
+		if m.ReplyToMessageID != 0 {
+			if to := authors[msgKey{m.Chat, m.ReplyToMessageID}]; !skip(m.FromID, to) {
+				edge(m.FromID, to).Replies++
+			}
+		}
+		for _, ent := range m.TextEntities {
+			var to string
+			switch ent.Type {
+			case "mention":
+				to = strings.TrimPrefix(ent.Text, "@")
+				if id, ok := byName[to]; ok {
+					to = id
+				} else if _, ok := messages[to]; !ok && to != "" {
+					to = "@" + to // не участник: свой узел
+				}
+			case "mention_name":
+				to = byName[ent.Text]
+			}
+			if !skip(m.FromID, to) {
+				edge(m.FromID, to).Mentions++
+			}
+		}
+	}
+
+	var g Graph
+	seen := map[string]bool{}
+	node := func(id string) {
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		n := GraphNode{ID: id, Name: s.avatars.Names[id], Messages: messages[id]}
+		if n.Name == "" {
+			n.Name = id
+		}
+		g.Nodes = append(g.Nodes, n)
+	}
+	for id := range messages {
+		if !s.optedOut(id) {
+			node(id)
+		}
+	}
+	for _, e := range edges {
+		node(e.To)
+		e.Weight = e.Replies + e.Mentions
+		g.Edges = append(g.Edges, *e)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		if g.Nodes[i].Messages != g.Nodes[j].Messages {
+			return g.Nodes[i].Messages > g.Nodes[j].Messages
+		}
+		return g.Nodes[i].ID < g.Nodes[j].ID
+	})
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.Weight != b.Weight {
+			return a.Weight > b.Weight
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	return g
+}
@@ -0,0 +1,285 @@
+package stats
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Все тексты страницы лежат в каталогах locales/<язык>.yaml, а не в коде
+// номинаций: один и тот же бинарник собирает страницу на любом языке из
+// каталога. Строка каталога — text/template, см. textData.
+
+//go:embed locales/*.yaml
+var localeFiles embed.FS
+
+// DefaultLanguage — язык страницы, если он не задан; на нём же строки,
+// которых нет в каталоге другого языка
+const DefaultLanguage = "ru"
+
+var (
+	catalogMu sync.Mutex
+	catalogs  = map[string]map[string]string{}             // язык → ключ → строка
+	compiled  = map[string]map[string]*template.Template{} // язык → ключ → шаблон
+)
+
+// Languages — языки, для которых есть каталог
+func Languages() []string {
+	files, _ := localeFiles.ReadDir("locales")
+	var list []string
+	for _, f := range files {
+		list = append(list, strings.TrimSuffix(f.Name(), path.Ext(f.Name())))
+	}
+	sort.Strings(list)
+	return list
+}
+
+// ParseLanguage проверяет язык страницы: ru или en, пусто — DefaultLanguage
+func ParseLanguage(l string) (string, error) {
+	l = strings.ToLower(strings.TrimSpace(l))
+	if l == "" {
+		return DefaultLanguage, nil
+	}
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	if _, err := loadCatalog(l); errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("language %q is not supported, known: %s", l, strings.Join(Languages(), ", "))
+	} else if err != nil {
+		return "", fmt.Errorf("language %q: %w", l, err) // каталог есть, но не читается
+	}
+	return l, nil
+}
+
+// loadCatalog читает и раскладывает каталог по ключам вида nominations.maxDay.title
+func loadCatalog(l string) (map[string]string, error) {
+	if c, ok := catalogs[l]; ok {
+		return c, nil
+	}
+	data, err := localeFiles.ReadFile("locales/" + l + ".yaml")
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]any
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("locale %s: %w", l, err)
+	}
+	c := map[string]string{}
+	flatten(c, "", tree)
+	catalogs[l] = c
+	compiled[l] = map[string]*template.Template{}
+	return c, nil
+}
+
+func flatten(dst map[string]string, prefix string, v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, sub := range v {
+			flatten(dst, prefix+k+".", sub)
+		}
+	case []any:
+		for i, sub := range v {
+			flatten(dst, prefix+strconv.Itoa(i)+".", sub)
+		}
+	default:
+		dst[strings.TrimSuffix(prefix, ".")] = fmt.Sprint(v)
+	}
+}
+
+// catalogFuncs — функции строк каталога языка l
+func catalogFuncs(l string) template.FuncMap {
+	return template.FuncMap{
+		"plural": func(n int, forms ...string) string { return Plural(l, n, forms...) },
+		"join":   strings.Join,
+	}
+}
+
+// lookup — шаблон строки key на языке lang, а если там её нет — на русском
+func lookup(lang, key string) (*template.Template, bool) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	for _, l := range []string{lang, DefaultLanguage} {
+		c, err := loadCatalog(l)
+		if err != nil {
+			continue
+		}
+		if t, ok := compiled[l][key]; ok {
+			return t, true
+		}
+		s, ok := c[key]
+		if !ok {
+			continue
+		}
+		t, err := template.New(key).Funcs(catalogFuncs(l)).Parse(s)
+		if err != nil {
+			continue // кривая строка в каталоге — как будто её нет
+		}
+		compiled[l][key] = t
+		return t, true
+	}
+	return nil, false
+}
+
+// textIn — строка каталога key на языке l, выполненная над data; если
+// строки нет, возвращается сам ключ, чтобы пропуск был виден на странице
+func textIn(l, key string, data any) string {
+	t, ok := lookup(l, key)
+	if !ok {
+		return key
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return key
+	}
+	return b.String()
+}
+
+// text — textIn на языке страницы
+func (s *Settings) text(key string, data any) string { return textIn(s.lang, key, data) }
+
+// hasTextIn — есть ли строка key в каталоге языка l
+func hasTextIn(l, key string) bool {
+	_, ok := lookup(l, key)
+	return ok
+}
+
+// hasText — hasTextIn на языке страницы
+func (s *Settings) hasText(key string) bool { return hasTextIn(s.lang, key) }
+
+// Number разбивает число на разряды по правилам языка l: неразрывный
+// пробел в русском, запятая в английском
+func Number(l string, n int) string {
+	sep := "\u00a0"
+	if l == "en" {
+		sep = ","
+	}
+	s := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, s = "-", s[1:]
+	}
+	if len(s) <= 3 || len(s) == 4 && sep != "," {
+		// по-русски четырёхзначные не разбивают: 2025, а не 2 025
+		return sign + s
+	}
+	var b strings.Builder
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(c)
+	}
+	return sign + b.String()
+}
+
+// Plural выбирает форму слова для n по правилам языка l: в русском
+// три формы (день, дня, дней), в английском две:
+// Plural("ru", 3, "день", "дня", "дней")
+func Plural(l string, n int, forms ...string) string {
+	if len(forms) == 0 {
+		return ""
+	}
+	i := 0
+	switch l {
+	case "ru":
+		n %= 100
+		switch {
+		case n >= 11 && n <= 14:
+			i = 2
+		case n%10 == 1:
+			i = 0
+		case n%10 >= 2 && n%10 <= 4:
+			i = 1
+		default:
+			i = 2
+		}
+	default:
+		if n != 1 {
+			i = 1
+		}
+	}
+	return forms[min(i, len(forms)-1)]
+}
+
+// textData — поля строк каталога про карточку: подписи победителя
+// (captionData) и числа, которые на ней показываются
+type textData struct {
+	captionData
+	Count   int      // главное число: сообщений, дней, реакций
+	Total   int      // из скольких
+	Percent int      // доля в процентах
+	Rate    float64  // дробное число: реакций на сообщение, корреляция
+	Times   float64  // во сколько раз больше обычного
+	Value   string   // эмодзи, название, длительность
+	Date    string   // день, уже подписанный на языке страницы
+	Names   []string // несколько участников
+}
+
+// newTextData — поля для карточки про участника winner (может быть пусто)
+func (s *Settings) newTextData(winner string) textData {
+	return textData{captionData: s.newCaptionData(Nomination{Winner: winner})}
+}
+
+// card — заголовок, число и подпись номинации name из каталога
+func (s *Settings) card(name string, d textData) Nomination {
+	key := "nominations." + name + "."
+	return Nomination{
+		Title:    s.text(key+"title", d),
+		Subtitle: s.text(key+"subtitle", d),
+		Caption:  s.text(key+"caption", d),
+	}
+}
+
+// Labels — подписи самого шаблона (кнопки, заголовки слайдов) на языке lang
+func Labels(lang string) map[string]string {
+	labels := map[string]string{}
+	for _, l := range []string{DefaultLanguage, lang} {
+		catalogMu.Lock()
+		c, _ := loadCatalog(l)
+		catalogMu.Unlock()
+		for k, v := range c {
+			if name, ok := strings.CutPrefix(k, "labels."); ok {
+				labels[name] = v
+			}
+		}
+	}
+	return labels
+}
+
+// joinNames — «Саша», «Саша и Лена», «Саша, Лена и Петя»
+func (s *Settings) joinNames(names []string) string {
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + s.text("and", nil) + names[len(names)-1]
+}
+
+func (s *Settings) monthName(m time.Month) string {
+	return s.text("months."+strconv.Itoa(int(m)-1), nil)
+}
+
+// dayLabel — «14 февраля» / «February 14»
+func (s *Settings) dayLabel(t time.Time) string {
+	return s.text("date.day", struct {
+		Day   int
+		Month string
+	}{t.Day(), s.text("monthsGenitive."+strconv.Itoa(int(t.Month())-1), nil)})
+}
+
+// weekdayLabel — «пятница, 14 февраля»
+func (s *Settings) weekdayLabel(t time.Time) string {
+	return s.text("date.weekday", struct {
+		Day     int
+		Month   string
+		Weekday string
+	}{t.Day(), s.text("monthsGenitive."+strconv.Itoa(int(t.Month())-1), nil), s.text("weekdays."+strconv.Itoa(int(t.Weekday())), nil)})
+}
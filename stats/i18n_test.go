@@ -0,0 +1,25 @@
+package stats
+
+import (
+	"testing"
+	"text/template"
+)
+
+// Каталог, который не разбирается, молча выключает язык целиком, поэтому
+// каждый из locales/*.yaml должен читаться, а его строки — быть шаблонами.
+func TestCatalogsLoad(t *testing.T) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	for _, l := range Languages() {
+		c, err := loadCatalog(l)
+		if err != nil {
+			t.Errorf("loadCatalog(%q): %v", l, err)
+			continue
+		}
+		for key, s := range c {
+			if _, err := template.New(key).Funcs(catalogFuncs(l)).Parse(s); err != nil {
+				t.Errorf("%s: %s: %v", l, key, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// Place — место в таблице лидеров номинации
+type Place struct {
+	Rank    int    `json:"rank"` // 1 — победитель; равные значения делят место
+	ID      string `json:"id"`   // from_id или другой ключ: день, эмодзи, чат
+	Name    string `json:"name,omitempty"`
+	Avatar  string `json:"avatar,omitempty"`
+	Value   int    `json:"value"`
+	Label   string `json:"label"`   // значение, как его показывать: «42», «2 ч 39 мин»
+	Percent int    `json:"percent"` // доля от суммы значений всех участников
+}
+
+// Leaderboard раскладывает counts по местам: по убыванию (findMax) или по
+// возрастанию, при равенстве — по ключу, чтобы порядок не зависел от обхода
+// map. Отказавшиеся от участия и сообщения от имени чата мест не получают,
+// но в сумму для процентов входят.
+func Leaderboard(s *Settings, counts map[string]int, findMax bool) []Place {
+	total := 0
+	var board []Place
+	for key, v := range counts {
+		total += v
+		if s.optedOut(key) || key == telegram.ChatSenderID {
+			continue
+		}
+		board = append(board, Place{ID: key, Value: v, Label: strconv.Itoa(v)})
+	}
+	sort.Slice(board, func(i, j int) bool {
+		if board[i].Value != board[j].Value {
+			return (board[i].Value > board[j].Value) == findMax
+		}
+		return board[i].ID < board[j].ID
+	})
+	for i := range board {
+		board[i].Rank = i + 1
+		if i > 0 && board[i].Value == board[i-1].Value {
+			board[i].Rank = board[i-1].Rank
+		}
+		if total != 0 {
+			board[i].Percent = int(math.Round(float64(board[i].Value) * 100 / float64(total)))
+		}
+	}
+	return board
+}
+
+// winners — все, кто делит первое место, и их значение
+func winners(board []Place) ([]string, int) {
+	var ids []string
+	for _, p := range board {
+		if p.Rank != 1 {
+			break
+		}
+		ids = append(ids, p.ID)
+	}
+	if len(ids) == 0 {
+		return nil, 0
+	}
+	return ids, board[0].Value
+}
+
+// boardCard — userCard по таблице лидеров; таблица остаётся в карточке
+// для пьедестала
+func (s *Settings) boardCard(name string, board []Place) Nomination {
+	users, cnt := winners(board)
+	nom := s.userCard(name, users, cnt)
+	nom.board = board
+	return nom
+}
+
+// podium — первые n мест таблицы с именами и аватарками; пусто, если
+// показывать нечего, кроме самого победителя
+func (s *Settings) podium(name string, board []Place) []Place {
+	n, ok := s.podiumSize[strings.ToLower(name)]
+	if !ok {
+		n = s.podiumAll
+	}
+	if n < 2 || len(board) < 2 {
+		return nil
+	}
+	res := make([]Place, min(n, len(board)))
+	for i := range res {
+		p := board[i]
+		p.Name = s.avatars.Names[p.ID]
+		p.Avatar = s.userAvatar(p.ID)
+		res[i] = p
+	}
+	return res
+}
+
+// TopUsers — общий рейтинг: первые n участников по числу сообщений, с
+// местом, аватаркой и долей от всех сообщений, как в «Самом активном».
+// Равные делят место.
+func TopUsers(s *Settings, msg []telegram.Message, n int) []Place {
+	counts := map[string]int{}
+	for _, m := range msg {
+		counts[LabelID(m)]++
+	}
+	board := Leaderboard(s, counts, true)
+	board = board[:min(max(n, 0), len(board))]
+	for i := range board {
+		board[i].Name = s.avatars.Names[board[i].ID]
+		board[i].Avatar = s.userAvatar(board[i].ID)
+		board[i].Label = Number(s.lang, board[i].Value)
+	}
+	return board
+}
@@ -0,0 +1,137 @@
+package stats
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"net/url"
+	"strings"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// mediaColors — цвета долей пончика, по порядку MediaCounts.kinds
+var mediaColors = []string{"#ff4c6b", "#6bf2ff", "#ffe066", "#9b7bff", "#7bffb0", "#ffa64c"}
+
+// MediaCounts — сколько сообщений с вложением каждого вида
+type MediaCounts struct {
+	Photos   int `json:"photos"`
+	Stickers int `json:"stickers"`
+	Voice    int `json:"voice"`  // голосовые
+	Videos   int `json:"videos"` // кружочки
+	Files    int `json:"files"`  // документы, музыка и видео файлом
+	GIFs     int `json:"gifs"`
+}
+
+// add считает вложение m, если оно есть
+func (c *MediaCounts) add(m telegram.Message) {
+	switch {
+	case m.Photo != "":
+		c.Photos++
+	case m.MediaType == "sticker":
+		c.Stickers++
+	case m.MediaType == "voice_message":
+		c.Voice++
+	case filterVideo(m):
+		c.Videos++
+	case filterGIF(m):
+		c.GIFs++
+	case filterFile(m):
+		c.Files++
+	}
+}
+
+// kinds — виды по порядку легенды; ключи — подписи в каталоге,
+// nominations.mediaMix.<ключ>
+func (c MediaCounts) kinds() []struct {
+	key   string
+	count int
+} {
+	return []struct {
+		key   string
+		count int
+	}{
+		{"photos", c.Photos}, {"stickers", c.Stickers}, {"voice", c.Voice},
+		{"videos", c.Videos}, {"files", c.Files}, {"gifs", c.GIFs},
+	}
+}
+
+// filterFile — документ или файл: у других платформ media_type file, у
+// Telegram — видео и музыка файлом, а документ без media_type узнаётся по
+// размеру вложения без фото
+func filterFile(m telegram.Message) bool {
+	switch m.MediaType {
+	case "file", "video_file", "audio_file":
+		return true
+	case "":
+		return m.Photo == "" && m.FileSize > 0
+	}
+	return false
+}
+
+// mediaMix — «Чем делились»: вложения по видам пончиком, главное — самый
+// частый вид
+func mediaMix(s *Settings) Accumulator {
+	var counts MediaCounts
+	return accFunc{
+		add: func(m telegram.Message) {
+			if FilterUser(m) {
+				counts.add(m)
+			}
+		},
+		result: func() (Nomination, bool) {
+			kinds := counts.kinds()
+			top, total := 0, 0
+			for i, k := range kinds {
+				if k.count > kinds[top].count {
+					top = i
+				}
+				total += k.count
+			}
+			if total == 0 {
+				return Nomination{}, false
+			}
+			d := s.newTextData("")
+			d.Count, d.Total = kinds[top].count, total
+			d.Percent = int(math.Round(float64(kinds[top].count) * 100 / float64(total)))
+			d.Value = s.text("nominations.mediaMix."+kinds[top].key, nil)
+			nom := s.card("mediaMix", d)
+			nom.Avatar = s.avatars.Common()
+			nom.Chart = s.donutChart(counts)
+			return nom, true
+		},
+	}
+}
+
+// donutChart рисует доли видов вложений пончиком с легендой справа; у
+// долей подсказка с числом
+func (s *Settings) donutChart(c MediaCounts) string {
+	const w, h, cx, cy, r, width = 480, 200, 100, 100, 70, 34
+	kinds := c.kinds()
+	total := 0
+	for _, k := range kinds {
+		total += k.count
+	}
+	circle := 2 * math.Pi * r
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="14" fill="#9aa4c8">`, w, h, w, h)
+	// доли — дуги одной окружности со сдвигом штриха, от двенадцати часов
+	offset, row := 0.0, 0
+	for i, k := range kinds {
+		if k.count == 0 {
+			continue
+		}
+		label := html.EscapeString(s.text("nominations.mediaMix."+k.key, nil))
+		arc := float64(k.count) / float64(total) * circle
+		fmt.Fprintf(&b, `<circle cx="%d" cy="%d" r="%d" fill="none" stroke="%s" stroke-width="%d" stroke-dasharray="%.2f %.2f" stroke-dashoffset="%.2f" transform="rotate(-90 %d %d)"><title>%s: %d</title></circle>`,
+			cx, cy, r, mediaColors[i], width, arc, circle-arc, -offset, cx, cy, label, k.count)
+		offset += arc
+		y := 40 + row*26
+		fmt.Fprintf(&b, `<rect x="220" y="%d" width="14" height="14" rx="3" fill="%s"/>`, y-12, mediaColors[i])
+		fmt.Fprintf(&b, `<text x="244" y="%d">%s</text><text x="%d" y="%d" text-anchor="end">%d · %d%%</text>`,
+			y, label, w, y, k.count, int(math.Round(float64(k.count)*100/float64(total))))
+		row++
+	}
+	b.WriteString(`</svg>`)
+	return "data:image/svg+xml;utf8," + url.PathEscape(b.String())
+}
@@ -0,0 +1,137 @@
+package stats
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// MemberLink — участник в списке на главной странице сайта (команда site)
+type MemberLink struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Avatar string `json:"avatar,omitempty"`
+	URL    string `json:"url"` // страница участника относительно главной
+}
+
+// MemberPage — личная страница участника: его числа, его номинации и
+// сообщение, собравшее больше всех реакций
+type MemberPage struct {
+	ID     string       `json:"id"`
+	Name   string       `json:"name"`
+	Avatar string       `json:"avatar,omitempty"`
+	Title  string       `json:"title"` // «Саша — итоги 2025»
+	Stats  []MemberStat `json:"stats"`
+	Awards []Nomination `json:"awards,omitempty"` // номинации, где он победил, в порядке страницы
+	Top    *TopMessage  `json:"top,omitempty"`
+	Index  string       `json:"index"`           // ссылка назад на главную, ставит render.WriteSite
+	Theme  *Theme       `json:"theme,omitempty"` // палитра главной, ставит render.WriteSite
+
+	Lang   string            `json:"lang"`
+	Labels map[string]string `json:"labels"`
+}
+
+// MemberStat — одно число на странице участника
+type MemberStat struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// TopMessage — сообщение участника с наибольшим числом реакций
+type TopMessage struct {
+	Text      string    `json:"text"`
+	Date      time.Time `json:"date"`
+	Day       string    `json:"day"` // «14 февраля»
+	Reactions int       `json:"reactions"`
+}
+
+// MemberPages — страницы всех участников msg по убыванию числа сообщений.
+// Номинации берутся из page, включая разделы чатов. Отказавшиеся от
+// участия страниц не получают; с Options.Minimal текст сообщения скрыт.
+func MemberPages(s *Settings, page PageData, msg []telegram.Message) []MemberPage {
+	agg := ComputeAggregates(s, msg)
+	top := topMessages(msg)
+	year := yearOf(msg)
+
+	var all []Nomination
+	all = append(all, page.Nominations...)
+	for _, sec := range page.Sections {
+		all = append(all, sec.Nominations...)
+	}
+
+	pages := make([]MemberPage, 0, len(agg.Users))
+	for _, u := range agg.Users {
+		name := u.Name
+		if name == "" {
+			name = u.ID
+		}
+		title := s.text("member.title", struct {
+			Name string
+			Year int
+		}{name, year})
+		p := MemberPage{
+			ID:     u.ID,
+			Name:   name,
+			Avatar: s.userAvatar(u.ID),
+			Title:  title,
+			Lang:   s.lang,
+			Labels: Labels(s.lang),
+		}
+		for _, c := range userColumns {
+			if c.key == "id" || c.key == "name" {
+				continue
+			}
+			p.Stats = append(p.Stats, MemberStat{Label: s.text("table."+c.key, nil), Value: fmt.Sprint(c.value(u))})
+		}
+		for _, n := range all {
+			if wonBy(n, u.ID) {
+				p.Awards = append(p.Awards, n)
+			}
+		}
+		if m, ok := top[u.ID]; ok {
+			quoted := []rune(s.quote(m.Text))
+			if len(quoted) > topTextRunes {
+				quoted = append(quoted[:topTextRunes], '…')
+			}
+			p.Top = &TopMessage{Text: string(quoted), Date: m.Date, Day: s.dayLabel(m.Date), Reactions: reactionTotal(m)}
+		}
+		pages = append(pages, p)
+	}
+	return pages
+}
+
+// wonBy — победил ли id в номинации, в том числе при ничьей
+func wonBy(n Nomination, id string) bool {
+	if n.Redacted {
+		return false
+	}
+	if n.Winner == id {
+		return true
+	}
+	for _, w := range n.Winners {
+		if w == id {
+			return true
+		}
+	}
+	return false
+}
+
+// topMessages — у каждого участника сообщение с текстом и наибольшим
+// числом реакций; при равенстве — более раннее
+func topMessages(msg []telegram.Message) map[string]telegram.Message {
+	top := map[string]telegram.Message{}
+	for _, m := range msg {
+		if !FilterUser(m) || m.Text == "" {
+			continue
+		}
+		n := reactionTotal(m)
+		if n == 0 {
+			continue
+		}
+		if old, ok := top[m.FromID]; !ok || n > reactionTotal(old) {
+			top[m.FromID] = m
+		}
+	}
+	return top
+}
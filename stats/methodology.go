@@ -0,0 +1,70 @@
+package stats
+
+// Explainer — номинация, которая может рассказать, как она считается. Из этих
+// описаний собирается приложение «Как считали», чтобы спорить было не о чем.
+// Номинации из плагинов тоже могут его реализовать.
+type Explainer interface {
+	Method(s *Settings) string
+}
+
+// Note — одна строка приложения: карточка и как она посчитана
+type Note struct {
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+// methodNotes — общие оговорки, они идут первыми; тексты в каталоге,
+// methodology.<ключ>.title и .text
+var methodNotes = []string{"scope", "ties", "optout"}
+
+// Method — описание из каталога, nominations.<имя>.method; пороги берутся
+// из params номинации, у встроенных — из Thresholds страницы
+func (f funcNominator) Method(s *Settings) string {
+	key := "nominations." + f.name + ".method"
+	if !s.hasText(key) {
+		return ""
+	}
+	if f.params != nil {
+		return s.text(key, f.params)
+	}
+	return s.text(key, s.limits)
+}
+
+// Methodology — приложение «Как считали» к карточкам noms: общие оговорки и
+// описание каждой карточки, если номинация умеет себя описать
+func Methodology(s *Settings, noms []Nomination) []Note {
+	var notes []Note
+	for _, key := range methodNotes {
+		notes = append(notes, Note{
+			Title: s.text("methodology."+key+".title", nil),
+			Text:  s.text("methodology."+key+".text", nil),
+		})
+	}
+	seen := map[string]bool{}
+	for _, n := range noms {
+		if n.method == "" || seen[n.Title] {
+			continue
+		}
+		seen[n.Title] = true
+		notes = append(notes, Note{Title: n.Title, Text: n.method})
+	}
+	return notes
+}
+
+// describeFilter — условия CustomFilter человеческими словами
+func (s *Settings) describeFilter(f CustomFilter) string {
+	var parts []string
+	if f.MediaType != "" {
+		parts = append(parts, s.text("custom.filter.media", f.MediaType))
+	}
+	if f.Text != "" {
+		parts = append(parts, s.text("custom.filter.text", f.Text))
+	}
+	if f.Reaction != "" {
+		parts = append(parts, s.text("custom.filter.reaction", f.Reaction))
+	}
+	if len(parts) == 0 {
+		return s.text("custom.filter.all", nil)
+	}
+	return s.text("custom.filter.where", parts)
+}
@@ -0,0 +1,114 @@
+package stats
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// milestoneSteps — круглые числа сообщений, которые отмечаются на графике
+var milestoneSteps = []int{1000, 5000, 10000, 50000, 100000, 500000, 1000000}
+
+// milestoneMarks — сколько последних круглых чисел подписывать, чтобы
+// подписи не налезали друг на друга
+const milestoneMarks = 3
+
+// milestone — круглое число сообщений и сообщение, которым чат его взял
+type milestone struct {
+	count  int
+	date   time.Time
+	fromID string
+}
+
+// messageMilestones — «Путь к юбилею»: сколько сообщений набралось к
+// каждому дню года линией, с отметками круглых чисел — когда и кем
+// написано 10-тысячное, 50-тысячное…
+func messageMilestones(s *Settings) Accumulator {
+	var days [366]int
+	var marks []milestone
+	total, year := 0, 0
+	return accFunc{
+		add: func(m telegram.Message) {
+			if year == 0 {
+				year = m.Date.Year()
+			}
+			total++
+			if m.Date.Year() == year {
+				days[m.Date.YearDay()-1]++
+			}
+			if len(marks) < len(milestoneSteps) && total == milestoneSteps[len(marks)] {
+				marks = append(marks, milestone{total, m.Date, m.FromID})
+			}
+		},
+		result: func() (Nomination, bool) {
+			if len(marks) == 0 {
+				return Nomination{}, false
+			}
+			marks = marks[max(0, len(marks)-milestoneMarks):]
+			last := marks[len(marks)-1]
+			author := last.fromID
+			if s.optedOut(author) || author == telegram.ChatSenderID {
+				author = ""
+			}
+			d := s.newTextData(author)
+			d.Count, d.Total = last.count, total
+			d.Value = Number(s.lang, last.count)
+			d.Date = s.dayLabel(last.date)
+			nom := s.card("messageMilestones", d)
+			nom.Avatar = s.avatars.Common()
+			if author != "" {
+				nom.Avatar = s.userAvatar(author)
+			}
+			nom.Chart = s.milestoneChart(year, days[:], marks)
+			return nom, true
+		},
+	}
+}
+
+// milestoneChart рисует, сколько сообщений набралось к каждому дню года
+// year, и отмечает marks точкой с подписью: число, день и автор
+func (s *Settings) milestoneChart(year int, days []int, marks []milestone) string {
+	const w, h, pad, bottom = 480, 200, 8, 20
+	total := YearCoverage(s.lang, 0, year).Total
+	sums := make([]int, total)
+	sum := 0
+	for i := range sums {
+		sum += days[i]
+		sums[i] = sum
+	}
+	x := func(day int) float64 { return pad + float64(day)*(w-2*pad)/float64(total-1) }
+	y := func(n int) float64 { return h - bottom - float64(n)/float64(max(sum, 1))*(h-bottom-2*pad) }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12" fill="#9aa4c8">`, w, h, w, h)
+	points := make([]string, total)
+	for i, n := range sums {
+		points[i] = fmt.Sprintf("%.1f,%.1f", x(i), y(n))
+	}
+	fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="3" stroke-linejoin="round" points="%s"/>`, chartColors[0], strings.Join(points, " "))
+	for m := time.January; m <= time.December; m += 3 {
+		day := time.Date(year, m, 1, 0, 0, 0, 0, time.UTC).YearDay() - 1
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`, x(day), h-4, html.EscapeString(s.shortMonth(m)))
+	}
+	for _, mark := range marks {
+		px, py := x(mark.date.YearDay()-1), y(mark.count)
+		label := s.dayLabel(mark.date)
+		if name := s.avatars.Names[mark.fromID]; name != "" && !s.optedOut(mark.fromID) {
+			label += " · " + name
+		}
+		// подпись — с той стороны точки, где есть место
+		anchor, dx := "start", 8.0
+		if px > w/2 {
+			anchor, dx = "end", -8
+		}
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="5" fill="#ffe066"/>`, px, py)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="%s"><tspan font-weight="bold" fill="#ffe066">%s</tspan><tspan x="%.1f" dy="14">%s</tspan></text>`,
+			px+dx, py-4, anchor, html.EscapeString(Number(s.lang, mark.count)), px+dx, html.EscapeString(label))
+	}
+	b.WriteString(`</svg>`)
+	return "data:image/svg+xml;utf8," + url.PathEscape(b.String())
+}
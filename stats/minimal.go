@@ -0,0 +1,121 @@
+package stats
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// HashKey — короткий необратимый ключ вместо id или имени.
+// Соль из конфига не даёт сопоставить хеши перебором известных id.
+func HashKey(salt, key string) string {
+	if key == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(salt + "\x00" + key))
+	return "u" + hex.EncodeToString(sum[:4])
+}
+
+// MinimizeMessages обезличивает сообщения до подсчёта номинаций.
+// Тексты остаются в памяти для подсчёта длины и эмодзи, но номинации
+// в режиме Options.Minimal их не показывают.
+func MinimizeMessages(msg []telegram.Message, salt string) {
+	for i := range msg {
+		m := &msg[i]
+		m.ID = 0
+		if m.FromID != telegram.ChatSenderID { // «от имени чата» — не человек, прятать нечего
+			m.FromID = HashKey(salt, m.FromID)
+			m.From = m.FromID
+		}
+		m.Photo = minimizeValue(m.Photo)
+		m.ForwardedFrom = minimizeValue(m.ForwardedFrom)
+		// от id остаётся только вид — канал или человек
+		m.ForwardedFromID = strings.TrimRightFunc(m.ForwardedFromID, unicode.IsDigit)
+		// у service — кто сделал действие и с кем
+		m.ActorID = HashKey(salt, m.ActorID)
+		m.Actor = m.ActorID
+		members := make([]string, len(m.Members))
+		for j, name := range m.Members {
+			members[j] = HashKey(salt, name)
+		}
+		m.Members = members
+
+		entities := make([]telegram.TextFragment, len(m.TextEntities))
+		for j, e := range m.TextEntities {
+			entities[j] = e
+			if e.Type == "mention" || e.Type == "mention_name" {
+				entities[j].Text = "@" + HashKey(salt, strings.TrimPrefix(e.Text, "@"))
+			}
+		}
+		m.TextEntities = entities
+
+		reactions := make([]telegram.Reaction, len(m.Reactions))
+		for j, r := range m.Reactions {
+			reactions[j] = r
+			reactions[j].Recent = make([]telegram.ReactionUser, len(r.Recent))
+			for k, u := range r.Recent {
+				id := HashKey(salt, u.FromID)
+				reactions[j].Recent[k] = telegram.ReactionUser{From: id, FromID: id}
+			}
+		}
+		m.Reactions = reactions
+	}
+}
+
+// MinimalOptOut — opt_out для сообщений после MinimizeMessages. В конфиге
+// участник указан по from_id, имени или @нику, а после минимизации от них
+// остаются только хеши: каждый ключ дополняется from_id и именем того же
+// участника из msg, и всё хешируется с той же солью. Вызывается до
+// MinimizeMessages, пока имена ещё на месте.
+func MinimalOptOut(list []string, msg []telegram.Message, salt string) []string {
+	names := map[string]string{} // from_id → имя
+	ids := map[string][]string{} // имя → from_id
+	add := func(id, name string) {
+		if id != "" && name != "" && names[id] != name {
+			names[id] = name
+			ids[name] = append(ids[name], id)
+		}
+	}
+	for _, m := range msg {
+		add(m.FromID, m.From)
+		add(m.ActorID, m.Actor) // у service
+	}
+
+	var keys []string
+	for _, key := range list {
+		key = strings.TrimPrefix(strings.TrimSpace(key), "@")
+		if key == "" {
+			continue
+		}
+		keys = append(keys, key)
+		if name, ok := names[key]; ok {
+			keys = append(keys, name)
+		}
+		keys = append(keys, ids[key]...)
+	}
+	hashed := make([]string, len(keys))
+	for i, key := range keys {
+		hashed[i] = HashKey(salt, key)
+	}
+	return hashed
+}
+
+// minimizeValue оставляет только факт наличия значения
+func minimizeValue(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "-"
+}
+
+// quote — цитата из сообщения для подписи номинации; в режиме минимизации
+// вместо текста только его длина
+func (s *Settings) quote(text string) string {
+	if s.minimal {
+		return s.text("textHidden", len([]rune(text)))
+	}
+	return text
+}
@@ -0,0 +1,40 @@
+package stats_test
+
+import (
+	"testing"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+	"github.com/bebroedik/year-summary-2025/stats/statstest"
+)
+
+// opt_out по имени, как в примерах конфига, работает и после обезличивания:
+// from_id заменены хешами, а имя в них не участвует
+func TestMinimalOptOutByName(t *testing.T) {
+	const salt = "соль"
+	named := statstest.Named("Анна")
+	messages := chat(msg("user1", named), msg("user1", named), msg("user1", named), msg("user2", statstest.Named("Борис")))
+
+	for _, c := range []struct {
+		name   string
+		optOut []string
+		winner string
+	}{
+		{"без opt_out", nil, "user1"},
+		{"по имени", []string{"Анна"}, "user2"},
+		{"по from_id", []string{"user1"}, "user2"},
+		{"по @нику", []string{"@Анна"}, "user2"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			minimized := append(messages[:0:0], messages...)
+			optOut := stats.MinimalOptOut(c.optOut, minimized, salt)
+			stats.MinimizeMessages(minimized, salt)
+			statstest.Run(t, []statstest.Case{{
+				Name:       "mostTotalUser",
+				Nomination: "mostTotalUser",
+				Messages:   minimized,
+				Options:    stats.Options{Minimal: true, OptOut: optOut},
+				Winner:     stats.HashKey(salt, c.winner),
+			}})
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package stats
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// monthlyActivity — «Ритм года»: сообщения по месяцам столбиками, главным
+// числом — самый разговорчивый месяц
+func monthlyActivity(s *Settings) Accumulator {
+	var months [12]int
+	return accFunc{
+		add: func(m telegram.Message) {
+			months[m.Date.Month()-1]++
+		},
+		result: func() (Nomination, bool) {
+			busiest, active, total := 0, 0, 0
+			for i, n := range months {
+				if n > 0 {
+					active++
+				}
+				if n > months[busiest] {
+					busiest = i
+				}
+				total += n
+			}
+			if active < 2 {
+				return Nomination{}, false
+			}
+			d := s.newTextData("")
+			d.Count, d.Total = months[busiest], total
+			d.Percent = months[busiest] * 100 / total
+			d.Value = s.monthName(time.Month(busiest + 1))
+			nom := s.card("monthlyActivity", d)
+			nom.Avatar = s.avatars.Common()
+			labels := make([]string, len(months))
+			for i := range labels {
+				labels[i] = s.shortMonth(time.Month(i + 1))
+			}
+			nom.Chart = barChart(months[:], labels)
+			return nom, true
+		},
+	}
+}
+
+// shortMonth — первые три буквы названия месяца, для подписей графиков
+func (s *Settings) shortMonth(m time.Month) string {
+	name := []rune(s.monthName(m))
+	return string(name[:min(3, len(name))])
+}
+
+// barChart рисует столбики values с подписями labels снизу и числами
+// сверху; самый высокий выделен цветом. Как и lineChart, отдаёт SVG в
+// data URL.
+func barChart(values []int, labels []string) string {
+	const w, h, top, bottom = 480, 180, 20, 24
+	step := float64(w) / float64(len(values))
+	highest := 0
+	for _, v := range values {
+		highest = max(highest, v)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" text-anchor="middle" font-family="sans-serif">`, w, h, w, h)
+	for i, v := range values {
+		x := step * float64(i)
+		if v > 0 {
+			bar := float64(v) / float64(highest) * (h - top - bottom)
+			color := chartColors[0]
+			if v == highest {
+				color = "#ffe066"
+			}
+			fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" rx="3" fill="%s"/>`,
+				x+step*0.15, h-bottom-bar, step*0.7, bar, color)
+			fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-size="11" fill="#9aa4c8">%s</text>`, x+step/2, h-bottom-bar-5, strconv.Itoa(v))
+		}
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" font-size="13" fill="#9aa4c8">%s</text>`, x+step/2, h-6, html.EscapeString(labels[i]))
+	}
+	b.WriteString(`</svg>`)
+	return "data:image/svg+xml;utf8," + url.PathEscape(b.String())
+}
@@ -0,0 +1,79 @@
+package stats
+
+import (
+	"context"
+	"sort"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// Section — номинации одного чата на общей странице
+type Section struct {
+	Title       string       `json:"title"`
+	Nominations []Nomination `json:"nominations"`
+}
+
+func labelChat(m telegram.Message) string { return m.Chat }
+
+// chatNames возвращает чаты в порядке убывания числа сообщений
+func chatNames(msg []telegram.Message) []string {
+	cnt := Count(msg, FilterTrue, labelChat)
+	chats := make([]string, 0, len(cnt))
+	for c := range cnt {
+		chats = append(chats, c)
+	}
+	sort.Slice(chats, func(i, j int) bool {
+		if cnt[chats[i]] != cnt[chats[j]] {
+			return cnt[chats[i]] > cnt[chats[j]]
+		}
+		return chats[i] < chats[j]
+	})
+	return chats
+}
+
+func (s *Settings) busiestChat(msg []telegram.Message) Nomination {
+	chatCount := Count(msg, FilterTrue, labelChat)
+	chats, cnt := s.most(chatCount, true)
+	d := s.newTextData("")
+	d.Count, d.Value = cnt, s.joinNames(chats)
+	nom := s.card("busiestChat", d)
+	nom.Avatar = s.avatars.Common()
+	return nom
+}
+
+// FormMultiPage — общая страница по всем чатам; при perChat к ней
+// добавляется по разделу номинаций на каждый чат
+func FormMultiPage(s *Settings, msg []telegram.Message, perChat bool) PageData {
+	page, _ := FormMultiPageContext(context.Background(), s, msg, perChat)
+	return page
+}
+
+// FormMultiPageContext — FormMultiPage, который можно прервать
+func FormMultiPageContext(ctx context.Context, s *Settings, msg []telegram.Message, perChat bool) (PageData, error) {
+	page, err := FormPageContext(ctx, s, msg)
+	if err != nil {
+		return PageData{}, err
+	}
+
+	chats := chatNames(msg)
+	if len(chats) < 2 {
+		return page, nil
+	}
+
+	page.Nominations = append(page.Nominations, s.busiestChat(msg))
+
+	if perChat {
+		for _, chat := range chats {
+			chatMsg := FilterMessages(msg, func(m telegram.Message) bool { return m.Chat == chat })
+			chatPage, err := FormPageContext(ctx, s, chatMsg)
+			if err != nil {
+				return PageData{}, err
+			}
+			page.Sections = append(page.Sections, Section{
+				Title:       chat,
+				Nominations: chatPage.Nominations,
+			})
+		}
+	}
+	return page, nil
+}
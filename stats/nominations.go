@@ -0,0 +1,541 @@
+// Package stats считает номинации итогов года по сообщениям из пакета telegram.
+//
+//	msgs = stats.FilterMessages(msgs, stats.FilterTypeMessage, stats.FilterYear(2025))
+//	s, err := stats.NewSettings(stats.Options{}, msgs, nil)
+//	page := stats.FormPage(s, msgs)
+package stats
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// FilterMessages оставляет сообщения, прошедшие все фильтры
+func FilterMessages(msg []telegram.Message, filters ...func(telegram.Message) bool) []telegram.Message {
+	res := []telegram.Message{}
+	for _, m := range msg {
+		pass := true
+		for _, f := range filters {
+			if !f(m) {
+				pass = false
+				break
+			}
+		}
+		if pass {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+// Nomination — карточка на странице итогов
+type Nomination struct {
+	Title    string   `json:"title"`              // заголовок номинации
+	Avatar   string   `json:"avatar"`             // URL аватарки (может быть data URL)
+	Subtitle string   `json:"subtitle"`           // число или дата
+	Caption  string   `json:"caption"`            // подпись/комментарий
+	Redacted bool     `json:"redacted,omitempty"` // автор отказался от участия: размыть аватарку
+	Winner   string   `json:"winner,omitempty"`   // from_id победителя, если номинация про участника
+	Winners  []string `json:"winners,omitempty"`  // все победители при ничьей, Winner — первый из них
+	Podium   []Place  `json:"podium,omitempty"`   // первые места, если пьедестал включён (Options.Podium)
+	Chart    string   `json:"chart,omitempty"`    // график к номинации, SVG в data URL
+
+	method string  // как посчитана, для Methodology
+	board  []Place // таблица лидеров для пьедестала
+}
+
+// PageData — всё, что получает HTML-шаблон
+type PageData struct {
+	Title       string       `json:"title"`
+	Cover       string       `json:"cover,omitempty"` // обложка первого слайда, images.cover в конфиге
+	Nominations []Nomination `json:"nominations"`
+	Sections    []Section    `json:"sections,omitempty"`    // номинации по отдельным чатам
+	Timeline    []Event      `json:"timeline,omitempty"`    // хроника года, timeline в конфиге
+	Methodology []Note       `json:"methodology,omitempty"` // приложение «Как считали», methodology в конфиге
+	TopUsers    []Place      `json:"top_users,omitempty"`   // общий рейтинг по сообщениям, leaderboard в конфиге
+	Preview     string       `json:"preview,omitempty"`     // страница по выборке (-sample): плашка о том, что цифры неточные
+	Members     []MemberLink `json:"members,omitempty"`     // ссылки на страницы участников, только в режиме сайта
+	Build       BuildInfo    `json:"build"`                 // версия и время сборки для подписи внизу
+	Theme       *Theme       `json:"theme,omitempty"`       // палитра поверх цветов шаблона, -theme и -theme-file
+
+	Lang   string            `json:"lang"`   // язык страницы, <html lang>
+	Labels map[string]string `json:"labels"` // подписи шаблона на этом языке: prev, next, timeline, …
+}
+
+func (s *Settings) userAvatar(id string) string {
+	return s.avatars.Get(id)
+}
+
+// winnerCard — карточка номинации name про участников users; при ничьей
+// их несколько, и подпись начинается с имён: «Саша и Лена — поровну: …»
+func (s *Settings) winnerCard(name string, users []string, d textData) Nomination {
+	user := firstKey(users)
+	if len(users) > 1 {
+		d.captionData = s.newCaptionData(Nomination{Winner: user, Winners: users})
+	}
+	nom := s.card(name, d)
+	nom.Avatar = s.userAvatar(user)
+	nom.Winner = user
+	if len(users) > 1 {
+		nom.Winners = users
+		d.Caption = nom.Caption
+		nom.Caption = s.text("tie", d)
+	}
+	return nom
+}
+
+// userCard — самая частая карточка: участник и его число
+func (s *Settings) userCard(name string, users []string, count int) Nomination {
+	d := s.newTextData(firstKey(users))
+	d.Count = count
+	return s.winnerCard(name, users, d)
+}
+
+// LabelID и другие label* — ключи для Count
+func LabelID(m telegram.Message) string  { return m.FromID }
+func labelDay(m telegram.Message) string { return m.Date.Format(time.DateOnly) }
+
+// FilterTrue и другие filter* — фильтры для Count и FilterMessages
+func FilterTrue(m telegram.Message) bool                 { return true }
+func filterVideo(m telegram.Message) bool                { return m.MediaType == "video_message" }
+func filterGIF(m telegram.Message) bool                  { return m.MediaType == "animation" }
+func filterStory(m telegram.Message) bool                { return m.MediaType == telegram.MediaStory }
+func filterTextMsg(m telegram.Message) bool              { return m.MediaType == "" && m.Text != "" }
+func (s *Settings) filterTikTok(m telegram.Message) bool { return s.textContains(m, "tiktok.com") }
+func FilterTypeMessage(m telegram.Message) bool          { return m.Type == "message" }
+func filterForwarded(m telegram.Message) bool {
+	return m.ForwardedFrom != "" || m.ForwardedFromID != ""
+}
+
+// filterChannelRepost — пересылка из канала. Без forwarded_from_id (старые
+// экспорты) канал от человека не отличить, такие пересылки считаются от людей.
+func filterChannelRepost(m telegram.Message) bool {
+	return strings.HasPrefix(m.ForwardedFromID, "channel")
+}
+
+func filterUserForward(m telegram.Message) bool { return filterForwarded(m) && !filterChannelRepost(m) }
+
+// FilterUser — сообщение от участника, а не от имени чата (анонимный
+// админ) и не без автора вовсе
+func FilterUser(m telegram.Message) bool {
+	return m.FromID != "" && m.FromID != telegram.ChatSenderID
+}
+func FilterYear(year int) func(m telegram.Message) bool {
+	return func(m telegram.Message) bool {
+		return m.Date.Year() == year
+	}
+}
+
+// most — ключи с наибольшим (findMax) или наименьшим значением; при ничьей
+// все, по возрастанию (см. Leaderboard)
+func (s *Settings) most(userCounts map[string]int, findMax bool) ([]string, int) {
+	return winners(Leaderboard(s, userCounts, findMax))
+}
+
+// firstKey — первый из ключей most, пусто, если их нет
+func firstKey(keys []string) string {
+	if len(keys) == 0 {
+		return ""
+	}
+	return keys[0]
+}
+
+// Count считает сообщения, прошедшие filter, по ключу label
+func Count(msg []telegram.Message, filter func(telegram.Message) bool, label func(telegram.Message) string) map[string]int {
+	cnt := map[string]int{}
+	for _, m := range msg {
+		if filter(m) {
+			cnt[label(m)]++
+		}
+	}
+	return cnt
+}
+
+func messagesTotal(s *Settings) Accumulator {
+	total := 0
+	return accFunc{
+		add: func(telegram.Message) { total++ },
+		result: func() (Nomination, bool) {
+			d := s.newTextData("")
+			d.Count = total
+			nom := s.card("messagesTotal", d)
+			nom.Avatar = s.avatars.Common()
+			return nom, true
+		},
+	}
+}
+
+func mostTotalUser(s *Settings) Accumulator {
+	return tally(LabelID, each(FilterTrue), s.boardResult("mostTotalUser", true, false))
+}
+
+// firstMessage — первое текстовое сообщение года; без текстов карточки нет
+func firstMessage(s *Settings) Accumulator {
+	var first *telegram.Message
+	return accFunc{
+		add: func(m telegram.Message) {
+			if first == nil && filterTextMsg(m) {
+				first = &m
+			}
+		},
+		result: func() (Nomination, bool) {
+			if first == nil {
+				return Nomination{}, false
+			}
+			return s.redact(Nomination{
+				Title:    s.text("nominations.firstMessage.title", nil),
+				Subtitle: first.Date.Format(time.DateTime),
+				Caption:  s.quote(first.Text),
+				Avatar:   s.userAvatar(first.FromID),
+				Winner:   first.FromID,
+			}, first.FromID), true
+		},
+	}
+}
+
+func minTotalUser(s *Settings) Accumulator {
+	return tally(LabelID, each(FilterTrue), s.boardResult("minTotalUser", false, false))
+}
+
+func maxVideo(s *Settings) Accumulator {
+	return tally(LabelID, each(filterVideo), s.boardResult("maxVideo", true, false))
+}
+
+func maxTikTok(s *Settings) Accumulator {
+	return tally(LabelID, each(s.filterTikTok), s.boardResult("maxTikTok", true, false))
+}
+
+// maxForward — сплетник: пересылает сообщения людей
+func maxForward(s *Settings) Accumulator {
+	return tally(LabelID, each(filterUserForward), s.boardResult("maxForward", true, false))
+}
+
+// channelReposts — новостной агрегатор: репостит из каналов; если каналы
+// в экспорте не различить, карточки нет
+func channelReposts(s *Settings) Accumulator {
+	return tally(LabelID, each(filterChannelRepost), s.boardResult("channelReposts", true, true))
+}
+
+// storyShares — кто больше всех пересылает в чат сторис; в старых
+// экспортах сторис нет, и карточки тоже
+func storyShares(s *Settings) Accumulator {
+	return tally(LabelID, each(func(m telegram.Message) bool {
+		return FilterUser(m) && filterStory(m)
+	}), s.boardResult("storyShares", true, true))
+}
+
+func maxDay(s *Settings) Accumulator {
+	return tally(labelDay, each(FilterTrue), func(dayCount map[string]int) (Nomination, bool) {
+		days, cnt := s.most(dayCount, true)
+		d := s.newTextData("")
+		d.Count = cnt
+		// при ничьей — самый ранний из дней
+		if t, err := time.Parse(time.DateOnly, firstKey(days)); err == nil {
+			d.Date = s.weekdayLabel(t)
+		}
+		nom := s.card("maxDay", d)
+		nom.Avatar = s.avatars.Common()
+		return nom, true
+	})
+}
+
+func championByDays(s *Settings) Accumulator {
+	days := map[string]map[string]bool{}
+	year := 0
+	return accFunc{
+		add: func(m telegram.Message) {
+			if year == 0 {
+				year = m.Date.Year() // как yearOf: страница всегда по одному году
+			}
+			if !FilterUser(m) {
+				return
+			}
+			if days[m.FromID] == nil {
+				days[m.FromID] = map[string]bool{}
+			}
+			days[m.FromID][labelDay(m)] = true
+		},
+		result: func() (Nomination, bool) {
+			if year == 0 {
+				year = yearOf(nil)
+			}
+			counts := map[string]int{}
+			for id, d := range days {
+				counts[id] = len(d)
+			}
+			board := Leaderboard(s, counts, true) // ищем максимальное количество дней
+			users, cnt := winners(board)
+			d := s.newTextData(firstKey(users))
+			d.Count = cnt
+			d.Value = YearCoverage(s.lang, cnt, year).String()
+			nom := s.winnerCard("championByDays", users, d)
+			nom.board = board
+			return nom, true
+		},
+	}
+}
+
+func longestWriter(s *Settings) Accumulator {
+	userTotalLength := map[string]int{}
+	userMsgCount := map[string]int{}
+	return accFunc{
+		add: func(m telegram.Message) {
+			if !FilterUser(m) || m.Text == "" {
+				return
+			}
+			userTotalLength[m.FromID] += len(m.Text)
+			userMsgCount[m.FromID]++
+		},
+		result: func() (Nomination, bool) {
+			avgLength := map[string]int{}
+			for user, total := range userTotalLength {
+				avgLength[user] = total / userMsgCount[user]
+			}
+			return s.boardCard("longestWriter", Leaderboard(s, avgLength, true)), true // ищем максимальную среднюю длину
+		},
+	}
+}
+
+func maxStickers(s *Settings) Accumulator {
+	return tally(LabelID, each(func(m telegram.Message) bool {
+		return FilterUser(m) && m.MediaType == "sticker"
+	}), s.boardResult("maxStickers", true, false))
+}
+
+// maxGIFs — кто больше всех отвечает гифками, включая гифки через @gif
+func maxGIFs(s *Settings) Accumulator {
+	return tally(LabelID, each(func(m telegram.Message) bool {
+		return FilterUser(m) && filterGIF(m)
+	}), s.boardResult("maxGIFs", true, true))
+}
+
+// voiceTime — кто наговорил больше всего голосовых; без duration_seconds
+// в экспорте карточки нет
+func voiceTime(s *Settings) Accumulator {
+	seconds := func(m telegram.Message) int {
+		if FilterUser(m) && m.MediaType == "voice_message" && m.DurationSeconds > 0 {
+			return m.DurationSeconds
+		}
+		return 0
+	}
+	return tally(LabelID, seconds, func(userSeconds map[string]int) (Nomination, bool) {
+		board := Leaderboard(s, userSeconds, true)
+		users, _ := winners(board)
+		if len(users) == 0 {
+			return Nomination{}, false
+		}
+		for i, p := range board {
+			board[i].Label = HumanDuration(s.lang, time.Duration(p.Value)*time.Second)
+		}
+		d := s.newTextData(firstKey(users))
+		d.Value = board[0].Label
+		nom := s.winnerCard("voiceTime", users, d)
+		nom.board = board
+		return nom, true
+	})
+}
+
+// longestSilence — самая долгая пауза между сообщениями чата
+func longestSilence(s *Settings) Accumulator {
+	var dates []time.Time
+	return accFunc{
+		add: func(m telegram.Message) { dates = append(dates, m.Date) },
+		result: func() (Nomination, bool) {
+			if len(dates) < 2 {
+				return Nomination{}, false
+			}
+			sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+			var gap time.Duration
+			var since time.Time
+			for i := 1; i < len(dates); i++ {
+				if d := dates[i].Sub(dates[i-1]); d > gap {
+					gap, since = d, dates[i-1]
+				}
+			}
+			if gap == 0 {
+				return Nomination{}, false
+			}
+			d := s.newTextData("")
+			d.Value = HumanDuration(s.lang, gap)
+			d.Date = s.dayLabel(since)
+			nom := s.card("longestSilence", d)
+			nom.Avatar = s.avatars.Common()
+			return nom, true
+		},
+	}
+}
+
+// подсчёт количества эмодзи в строке
+func isEmoji(r rune) bool {
+	// диапазоны для эмодзи (часто используемые)
+	return (r >= 0x1F600 && r <= 0x1F64F) || // эмоции
+		(r >= 0x1F300 && r <= 0x1F5FF) || // символы и пиктограммы
+		(r >= 0x1F680 && r <= 0x1F6FF) || // транспорт и карты
+		(r >= 0x2600 && r <= 0x26FF) || // символы Misc
+		(r >= 0x2700 && r <= 0x27BF) // символы Misc Dingbats
+}
+
+func countEmoji(s string) int {
+	count := 0
+	for _, r := range s {
+		if isEmoji(r) {
+			count++
+		}
+	}
+	return count
+}
+
+func emojiMaster(s *Settings) Accumulator {
+	emoji := func(m telegram.Message) int {
+		if !FilterUser(m) {
+			return 0
+		}
+		return countEmoji(m.Text)
+	}
+	return tally(LabelID, emoji, s.boardResult("emojiMaster", true, false))
+}
+
+func mostUsedEmoji(s *Settings) Collector {
+	emojiCount := map[string]int{}
+	return collectorFunc{
+		subscribe: func(b *Bus) {
+			b.On(TextMessage, func(e BusEvent) {
+				for _, r := range e.Message.Text {
+					if isEmoji(r) {
+						emojiCount[string(r)]++
+					}
+				}
+			})
+		},
+		result: func() (Nomination, bool) {
+			emoji, cnt := s.most(emojiCount, true) // используем уже существующую функцию most
+
+			d := s.newTextData("")
+			d.Count, d.Value = cnt, s.joinNames(emoji)
+			nom := s.card("mostUsedEmoji", d)
+			nom.Avatar = s.avatars.Common() // можно оставить общую аватарку
+			return nom, true
+		},
+	}
+}
+
+// mostReactions — сумма реакций под сообщениями автора
+func mostReactions(s *Settings) Collector {
+	counts := map[string]int{}
+	return collectorFunc{
+		subscribe: func(b *Bus) {
+			b.On(Reaction, func(e BusEvent) {
+				if e.Reaction.Count != 0 && FilterUser(*e.Message) {
+					counts[LabelID(*e.Message)] += e.Reaction.Count
+				}
+			})
+		},
+		result: func() (Nomination, bool) { return s.boardResult("mostReactions", true, false)(counts) },
+	}
+}
+
+func mostGivenReactions(s *Settings) Collector {
+	userCount := map[string]int{}
+	return collectorFunc{
+		subscribe: func(b *Bus) {
+			b.On(Reaction, func(e BusEvent) {
+				for _, recent := range e.Reaction.Recent {
+					userCount[recent.FromID]++
+				}
+			})
+		},
+		result: func() (Nomination, bool) {
+			return s.boardCard("mostGivenReactions", Leaderboard(s, userCount, true)), true
+		},
+	}
+}
+
+func maxPhotos(s *Settings) Accumulator {
+	return tally(LabelID, each(func(m telegram.Message) bool { return m.Photo != "" }), s.boardResult("maxPhotos", true, false))
+}
+
+func mostMentioned(s *Settings) Accumulator {
+	mentionCount := map[string]int{}
+	return accFunc{
+		add: func(m telegram.Message) {
+			for _, ent := range m.TextEntities {
+				if ent.Type == "mention" && ent.Text != "" {
+					// убираем символ @
+					user := strings.TrimPrefix(ent.Text, "@")
+					mentionCount[user]++
+				}
+			}
+		},
+		result: func() (Nomination, bool) {
+			users, cnt := s.most(mentionCount, true)
+			for i, user := range users {
+				users[i] = "@" + user
+			}
+
+			d := s.newTextData("")
+			d.Count, d.Value = cnt, s.joinNames(users)
+			nom := s.card("mostMentioned", d)
+			nom.Avatar = s.avatars.Common()
+			if len(users) == 1 {
+				if id, ok := s.avatars.Find(users[0]); ok && !s.optedOut(id) {
+					nom.Avatar = s.userAvatar(id)
+				}
+			}
+			return nom, true
+		},
+	}
+}
+
+// pageTitle — «<чат> — итоги <год>» по названию чата из экспорта;
+// для нескольких чатов — общий заголовок
+func (s *Settings) pageTitle(msg []telegram.Message) string {
+	d := struct {
+		Chat string
+		Year int
+	}{Year: yearOf(msg)}
+	switch chats := chatNames(msg); {
+	case len(chats) > 1:
+		return s.text("page.titleMany", d)
+	case len(chats) == 1:
+		d.Chat = chats[0]
+	}
+	return s.text("page.title", d)
+}
+
+// FormPage считает все номинации по сообщениям одного или нескольких чатов
+func FormPage(s *Settings, msg []telegram.Message) PageData {
+	page, _ := FormPageContext(context.Background(), s, msg)
+	return page
+}
+
+// FormPageContext — FormPage, который можно прервать. Номинации считаются
+// параллельно (см. Options.Workers), накопители — за общие проходы по сообщениям;
+// порядок карточек — порядок реестра s.Registry().
+func FormPageContext(ctx context.Context, s *Settings, msg []telegram.Message) (PageData, error) {
+	return formPage(ctx, s, msg, s.registry.Enabled())
+}
+
+// formPage — страница из номинаций list в этом порядке
+func formPage(ctx context.Context, s *Settings, msg []telegram.Message, list []Nominator) (PageData, error) {
+	page := PageData{
+		Title:  s.pageTitle(msg),
+		Build:  Build(),
+		Lang:   s.lang,
+		Labels: Labels(s.lang),
+	}
+	res, err := computeAll(ctx, s, list, msg)
+	if err != nil {
+		return PageData{}, err
+	}
+	for i, n := range list {
+		if res[i].ok {
+			page.Nominations = append(page.Nominations, s.finish(n, res[i].nom))
+		}
+	}
+	return page, nil
+}
@@ -0,0 +1,189 @@
+package stats_test
+
+import (
+	"testing"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+	"github.com/bebroedik/year-summary-2025/stats/statstest"
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+var (
+	msg      = statstest.Msg
+	chat     = statstest.Chat
+	text     = statstest.WithText
+	reaction = statstest.WithReaction
+	media    = statstest.WithMedia
+)
+
+func TestNominations(t *testing.T) {
+	statstest.Run(t, []statstest.Case{{
+		Name:       "больше всех сообщений",
+		Nomination: "mostTotalUser",
+		Messages:   chat(msg("anna"), msg("bob"), msg("bob")),
+		Winner:     "bob",
+		Subtitle:   "2",
+	}, {
+		Name:       "меньше всех сообщений",
+		Nomination: "minTotalUser",
+		Messages:   chat(msg("anna"), msg("bob"), msg("bob")),
+		Winner:     "anna",
+		Subtitle:   "1",
+	}, {
+		Name:       "больше всех реакций",
+		Nomination: "mostReactions",
+		Messages: chat(
+			msg("anna", text("привет"), reaction("❤", "bob", "carl")),
+			msg("bob", text("и тебе"), reaction("👍", "anna")),
+		),
+		Winner:   "anna",
+		Subtitle: "2 реакций",
+	}, {
+		Name:       "больше всех стикеров",
+		Nomination: "maxStickers",
+		Messages:   chat(msg("anna", text("привет")), msg("bob", media("sticker")), msg("bob", media("sticker"))),
+		Winner:     "bob",
+		Subtitle:   "2 стикеров",
+	}, {
+		Name:       "первое сообщение года",
+		Nomination: "firstMessage",
+		Messages: chat(
+			msg("bob", media("sticker")),
+			msg("anna", text("с новым годом")),
+			msg("bob", text("и тебя")),
+		),
+		Winner: "anna",
+	}})
+}
+
+func TestNominationsTie(t *testing.T) {
+	statstest.Run(t, []statstest.Case{{
+		Name:       "поровну сообщений",
+		Nomination: "mostTotalUser",
+		Messages:   chat(msg("bob"), msg("anna"), msg("bob"), msg("anna"), msg("carl")),
+		Winner:     "anna",
+		Winners:    []string{"anna", "bob"},
+		Subtitle:   "2",
+	}, {
+		Name:       "поровну реакций",
+		Nomination: "mostReactions",
+		Messages: chat(
+			msg("anna", text("раз"), reaction("❤", "carl")),
+			msg("bob", text("два"), reaction("👍", "carl")),
+		),
+		Winner:  "anna",
+		Winners: []string{"anna", "bob"},
+	}, {
+		Name:       "без ничьей один победитель",
+		Nomination: "maxStickers",
+		Messages:   chat(msg("anna", media("sticker")), msg("bob", media("sticker")), msg("bob", media("sticker"))),
+		Winner:     "bob",
+		Winners:    []string{},
+	}})
+}
+
+func TestNominationsEmpty(t *testing.T) {
+	statstest.Run(t, []statstest.Case{{
+		Name:       "нет сообщений",
+		Nomination: "firstMessage",
+		NoCard:     true,
+	}, {
+		Name:       "нет текстов",
+		Nomination: "firstMessage",
+		Messages:   chat(msg("anna", media("sticker"))),
+		NoCard:     true,
+	}, {
+		Name:       "одно сообщение — нет тишины",
+		Nomination: "longestSilence",
+		Messages:   chat(msg("anna")),
+		NoCard:     true,
+	}})
+}
+
+// Номинации-рейтинги без участников всё равно дают карточку — пустую, без
+// победителя
+func TestNominationsEmptyBoard(t *testing.T) {
+	for _, c := range []struct {
+		name       string
+		nomination string
+		messages   []telegram.Message
+		optOut     []string
+	}{
+		{"нет сообщений", "mostTotalUser", nil, nil},
+		{"нет реакций", "mostReactions", chat(msg("anna", text("привет")), msg("bob", text("пока"))), nil},
+		{"все отказались", "mostTotalUser", chat(msg("anna"), msg("bob")), []string{"anna", "bob"}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			nom, ok, err := stats.Evaluate(c.nomination, c.messages, stats.Options{OptOut: c.optOut})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Fatalf("%s: no card", c.nomination)
+			}
+			if nom.Winner != "" || len(nom.Winners) != 0 {
+				t.Errorf("%s: winner %q %q, want none", c.nomination, nom.Winner, nom.Winners)
+			}
+		})
+	}
+}
+
+func TestNominationsOptOut(t *testing.T) {
+	messages := chat(msg("anna"), msg("bob"), msg("bob"), msg("bob"), msg("carl"), msg("carl"))
+	statstest.Run(t, []statstest.Case{{
+		Name:       "победитель без opt_out",
+		Nomination: "mostTotalUser",
+		Messages:   messages,
+		Winner:     "bob",
+		Subtitle:   "3",
+	}, {
+		Name:       "отказавшийся не побеждает",
+		Nomination: "mostTotalUser",
+		Messages:   messages,
+		Options:    stats.Options{OptOut: []string{"bob"}},
+		Winner:     "carl",
+		Subtitle:   "2",
+	}, {
+		Name:       "несколько отказавшихся",
+		Nomination: "mostTotalUser",
+		Messages:   messages,
+		Options:    stats.Options{OptOut: []string{"bob", "carl"}},
+		Winner:     "anna",
+		Subtitle:   "1",
+	}, {
+		Name:       "отказавшийся не делит первое место",
+		Nomination: "mostTotalUser",
+		Messages:   chat(msg("anna"), msg("anna"), msg("bob"), msg("bob")),
+		Options:    stats.Options{OptOut: []string{"anna"}},
+		Winner:     "bob",
+		Winners:    []string{},
+	}})
+}
+
+// Evaluate не держит настроек между вызовами: opt_out и язык одного
+// вызова не влияют на другие, в том числе параллельные
+func TestEvaluateIndependent(t *testing.T) {
+	messages := chat(msg("anna"), msg("bob"), msg("bob"))
+	for _, c := range []struct {
+		name   string
+		opts   stats.Options
+		winner string
+	}{
+		{"ru", stats.Options{}, "bob"},
+		{"en", stats.Options{Language: "en"}, "bob"},
+		{"opt_out", stats.Options{OptOut: []string{"bob"}}, "anna"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			for range 20 {
+				nom, ok, err := stats.Evaluate("mostTotalUser", messages, c.opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !ok || nom.Winner != c.winner {
+					t.Fatalf("winner %q (card %v), want %q", nom.Winner, ok, c.winner)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// Нормализация текста перед анализом: подсчёт слов, поиск по тексту
+// (filter.text своих номинаций, «смех», ссылки на TikTok) видят один и тот
+// же текст, а не каждый свою версию. Цитаты в подписях остаются как есть.
+
+// normSteps — шаги нормализации по именам, как в normalize конфига
+var normSteps = map[string]func(string) string{
+	// составные символы — в одну форму: «й» из «и» и бреве совпадает с «й»
+	"nfc": norm.NFC.String,
+	// ё → е: «ещё» и «еще» — одно слово
+	"yo":    foldYo,
+	"lower": strings.ToLower,
+	// пробелы, переводы строк и табуляции подряд — один пробел
+	"spaces": collapseSpaces,
+}
+
+var yoReplacer = strings.NewReplacer("ё", "е", "Ё", "Е")
+
+func foldYo(s string) string {
+	if !strings.ContainsAny(s, "ёЁ") {
+		return s
+	}
+	return yoReplacer.Replace(s)
+}
+
+func collapseSpaces(s string) string {
+	// обычно сокращать нечего, тогда и строку не копируем
+	prev := true // пробел в начале тоже лишний
+	clean := true
+	for _, r := range s {
+		space := unicode.IsSpace(r)
+		if space && (prev || r != ' ') {
+			clean = false
+			break
+		}
+		prev = space
+	}
+	if clean && !prev {
+		return s
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// DefaultNormalization — шаги по умолчанию, все по порядку
+var DefaultNormalization = []string{"nfc", "yo", "lower", "spaces"}
+
+// normalizer — цепочка шагов нормализации и её шаги по именам
+type normalizer struct {
+	chain []func(string) string
+	on    map[string]bool
+}
+
+// newNormalizer собирает цепочку шагов: nfc, yo, lower, spaces в нужном
+// порядке; nil — DefaultNormalization, "none" — без нормализации
+func newNormalizer(list []string) (normalizer, error) {
+	if list == nil {
+		list = DefaultNormalization
+	}
+	var names []string
+	for _, name := range list {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "none" {
+			continue
+		}
+		if _, ok := normSteps[name]; !ok {
+			known := make([]string, 0, len(normSteps))
+			for k := range normSteps {
+				known = append(known, k)
+			}
+			sort.Strings(known)
+			return normalizer{}, fmt.Errorf("unknown normalization step %q, known: %s, none", name, strings.Join(known, ", "))
+		}
+		names = append(names, name)
+	}
+	return normalizer{chain: chain(names), on: steps(names)}, nil
+}
+
+func chain(names []string) []func(string) string {
+	fns := make([]func(string) string, len(names))
+	for i, name := range names {
+		fns[i] = normSteps[name]
+	}
+	return fns
+}
+
+func steps(names []string) map[string]bool {
+	on := map[string]bool{}
+	for _, name := range names {
+		on[name] = true
+	}
+	return on
+}
+
+// text — текст сообщения для анализа
+func (n normalizer) text(s string) string {
+	for _, fn := range n.chain {
+		s = fn(s)
+	}
+	return s
+}
+
+// pattern — регулярное выражение под ту же цепочку: ё в шаблоне
+// заменяется на е, с lower поиск без учёта регистра. Сам шаблон в нижний
+// регистр не переводится: \S и \W значат не то же, что \s и \w.
+func (n normalizer) pattern(pattern string) string {
+	if n.on["nfc"] {
+		pattern = norm.NFC.String(pattern)
+	}
+	if n.on["yo"] {
+		pattern = foldYo(pattern)
+	}
+	if n.on["lower"] {
+		pattern = "(?i)" + pattern
+	}
+	return pattern
+}
+
+// NormalizeText — текст сообщения для анализа по цепочке Options.Normalize
+func (s *Settings) NormalizeText(text string) string { return s.norm.text(text) }
+
+// textContains — есть ли sub в нормализованном тексте сообщения
+func (s *Settings) textContains(m telegram.Message, sub string) bool {
+	return strings.Contains(s.NormalizeText(m.Text), sub)
+}
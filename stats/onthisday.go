@@ -0,0 +1,132 @@
+package stats
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// onThisDayLoudest — сколько самых разговорчивых показывать в «Год назад»
+const onThisDayLoudest = 3
+
+// OnThisDay — что было в чате год назад (команда on-this-day): за день или
+// за несколько дней, которые им заканчиваются
+type OnThisDay struct {
+	From     time.Time     `json:"from"` // первый день, полночь
+	To       time.Time     `json:"to"`   // последний день, полночь
+	Title    string        `json:"title"`
+	Messages int           `json:"messages"`
+	Members  int           `json:"members"` // сколько участников писали
+	Summary  string        `json:"summary"` // «412 сообщений от 9 участников» или что было тихо
+	Loudest  []Place       `json:"loudest,omitempty"`
+	Top      *OnThisDayTop `json:"top,omitempty"`
+
+	Labels map[string]string `json:"labels"` // подписи на языке отчёта: loudest, topMessage
+}
+
+// OnThisDayTop — сообщение тех дней с наибольшим числом реакций
+type OnThisDayTop struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Text      string `json:"text"`
+	Day       string `json:"day"`
+	Reactions int    `json:"reactions"`
+}
+
+// YearAgo — тот же день год назад; 29 февраля становится 28-м
+func YearAgo(day time.Time) time.Time {
+	y, month, d := day.Date()
+	if month == time.February && d == 29 {
+		d = 28
+	}
+	return time.Date(y-1, month, d, 0, 0, 0, 0, day.Location())
+}
+
+// OnThisDayOf собирает «Год назад» по сообщениям msg за days дней,
+// последний из которых — day: сколько написали, кто больше всех и
+// сообщение с наибольшим числом реакций. Отказавшиеся от участия в
+// разговорчивых и цитатах не показываются.
+func OnThisDayOf(s *Settings, msg []telegram.Message, day time.Time, days int) OnThisDay {
+	days = max(days, 1)
+	y, month, d := day.Date()
+	to := time.Date(y, month, d, 0, 0, 0, 0, day.Location())
+	from := to.AddDate(0, 0, 1-days)
+	end := to.AddDate(0, 0, 1)
+
+	res := OnThisDay{From: from, To: to, Labels: Labels(s.lang)}
+	counts := map[string]int{}
+	var top *telegram.Message
+	for i := range msg {
+		m := &msg[i]
+		if m.Date.Before(from) || !m.Date.Before(end) {
+			continue
+		}
+		res.Messages++
+		if !FilterUser(*m) {
+			continue
+		}
+		counts[m.FromID]++
+		if s.optedOut(m.FromID) || m.Text == "" {
+			continue
+		}
+		if n := reactionTotal(*m); n > 0 && (top == nil || n > reactionTotal(*top)) {
+			top = m
+		}
+	}
+	res.Members = len(counts)
+
+	label := s.dayLabel(to)
+	if days > 1 {
+		label = s.dayLabel(from) + " — " + label
+	}
+	res.Title = s.text("onThisDay.title", struct {
+		Day  string
+		Year int
+	}{label, to.Year()})
+	if res.Messages == 0 {
+		res.Summary = s.text("onThisDay.quiet", nil)
+		return res
+	}
+	res.Summary = s.text("onThisDay.summary", struct{ Count, Members int }{res.Messages, res.Members})
+
+	ids := make([]string, 0, len(counts))
+	for id := range counts {
+		if !s.optedOut(id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if counts[ids[i]] != counts[ids[j]] {
+			return counts[ids[i]] > counts[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	for i, id := range ids[:min(onThisDayLoudest, len(ids))] {
+		rank := i + 1
+		if i > 0 && counts[id] == counts[ids[i-1]] {
+			rank = res.Loudest[i-1].Rank // поровну — одно место
+		}
+		res.Loudest = append(res.Loudest, Place{
+			Rank:  rank,
+			ID:    id,
+			Name:  s.avatars.Names[id],
+			Value: counts[id],
+			Label: strconv.Itoa(counts[id]),
+		})
+	}
+
+	if top != nil {
+		quoted := []rune(s.quote(top.Text))
+		if len(quoted) > topTextRunes {
+			quoted = append(quoted[:topTextRunes], '…')
+		}
+		name := s.avatars.Names[top.FromID]
+		if name == "" {
+			name = top.From
+		}
+		res.Top = &OnThisDayTop{ID: top.ID, Name: name, Text: string(quoted), Day: s.dayLabel(top.Date), Reactions: reactionTotal(*top)}
+	}
+	return res
+}
@@ -0,0 +1,37 @@
+package stats
+
+import "strings"
+
+// optOutKeys — участники, которые не хотят попадать в номинации, из
+// opt_out конфига. Ключи — from_id, имена и @username без собаки: для
+// каждого from_id добавляется и имя, под которым участник виден в сообщениях.
+func optOutKeys(list []string, names map[string]string) map[string]bool {
+	optOut := map[string]bool{}
+	for _, key := range list {
+		key = strings.TrimPrefix(strings.TrimSpace(key), "@")
+		if key == "" {
+			continue
+		}
+		optOut[key] = true
+		if name, ok := names[key]; ok && name != "" {
+			optOut[name] = true
+		}
+	}
+	return optOut
+}
+
+func (s *Settings) optedOut(key string) bool {
+	return s.optOut[strings.TrimPrefix(key, "@")]
+}
+
+// redact скрывает цитату и аватарку, если номинация про отказавшегося участника
+func (s *Settings) redact(n Nomination, fromID string) Nomination {
+	if !s.optedOut(fromID) {
+		return n
+	}
+	n.Caption = s.text("redacted", nil)
+	n.Avatar = placeholderAvatar("", "")
+	n.Winner = ""
+	n.Redacted = true
+	return n
+}
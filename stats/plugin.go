@@ -0,0 +1,30 @@
+//go:build (linux || darwin || freebsd) && cgo
+
+package stats
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// LoadPlugin открывает номинации из Go-плагина (.so). Плагин — package main,
+// собранный с -buildmode=plugin той же версией Go и этого модуля, с функцией
+//
+//	func Nominators() []stats.Nominator
+//
+// Так админ чата может завести свои шуточные номинации, не форкая репозиторий.
+func LoadPlugin(path string) ([]Nominator, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open plugin: %w", err)
+	}
+	sym, err := p.Lookup("Nominators")
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", path, err)
+	}
+	list, ok := sym.(func() []Nominator)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s: Nominators is %T, want func() []stats.Nominator", path, sym)
+	}
+	return list(), nil
+}
@@ -0,0 +1,10 @@
+//go:build !((linux || darwin || freebsd) && cgo)
+
+package stats
+
+import "errors"
+
+// LoadPlugin — Go-плагины работают только на Linux, macOS и FreeBSD с cgo
+func LoadPlugin(path string) ([]Nominator, error) {
+	return nil, errors.New("go plugins are not supported on this platform")
+}
@@ -0,0 +1,101 @@
+package stats
+
+import (
+	"unicode/utf8"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// Лимиты обычного аккаунта: всё, что больше, можно только с Telegram Premium
+const (
+	freeCaptionRunes = 1024    // подпись к медиа
+	freeFileBytes    = 2 << 30 // 2 ГБ на файл
+)
+
+// premiumUsage — следы Premium у одного участника
+type premiumUsage struct {
+	Emoji     int // кастомные эмодзи в текстах
+	Reactions int // реакции кастомными эмодзи
+	Captions  int // подписи к медиа длиннее freeCaptionRunes
+	Files     int // файлы больше freeFileBytes
+}
+
+func (p premiumUsage) total() int {
+	return p.Emoji + p.Reactions + p.Captions + p.Files
+}
+
+// premiumUsages — следы Premium по участникам. Расшифровку голосовых
+// экспорт не сохраняет, её не посчитать.
+type premiumUsages map[string]*premiumUsage
+
+func (usage premiumUsages) get(id string) *premiumUsage {
+	if usage[id] == nil {
+		usage[id] = &premiumUsage{}
+	}
+	return usage[id]
+}
+
+func (usage premiumUsages) add(m telegram.Message) {
+	for _, r := range m.Reactions {
+		if r.Type != "custom_emoji" {
+			continue
+		}
+		for _, u := range r.Recent {
+			if u.FromID != "" {
+				usage.get(u.FromID).Reactions++
+			}
+		}
+	}
+	if !FilterUser(m) {
+		return
+	}
+	for _, e := range m.TextEntities {
+		if e.Type == "custom_emoji" {
+			usage.get(m.FromID).Emoji++
+		}
+	}
+	if m.MediaType != "" || m.Photo != "" {
+		if utf8.RuneCountInString(m.Text) > freeCaptionRunes {
+			usage.get(m.FromID).Captions++
+		}
+	}
+	if m.FileSize > freeFileBytes {
+		usage.get(m.FromID).Files++
+	}
+}
+
+// premiumTax — «Премиум-налог»: кто больше всех светит Telegram Premium
+func premiumTax(s *Settings) Accumulator {
+	usage := premiumUsages{}
+	return accFunc{add: usage.add, result: func() (Nomination, bool) { return usage.nomination(s) }}
+}
+
+func (usage premiumUsages) nomination(s *Settings) (Nomination, bool) {
+	totals := map[string]int{}
+	for id, p := range usage {
+		totals[id] = p.total()
+	}
+	board := Leaderboard(s, totals, true)
+	users, cnt := winners(board)
+	if len(users) == 0 || cnt == 0 {
+		return Nomination{}, false
+	}
+
+	// разбивка — у первого из победителей
+	p := usage[users[0]]
+	var parts []string
+	for _, part := range []struct {
+		key string
+		n   int
+	}{{"emoji", p.Emoji}, {"reactions", p.Reactions}, {"captions", p.Captions}, {"files", p.Files}} {
+		if part.n > 0 {
+			parts = append(parts, s.text("premium."+part.key, part.n))
+		}
+	}
+	d := s.newTextData(users[0])
+	d.Count = cnt
+	d.Value = s.joinNames(parts)
+	nom := s.winnerCard("premiumTax", users, d)
+	nom.board = board
+	return nom, true
+}
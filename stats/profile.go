@@ -0,0 +1,162 @@
+package stats
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// profileUsers — сколько самых активных участников рисовать на «Профиле
+// чата»: больше на одном графике не разобрать
+const profileUsers = 6
+
+// profileAxes — оси «Профиля чата», подписи — в каталоге, profile.<ось>
+var profileAxes = []string{"length", "emoji", "voice", "night"}
+
+// profileColors — цвета участников на графике; первые два — как у lineChart
+var profileColors = []string{"#ff4c6b", "#6bf2ff", "#ffe066", "#8dff6b", "#c38bff", "#ff9f43"}
+
+// styleCounts — из чего складывается стиль общения одного участника
+type styleCounts struct {
+	messages, texts, chars, emoji, voice, night int
+}
+
+// axes — значения по profileAxes: средняя длина текста, эмодзи на
+// сообщение, доля голосовых и доля сообщений с полуночи до пяти утра
+func (s styleCounts) axes() []float64 {
+	length := 0.0
+	if s.texts > 0 {
+		length = float64(s.chars) / float64(s.texts)
+	}
+	n := float64(s.messages)
+	return []float64{length, float64(s.emoji) / n, float64(s.voice) / n, float64(s.night) / n}
+}
+
+// chatProfile — «Профиль чата»: стиль общения самых активных участников на
+// одной лепестковой диаграмме. Каждая ось — от нуля до самого большого
+// значения среди них, так что видно, кто чем выделяется.
+func chatProfile(s *Settings) Accumulator {
+	counts := map[string]*styleCounts{}
+	return accFunc{
+		add: func(m telegram.Message) {
+			if !FilterUser(m) {
+				return
+			}
+			c := counts[m.FromID]
+			if c == nil {
+				c = &styleCounts{}
+				counts[m.FromID] = c
+			}
+			c.messages++
+			if m.Text != "" {
+				c.texts++
+				c.chars += utf8.RuneCountInString(m.Text)
+				c.emoji += countEmoji(m.Text)
+			}
+			if m.MediaType == "voice_message" {
+				c.voice++
+			}
+			if m.Date.Hour() < 5 {
+				c.night++
+			}
+		},
+		result: func() (Nomination, bool) {
+			var ids []string
+			for id, c := range counts {
+				if c.messages >= s.limits.DiscoverMinMessages && !s.optedOut(id) {
+					ids = append(ids, id)
+				}
+			}
+			sort.Slice(ids, func(i, j int) bool {
+				if counts[ids[i]].messages != counts[ids[j]].messages {
+					return counts[ids[i]].messages > counts[ids[j]].messages
+				}
+				return ids[i] < ids[j]
+			})
+			if len(ids) > profileUsers {
+				ids = ids[:profileUsers]
+			}
+			if len(ids) < 2 {
+				return Nomination{}, false
+			}
+
+			series := make([][]float64, len(ids))
+			names := make([]string, len(ids))
+			for i, id := range ids {
+				series[i] = counts[id].axes()
+				names[i] = s.avatars.Names[id]
+				if names[i] == "" {
+					names[i] = id
+				}
+			}
+			d := s.newTextData("")
+			d.Count = len(ids)
+			nom := s.card("chatProfile", d)
+			nom.Avatar = s.avatars.Common()
+			nom.Chart = s.radarChart(names, series)
+			return nom, true
+		},
+	}
+}
+
+// radarChart рисует лепестковую диаграмму: ось на каждое значение series,
+// многоугольник на каждого из names и подписи справа. Каждая ось в своём
+// масштабе, от нуля до наибольшего значения. Как и lineChart, отдаёт SVG
+// в data URL.
+func (s *Settings) radarChart(names []string, series [][]float64) string {
+	const w, h, cx, cy, r = 560, 360, 180, 180, 130
+	axes := len(profileAxes)
+	top := make([]float64, axes)
+	for _, s := range series {
+		for i, v := range s {
+			top[i] = max(top[i], v)
+		}
+	}
+	point := func(axis int, v float64) (float64, float64) {
+		angle := 2*math.Pi*float64(axis)/float64(axes) - math.Pi/2
+		return cx + v*r*math.Cos(angle), cy + v*r*math.Sin(angle)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="13">`, w, h, w, h)
+	for _, level := range []float64{0.25, 0.5, 0.75, 1} {
+		points := make([]string, axes)
+		for i := 0; i < axes; i++ {
+			x, y := point(i, level)
+			points[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+		}
+		fmt.Fprintf(&b, `<polygon fill="none" stroke="#9aa4c8" stroke-opacity="0.4" points="%s"/>`, strings.Join(points, " "))
+	}
+	for i, name := range profileAxes {
+		x, y := point(i, 1)
+		fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%.1f" y2="%.1f" stroke="#9aa4c8" stroke-opacity="0.4"/>`, cx, cy, x, y)
+		lx, ly := point(i, 1.12)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="middle" dominant-baseline="middle" fill="#9aa4c8">%s</text>`,
+			lx, ly, html.EscapeString(s.text("profile."+name, nil)))
+	}
+	for j, s := range series {
+		color := profileColors[j%len(profileColors)]
+		points := make([]string, axes)
+		for i, v := range s {
+			share := 0.0
+			if top[i] > 0 {
+				share = v / top[i]
+			}
+			x, y := point(i, share)
+			points[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+		}
+		fmt.Fprintf(&b, `<polygon fill="%s" fill-opacity="0.15" stroke="%s" stroke-width="2" stroke-linejoin="round" points="%s"/>`,
+			color, color, strings.Join(points, " "))
+		y := 40 + j*24
+		fmt.Fprintf(&b, `<rect x="380" y="%d" width="14" height="14" rx="3" fill="%s"/>`, y-11, color)
+		fmt.Fprintf(&b, `<text x="402" y="%d" fill="#9aa4c8">%s</text>`, y, html.EscapeString(names[j]))
+	}
+	b.WriteString(`</svg>`)
+	return "data:image/svg+xml;utf8," + url.PathEscape(b.String())
+}
@@ -0,0 +1,156 @@
+package stats
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// matrixMembers — сколько участников показывать на карте реакций: больше
+// клетки становятся нечитаемыми
+const matrixMembers = 10
+
+// ReactionMatrix — кто кому ставил реакции: Counts[i][j] — сколько реакций
+// Members[i] поставил под сообщениями Members[j]. Считается по recent в
+// экспорте, то есть не все реакции. Участники — по убыванию числа
+// поставленных и полученных реакций.
+type ReactionMatrix struct {
+	Members []string `json:"members"` // from_id
+	Names   []string `json:"names"`
+	Counts  [][]int  `json:"counts"`
+}
+
+// reactionPairs копит пары «кто поставил → кому»
+type reactionPairs map[[2]string]int
+
+// add — реакции e от тех, кого видно в recent, автору сообщения
+func (p reactionPairs) add(e BusEvent) {
+	if !FilterUser(*e.Message) {
+		return
+	}
+	for _, u := range e.Reaction.Recent {
+		if u.FromID != "" && u.FromID != telegram.ChatSenderID {
+			p[[2]string{u.FromID, e.Message.FromID}]++
+		}
+	}
+}
+
+// matrix — пары таблицей, не больше limit участников (0 — все); отказавшихся
+// от участия нет
+func (p reactionPairs) matrix(s *Settings, limit int) ReactionMatrix {
+	involved := map[string]int{}
+	for pair, n := range p {
+		involved[pair[0]] += n
+		involved[pair[1]] += n
+	}
+	ids := []string{}
+	for id := range involved {
+		if !s.optedOut(id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if involved[ids[i]] != involved[ids[j]] {
+			return involved[ids[i]] > involved[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+	res := ReactionMatrix{Members: ids, Names: make([]string, len(ids)), Counts: make([][]int, len(ids))}
+	for i, from := range ids {
+		res.Names[i] = s.avatars.Names[from]
+		res.Counts[i] = make([]int, len(ids))
+		for j, to := range ids {
+			res.Counts[i][j] = p[[2]string{from, to}]
+		}
+	}
+	return res
+}
+
+// reactionMatrix — «Кто кому ставит реакции»: карта реакций между самыми
+// заметными участниками, главное — самая преданная пара зритель → автор
+func reactionMatrix(s *Settings) Collector {
+	pairs := reactionPairs{}
+	return collectorFunc{
+		subscribe: func(b *Bus) {
+			b.On(Reaction, pairs.add)
+		},
+		result: func() (Nomination, bool) {
+			m := pairs.matrix(s, matrixMembers)
+			from, to := -1, -1
+			for i, row := range m.Counts {
+				for j, n := range row {
+					if i != j && n > 0 && (from < 0 || n > m.Counts[from][to]) {
+						from, to = i, j
+					}
+				}
+			}
+			if from < 0 {
+				return Nomination{}, false
+			}
+			d := s.newTextData(m.Members[from])
+			d.Count = m.Counts[from][to]
+			d.Names = []string{m.Names[from], m.Names[to]}
+			nom := s.card("reactionMatrix", d)
+			nom.Avatar = s.avatars.Common()
+			nom.Chart = s.matrixChart(m)
+			return nom, true
+		},
+	}
+}
+
+// matrixChart рисует ReactionMatrix тепловой картой: строка — кто ставил,
+// столбец — кому. У клетки подсказка «Аня → Борис: 12», самая частая пара
+// выделена; реакции себе — в подсказке, но без цвета.
+func (s *Settings) matrixChart(m ReactionMatrix) string {
+	const cell, step, left, top = 26, 28, 110, 100
+	n := len(m.Members)
+	w, h := left+n*step+top/2, top+n*step // справа — место под наклонные подписи
+	highest := 0
+	for i, row := range m.Counts {
+		for j, v := range row {
+			if i != j {
+				highest = max(highest, v)
+			}
+		}
+	}
+	short := make([]string, n)
+	for i, name := range m.Names {
+		r := []rune(name)
+		if len(r) > sparklineName {
+			r = append(r[:sparklineName-1], '…')
+		}
+		short[i] = html.EscapeString(string(r))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="11" fill="#9aa4c8">`, w, h, w, h)
+	for j, name := range short {
+		x := left + j*step + cell/2
+		fmt.Fprintf(&b, `<text x="%d" y="%d" transform="rotate(-45 %d %d)">%s</text>`, x, top-6, x, top-6, name)
+	}
+	for i, name := range short {
+		y := top + i*step
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`, left-6, y+cell/2+4, name)
+		for j, v := range m.Counts[i] {
+			title := s.text("nominations.reactionMatrix.cell", struct {
+				From, To string
+				Count    int
+			}{m.Names[i], m.Names[j], v})
+			fill := heatFill(v, highest)
+			if i == j {
+				fill = heatFill(0, highest)
+			}
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="3" %s><title>%s</title></rect>`,
+				left+j*step, y, cell, cell, fill, html.EscapeString(title))
+		}
+	}
+	b.WriteString(`</svg>`)
+	return "data:image/svg+xml;utf8," + url.PathEscape(b.String())
+}
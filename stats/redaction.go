@@ -0,0 +1,108 @@
+package stats
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// RedactRule — правило вычистки текста: регулярка и чем её заменить.
+// Если Pattern пустой, берётся встроенный шаблон по имени Preset.
+type RedactRule struct {
+	Preset  string   `yaml:"preset,omitempty"`  // phone, email, card
+	Pattern string   `yaml:"pattern,omitempty"` // регулярное выражение Go
+	Words   []string `yaml:"words,omitempty"`   // отдельные слова, без учёта регистра
+	Replace string   `yaml:"replace,omitempty"` // по умолчанию «█████»
+}
+
+var redactPresets = map[string]string{
+	"phone": `\+?\d[\d\-\s()]{8,}\d`,
+	"email": `[\w.+-]+@[\w-]+\.[\w.-]+`,
+	"card":  `\b(?:\d[ -]?){15,18}\d\b`,
+}
+
+// Redactor — скомпилированное правило вычистки
+type Redactor struct {
+	re      *regexp.Regexp
+	words   map[string]bool // если задано, заменяются только слова из набора
+	replace string
+}
+
+// \b в regexp понимает только ASCII, поэтому слова ищем по буквам Unicode
+var wordRe = regexp.MustCompile(`[\p{L}\p{N}_]+`)
+
+// CompileRedactions проверяет правила из конфига и компилирует регулярки
+func CompileRedactions(rules []RedactRule) ([]Redactor, error) {
+	var out []Redactor
+	for i, r := range rules {
+		pattern := r.Pattern
+		if pattern == "" && r.Preset != "" {
+			var ok bool
+			if pattern, ok = redactPresets[r.Preset]; !ok {
+				return nil, fmt.Errorf("redact rule %d: unknown preset %q", i+1, r.Preset)
+			}
+		}
+		replace := r.Replace
+		if replace == "" {
+			replace = "█████"
+		}
+
+		if len(r.Words) > 0 {
+			words := map[string]bool{}
+			for _, w := range r.Words {
+				words[strings.ToLower(w)] = true
+			}
+			out = append(out, Redactor{re: wordRe, words: words, replace: replace})
+		}
+		if pattern == "" {
+			if len(r.Words) == 0 {
+				return nil, fmt.Errorf("redact rule %d: need preset, pattern or words", i+1)
+			}
+			continue
+		}
+
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("redact rule %d: %w", i+1, err)
+		}
+		out = append(out, Redactor{re: re, replace: replace})
+	}
+	return out, nil
+}
+
+func redactText(s string, rules []Redactor) string {
+	for _, r := range rules {
+		if r.words == nil {
+			s = r.re.ReplaceAllLiteralString(s, r.replace)
+			continue
+		}
+		s = r.re.ReplaceAllStringFunc(s, func(w string) string {
+			if r.words[strings.ToLower(w)] {
+				return r.replace
+			}
+			return w
+		})
+	}
+	return s
+}
+
+// ApplyRedactions вычищает текст сообщений до того, как он попадёт
+// в номинации, шаблоны и выгрузки
+func ApplyRedactions(msg []telegram.Message, rules []Redactor) {
+	if len(rules) == 0 {
+		return
+	}
+	for i := range msg {
+		m := &msg[i]
+		m.Text = redactText(m.Text, rules)
+		if len(m.TextEntities) > 0 {
+			entities := make([]telegram.TextFragment, len(m.TextEntities))
+			for j, e := range m.TextEntities {
+				entities[j] = telegram.TextFragment{Type: e.Type, Text: redactText(e.Text, rules)}
+			}
+			m.TextEntities = entities
+		}
+	}
+}
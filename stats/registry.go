@@ -0,0 +1,238 @@
+package stats
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// Nominator — одна номинация страницы. Имя используют -only и nominations
+// в конфиге; Compute возвращает false, если карточку показывать не надо
+// (например, в чате нет подходящих сообщений). Язык, пороги, аватарки и
+// opt_out номинация берёт из s, см. Settings.
+type Nominator interface {
+	Name() string
+	Compute(s *Settings, msg []telegram.Message) (Nomination, bool)
+}
+
+type funcNominator struct {
+	name    string
+	compute func(*Settings, []telegram.Message) (Nomination, bool)
+	acc     func(*Settings) Accumulator // вместо compute, если номинация считается за общий проход
+	col     func(*Settings) Collector   // вместо compute, если номинация подписывается на события
+	params  any                         // пороги для описания в «Как считали», см. Method
+}
+
+func (f funcNominator) Name() string { return f.name }
+
+func (f funcNominator) Compute(s *Settings, msg []telegram.Message) (Nomination, bool) {
+	if f.col != nil {
+		return collect(s, f.col(s), msg)
+	}
+	if f.acc != nil {
+		return feed(f.acc(s), msg)
+	}
+	return f.compute(s, msg)
+}
+
+func (f funcNominator) NewAccumulator(s *Settings) Accumulator {
+	if f.acc == nil {
+		return nil
+	}
+	return f.acc(s)
+}
+
+func (f funcNominator) NewCollector(s *Settings) Collector {
+	if f.col == nil {
+		return nil
+	}
+	return f.col(s)
+}
+
+// NominatorFunc делает номинацию из функции, которая всегда даёт карточку
+func NominatorFunc(name string, form func(*Settings, []telegram.Message) Nomination) Nominator {
+	return funcNominator{name: name, compute: func(s *Settings, msg []telegram.Message) (Nomination, bool) {
+		return form(s, msg), true
+	}}
+}
+
+// Nominate считает номинацию с настройками s; картинку и подпись можно
+// заменить в nominations конфига
+func Nominate(s *Settings, n Nominator, msg []telegram.Message) (Nomination, bool) {
+	nom, ok := n.Compute(s, msg)
+	if !ok {
+		return Nomination{}, false
+	}
+	return s.finish(n, nom), true
+}
+
+// finish — общее для всех номинаций после подсчёта: картинка и подпись
+// из конфига, описание для «Как считали», пьедестал
+func (s *Settings) finish(n Nominator, nom Nomination) Nomination {
+	if avatar, ok := s.avatars.NominationAvatar(n.Name()); ok {
+		nom.Avatar = avatar
+	} else if nom.Avatar == "" {
+		nom.Avatar = s.avatars.Common() // номинации из плагинов могут обойтись без картинки
+	}
+	if t, ok := s.captions[strings.ToLower(n.Name())]; ok && !nom.Redacted {
+		nom.Caption = s.execCaption(t, nom)
+	}
+	if e, ok := n.(Explainer); ok {
+		nom.method = e.Method(s)
+	}
+	nom.Podium = s.podium(n.Name(), nom.board)
+	return nom
+}
+
+// Registry — номинации страницы по порядку. Свои номинации добавляются
+// через Register, порядок и набор меняются без правки FormPage.
+type Registry struct {
+	list     []Nominator
+	disabled map[string]bool // имя в нижнем регистре
+}
+
+// NewRegistry — реестр из номинаций list в этом порядке
+func NewRegistry(list ...Nominator) *Registry {
+	r := &Registry{disabled: map[string]bool{}}
+	for _, n := range list {
+		r.Register(n)
+	}
+	return r
+}
+
+// Nominators — номинации, из которых собирается страница, если в
+// Options.Registry не передан свой реестр
+var Nominators = NewRegistry(
+	AccumulatorFunc("messagesTotal", messagesTotal),
+	AccumulatorFunc("monthlyActivity", monthlyActivity),
+	AccumulatorFunc("activityCalendar", activityCalendar),
+	AccumulatorFunc("weeklyRhythm", weeklyRhythm),
+	AccumulatorFunc("memberTimelines", memberTimelines),
+	AccumulatorFunc("messageMilestones", messageMilestones),
+	AccumulatorFunc("mostTotalUser", mostTotalUser),
+	AccumulatorFunc("minTotalUser", minTotalUser),
+	AccumulatorFunc("firstMessage", firstMessage),
+	AccumulatorFunc("maxTikTok", maxTikTok),
+	AccumulatorFunc("maxVideo", maxVideo),
+	AccumulatorFunc("maxPhotos", maxPhotos),
+	AccumulatorFunc("mediaMix", mediaMix),
+	AccumulatorFunc("longestWriter", longestWriter),
+	AccumulatorFunc("championByDays", championByDays),
+	AccumulatorFunc("maxForward", maxForward),
+	AccumulatorFunc("channelReposts", channelReposts),
+	AccumulatorFunc("storyShares", storyShares),
+	AccumulatorFunc("mostMentioned", mostMentioned),
+	CollectorFunc("mostGivenReactions", mostGivenReactions),
+	CollectorFunc("reactionMatrix", reactionMatrix),
+	CollectorFunc("mostReactions", mostReactions),
+	CollectorFunc("reactionTrend", reactionTrend),
+	CollectorFunc("controversialPost", controversialPost),
+	AccumulatorFunc("emojiMaster", emojiMaster),
+	CollectorFunc("mostUsedEmoji", mostUsedEmoji),
+	AccumulatorFunc("maxStickers", maxStickers),
+	AccumulatorFunc("maxGIFs", maxGIFs),
+	AccumulatorFunc("voiceTime", voiceTime),
+	AccumulatorFunc("premiumTax", premiumTax),
+	CollectorFunc("boosters", boosters),
+	AccumulatorFunc("maxDay", maxDay),
+	AccumulatorFunc("longestSilence", longestSilence),
+	funcNominator{name: "syncedSouls", compute: syncedSouls},
+	AccumulatorFunc("chatProfile", chatProfile),
+)
+
+// Clone — копия реестра: номинации, порядок и выключенные. Так можно
+// применить конфиг заново, не накапливая прежние настройки.
+func (r *Registry) Clone() *Registry {
+	return &Registry{list: slices.Clone(r.list), disabled: maps.Clone(r.disabled)}
+}
+
+// Register добавляет номинацию в конец; номинация с тем же именем заменяется
+// на своём месте
+func (r *Registry) Register(n Nominator) {
+	for i, old := range r.list {
+		if strings.EqualFold(old.Name(), n.Name()) {
+			r.list[i] = n
+			return
+		}
+	}
+	r.list = append(r.list, n)
+}
+
+// Lookup ищет номинацию по имени без учёта регистра, включая выключенные
+func (r *Registry) Lookup(name string) (Nominator, bool) {
+	for _, n := range r.list {
+		if strings.EqualFold(n.Name(), name) {
+			return n, true
+		}
+	}
+	return nil, false
+}
+
+// Names — имена всех номинаций по порядку страницы
+func (r *Registry) Names() []string {
+	names := make([]string, len(r.list))
+	for i, n := range r.list {
+		names[i] = n.Name()
+	}
+	return names
+}
+
+// Enabled — номинации, которые попадут на страницу, по порядку
+func (r *Registry) Enabled() []Nominator {
+	var list []Nominator
+	for _, n := range r.list {
+		if !r.disabled[strings.ToLower(n.Name())] {
+			list = append(list, n)
+		}
+	}
+	return list
+}
+
+// IsEnabled — номинация не выключена; про неизвестные имена тоже true
+func (r *Registry) IsEnabled(name string) bool {
+	return !r.disabled[strings.ToLower(name)]
+}
+
+// SetEnabled включает или выключает номинацию
+func (r *Registry) SetEnabled(name string, enabled bool) error {
+	if _, ok := r.Lookup(name); !ok {
+		return r.unknown(name)
+	}
+	if enabled {
+		delete(r.disabled, strings.ToLower(name))
+	} else {
+		r.disabled[strings.ToLower(name)] = true
+	}
+	return nil
+}
+
+// Reorder ставит перечисленные номинации в начало в заданном порядке,
+// остальные идут за ними в прежнем
+func (r *Registry) Reorder(names []string) error {
+	list := make([]Nominator, 0, len(r.list))
+	placed := map[string]bool{}
+	for _, name := range names {
+		n, ok := r.Lookup(name)
+		if !ok {
+			return r.unknown(name)
+		}
+		if key := strings.ToLower(n.Name()); !placed[key] {
+			placed[key] = true
+			list = append(list, n)
+		}
+	}
+	for _, n := range r.list {
+		if !placed[strings.ToLower(n.Name())] {
+			list = append(list, n)
+		}
+	}
+	r.list = list
+	return nil
+}
+
+func (r *Registry) unknown(name string) error {
+	return fmt.Errorf("unknown nomination %q, known: %s", name, strings.Join(r.Names(), ", "))
+}
@@ -0,0 +1,54 @@
+package stats
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// ParseSample разбирает долю для -sample: "10%", "0.1"
+func ParseSample(s string) (float64, error) {
+	v := strings.TrimSpace(s)
+	percent := strings.HasSuffix(v, "%")
+	f, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("sample %q: want a share like 10%% or 0.1", s)
+	}
+	if percent {
+		f /= 100
+	}
+	if f <= 0 || f > 1 {
+		return 0, fmt.Errorf("sample %q: must be above 0 and at most 100%%", s)
+	}
+	return f, nil
+}
+
+// Sample — случайная доля fraction сообщений в прежнем порядке. Выбор
+// зависит только от seed, так что одна и та же выборка повторяется от
+// запуска к запуску, пока не поменяется экспорт.
+func Sample(msg []telegram.Message, fraction float64, seed int64) []telegram.Message {
+	if fraction >= 1 {
+		return msg
+	}
+	r := rand.New(rand.NewSource(seed))
+	out := make([]telegram.Message, 0, int(float64(len(msg))*fraction)+1)
+	for _, m := range msg {
+		if r.Float64() < fraction {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// PreviewNote — плашка на странице по выборке: сколько сообщений из
+// скольких посчитано, на языке l
+func PreviewNote(l string, kept, total int) string {
+	percent := 0
+	if total > 0 {
+		percent = kept * 100 / total
+	}
+	return textIn(l, "page.preview", struct{ Kept, Total, Percent int }{kept, total, percent})
+}
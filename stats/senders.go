@@ -0,0 +1,88 @@
+package stats
+
+import (
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// senders — справочник авторов по всему экспорту: from_id → имя и имя →
+// from_id. В старых сообщениях бывает пустой from при верном from_id и
+// наоборот; без справочника такие сообщения уходят к безымянному или
+// отдельному «участнику».
+type senders struct {
+	names map[string]string // from_id → последнее непустое имя
+	ids   map[string]string // имя → from_id; "" — у имени несколько id, не угадываем
+}
+
+func newSenders(msg []telegram.Message) senders {
+	s := senders{names: map[string]string{}, ids: map[string]string{}}
+	note := func(name, id string) {
+		if name == "" || id == "" || id == telegram.ChatSenderID {
+			return
+		}
+		s.names[id] = name
+		if old, ok := s.ids[name]; ok && old != id {
+			s.ids[name] = ""
+			return
+		}
+		s.ids[name] = id
+	}
+	for _, m := range msg {
+		note(m.From, m.FromID)
+		note(m.Actor, m.ActorID)
+		for _, r := range m.Reactions {
+			for _, u := range r.Recent {
+				note(u.From, u.FromID)
+			}
+		}
+	}
+	return s
+}
+
+// fill дополняет пару имя/id по справочнику; false — дополнять нечего
+func (s senders) fill(name, id *string) bool {
+	switch {
+	case *name == "" && *id != "":
+		if n := s.names[*id]; n != "" {
+			*name = n
+			return true
+		}
+	case (*id == "" || *id == telegram.ChatSenderID) && *name != "":
+		// без from_id парсер относит сообщение к чату; если имя — участника, это он
+		if i := s.ids[*name]; i != "" {
+			*id = i
+			return true
+		}
+	}
+	return false
+}
+
+// BackfillSenders заполняет в msg пустые from и from_id (а ещё actor у
+// service-сообщений и авторов реакций) по остальным сообщениям: имя — по
+// from_id, from_id — по имени, если оно у одного участника (и у сообщений,
+// которые без from_id достались ChatSenderID). Так подписи и подсчёты по
+// участникам одинаковы за весь год. Звать на всех сообщениях экспорта, до
+// отбора по году: в других годах бывают нужные имена.
+// Возвращает, сколько сообщений поправлено.
+func BackfillSenders(msg []telegram.Message) int {
+	s := newSenders(msg)
+	filled := 0
+	for i := range msg {
+		m := &msg[i]
+		changed := s.fill(&m.From, &m.FromID)
+		if s.fill(&m.Actor, &m.ActorID) {
+			changed = true
+		}
+		for j := range m.Reactions {
+			recent := m.Reactions[j].Recent
+			for k := range recent {
+				if s.fill(&recent[k].From, &recent[k].FromID) {
+					changed = true
+				}
+			}
+		}
+		if changed {
+			filled++
+		}
+	}
+	return filled
+}
@@ -0,0 +1,87 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// Settings — настройки одного подсчёта: язык, пороги, аватарки, opt_out,
+// подписи и пьедесталы из конфига, service-сообщения, режим минимизации
+// и число горутин. Номинации получают их аргументом, а не из состояния
+// пакета, поэтому подсчёты с разными настройками идут параллельно.
+// Собирает NewSettings; дальше меняются только замеры времени (TakeTimings)
+// и список пропавших картинок в AvatarSet.
+type Settings struct {
+	lang       string
+	limits     Thresholds
+	avatars    *AvatarSet
+	optOut     map[string]bool               // from_id, имена и @username без собаки
+	nameForms  map[string]NameForms          // формы имён по from_id
+	female     map[string]bool               // участницы по from_id
+	captions   map[string]*template.Template // свои подписи, имя номинации в нижнем регистре
+	podiumAll  int                           // мест на пьедестале у всех номинаций
+	podiumSize map[string]int                // у отдельных номинаций, имя в нижнем регистре
+	service    []telegram.Message            // service-сообщения года, из них бусты
+	minimal    bool                          // см. Options.Minimal
+	workers    int                           // см. Options.Workers
+	norm       normalizer                    // см. Options.Normalize
+	registry   *Registry                     // см. Options.Registry
+
+	timingsMu sync.Mutex
+	timings   []Timing
+}
+
+// NewSettings собирает настройки подсчёта из opts. messages — сообщения
+// страницы, по ним ищутся аватарки, если opts.Avatars не задан; service —
+// service-сообщения года, из них считаются бусты.
+func NewSettings(opts Options, messages, service []telegram.Message) (*Settings, error) {
+	l, err := ParseLanguage(opts.Language)
+	if err != nil {
+		return nil, err
+	}
+	s := &Settings{
+		lang:       l,
+		limits:     opts.Thresholds.withDefaults(),
+		avatars:    opts.Avatars,
+		nameForms:  map[string]NameForms{},
+		podiumAll:  opts.Podium,
+		podiumSize: map[string]int{},
+		service:    service,
+		minimal:    opts.Minimal,
+		workers:    opts.Workers,
+		registry:   opts.registry(),
+	}
+	if s.avatars == nil {
+		s.avatars = LoadAvatars(opts.AvatarDirs, ".", messages)
+	}
+	s.optOut = optOutKeys(opts.OptOut, s.avatars.Names)
+	for id, f := range opts.NameForms {
+		s.nameForms[id] = f
+	}
+	if s.female, err = femaleUsers(opts.Genders); err != nil {
+		return nil, fmt.Errorf("users: %w", err)
+	}
+	if s.captions, err = compileCaptions(opts.Captions); err != nil {
+		return nil, err
+	}
+	for name, n := range opts.Podiums {
+		s.podiumSize[strings.ToLower(name)] = n
+	}
+	if s.norm, err = newNormalizer(opts.Normalize); err != nil {
+		return nil, fmt.Errorf("normalize: %w", err)
+	}
+	return s, nil
+}
+
+// Language — язык страницы этих настроек
+func (s *Settings) Language() string { return s.lang }
+
+// Registry — номинации, из которых FormPage собирает страницу
+func (s *Settings) Registry() *Registry { return s.registry }
+
+// Avatars — картинки, из которых номинации берут аватарки
+func (s *Settings) Avatars() *AvatarSet { return s.avatars }
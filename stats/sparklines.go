@@ -0,0 +1,150 @@
+package stats
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// sparklineName — сколько символов имени влезает слева от линии
+const sparklineName = 14
+
+// memberTimelines — «Кто когда был»: у самых активных участников по
+// маленькому графику сообщений по месяцам. Видно, кто пропал к середине
+// года, а кто пришёл только в декабре.
+func memberTimelines(s *Settings) Accumulator {
+	months := map[string]*[12]int{}
+	return accFunc{
+		add: func(m telegram.Message) {
+			if !FilterUser(m) {
+				return
+			}
+			if months[m.FromID] == nil {
+				months[m.FromID] = &[12]int{}
+			}
+			months[m.FromID][m.Date.Month()-1]++
+		},
+		result: func() (Nomination, bool) {
+			totals := map[string]int{}
+			for id, counts := range months {
+				for _, n := range counts {
+					totals[id] += n
+				}
+			}
+			board := Leaderboard(s, totals, true)
+			if len(board) < 2 {
+				return Nomination{}, false
+			}
+			if n := s.limits.SparklineUsers; n > 0 && len(board) > n {
+				board = board[:n]
+			}
+
+			d := s.newTextData("")
+			d.Count = len(board)
+			// первый и последний месяц чата: экспорт мог начаться не с января
+			// и закончиться до декабря
+			first, last := 11, 0
+			for _, counts := range months {
+				for m, n := range counts {
+					if n > 0 {
+						first, last = min(first, m), max(last, m)
+					}
+				}
+			}
+			var notes []string
+			if id, month, ok := fadedMember(board, months, last-1); ok {
+				notes = append(notes, s.text("nominations.memberTimelines.faded", s.monthNote(id, month)))
+			}
+			if id, month, ok := lateMember(board, months, first+1); ok {
+				notes = append(notes, s.text("nominations.memberTimelines.late", s.monthNote(id, month)))
+			}
+			d.Value = strings.Join(notes, "; ")
+			nom := s.card("memberTimelines", d)
+			nom.Avatar = s.avatars.Common()
+			nom.Chart = s.sparklines(board, months)
+			return nom, true
+		},
+	}
+}
+
+// monthNote — поля подписи про участника id и месяц с нуля
+func (s *Settings) monthNote(id string, month int) any {
+	return struct{ Name, Month string }{s.avatars.Names[id], s.text("monthsGenitive."+strconv.Itoa(month), nil)}
+}
+
+// fadedMember — кто из board раньше всех замолчал: последний месяц с
+// сообщениями раньше before; при равенстве — кто выше в board
+func fadedMember(board []Place, months map[string]*[12]int, before int) (id string, last int, ok bool) {
+	last = before
+	for _, p := range board {
+		m := 11
+		for m > 0 && months[p.ID][m] == 0 {
+			m--
+		}
+		if m < last {
+			id, last, ok = p.ID, m, true
+		}
+	}
+	return id, last, ok
+}
+
+// lateMember — кто из board пришёл позже всех: первый месяц с сообщениями
+// позже after
+func lateMember(board []Place, months map[string]*[12]int, after int) (id string, first int, ok bool) {
+	first = after
+	for _, p := range board {
+		m := 0
+		for m < 11 && months[p.ID][m] == 0 {
+			m++
+		}
+		if m > first {
+			id, first, ok = p.ID, m, true
+		}
+	}
+	return id, first, ok
+}
+
+// sparklines рисует по строке на участника board: имя, линия сообщений по
+// месяцам в его собственном масштабе и сколько всего. У точек подсказка с
+// месяцем и числом.
+func (s *Settings) sparklines(board []Place, months map[string]*[12]int) string {
+	const w, row, nameW, totalW = 480, 30, 130, 50
+	h := row*len(board) + 20
+	step := float64(w-nameW-totalW) / 11
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="13" fill="#9aa4c8">`, w, h, w, h)
+	for i, p := range board {
+		counts := months[p.ID]
+		highest := 0
+		for _, n := range counts {
+			highest = max(highest, n)
+		}
+		base := float64(row*(i+1)) - 6
+		name := []rune(s.avatars.Names[p.ID])
+		if len(name) > sparklineName {
+			name = append(name[:sparklineName-1], '…')
+		}
+		fmt.Fprintf(&b, `<text x="0" y="%.1f">%s</text>`, base, html.EscapeString(string(name)))
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end">%s</text>`, w, base, strconv.Itoa(p.Value))
+		var points []string
+		for m, n := range counts {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", float64(nameW)+step*float64(m), base-float64(n)/float64(highest)*(row-10)))
+		}
+		color := chartColors[i%len(chartColors)]
+		fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2" stroke-linejoin="round"/>`, strings.Join(points, " "), color)
+		for m, n := range counts {
+			x, y, _ := strings.Cut(points[m], ",")
+			fmt.Fprintf(&b, `<circle cx="%s" cy="%s" r="5" fill="%s" fill-opacity="0"><title>%s: %d</title></circle>`, x, y, color, html.EscapeString(s.monthName(time.Month(m+1))), n)
+		}
+	}
+	for m := 0; m < 12; m += 3 {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" font-size="11" text-anchor="middle">%s</text>`, float64(nameW)+step*float64(m), h-4, html.EscapeString(s.shortMonth(time.Month(m+1))))
+	}
+	b.WriteString(`</svg>`)
+	return "data:image/svg+xml;utf8," + url.PathEscape(b.String())
+}
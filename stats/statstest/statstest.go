@@ -0,0 +1,157 @@
+// Package statstest — сообщения для проверки номинаций без экспорта и
+// табличные тесты поверх stats.Evaluate:
+//
+//	statstest.Run(t, []statstest.Case{{
+//		Name:       "больше всех реакций",
+//		Nomination: "mostReactions",
+//		Messages: statstest.Chat(
+//			statstest.Msg("anna", statstest.WithText("привет"), statstest.WithReaction("❤", "bob", "carl")),
+//			statstest.Msg("bob", statstest.WithText("и тебе"), statstest.WithReaction("👍", "anna")),
+//		),
+//		Winner: "anna",
+//	}})
+package statstest
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/stats"
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// Start — дата первого сообщения Chat, если у сообщений нет своей
+var Start = time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+// Option меняет сообщение, которое собирает Msg
+type Option func(*telegram.Message)
+
+// Msg — обычное сообщение участника from (from_id, он же имя)
+func Msg(from string, opts ...Option) telegram.Message {
+	m := telegram.Message{Type: "message", From: from, FromID: from}
+	for _, o := range opts {
+		o(&m)
+	}
+	return m
+}
+
+// Service — service-сообщение: actor сделал action с чатом
+func Service(actor, action string, opts ...Option) telegram.Message {
+	m := telegram.Message{Type: "service", Actor: actor, ActorID: actor, Action: action}
+	for _, o := range opts {
+		o(&m)
+	}
+	return m
+}
+
+// Chat нумерует сообщения с 1 и ставит тем, у кого нет даты, даты по
+// минуте от Start — в том порядке, в каком они переданы
+func Chat(msg ...telegram.Message) []telegram.Message {
+	out := make([]telegram.Message, len(msg))
+	for i, m := range msg {
+		m.ID = int64(i + 1)
+		if m.Date.IsZero() {
+			m.Date = Start.Add(time.Duration(i) * time.Minute)
+		}
+		out[i] = m
+	}
+	return out
+}
+
+// WithText — текст сообщения
+func WithText(text string) Option {
+	return func(m *telegram.Message) {
+		m.Text = text
+		m.TextEntities = []telegram.TextFragment{{Type: "plain", Text: text}}
+	}
+}
+
+// WithReaction — реакция emoji от участников from; без from — одна
+// анонимная
+func WithReaction(emoji string, from ...string) Option {
+	return func(m *telegram.Message) {
+		r := telegram.Reaction{Emoji: emoji, Count: max(len(from), 1), Type: "emoji"}
+		for _, id := range from {
+			r.Recent = append(r.Recent, telegram.ReactionUser{From: id, FromID: id})
+		}
+		m.Reactions = append(m.Reactions, r)
+	}
+}
+
+// At — дата сообщения
+func At(t time.Time) Option {
+	return func(m *telegram.Message) { m.Date = t }
+}
+
+// Named — имя отправителя, если оно не совпадает с from_id
+func Named(name string) Option {
+	return func(m *telegram.Message) { m.From = name }
+}
+
+// WithMedia — вложение: "sticker", "animation", "video_message", … ;
+// "photo" — фото, у него в экспорте нет media_type
+func WithMedia(mediaType string) Option {
+	return func(m *telegram.Message) {
+		if mediaType == "photo" {
+			m.Photo = "photos/photo.jpg"
+			return
+		}
+		m.MediaType = mediaType
+	}
+}
+
+// WithDuration — длительность голосового или кружка
+func WithDuration(d time.Duration) Option {
+	return func(m *telegram.Message) { m.DurationSeconds = int(d / time.Second) }
+}
+
+// Forwarded — пересылка от from; id "channel…" — репост из канала
+func Forwarded(from, id string) Option {
+	return func(m *telegram.Message) { m.ForwardedFrom, m.ForwardedFromID = from, id }
+}
+
+// Case — строка табличного теста номинации. Пустые поля ожиданий не
+// проверяются.
+type Case struct {
+	Name       string // имя подтеста
+	Nomination string // имя номинации в stats.Nominators
+	Messages   []telegram.Message
+	Options    stats.Options
+
+	NoCard   bool     // карточки быть не должно
+	Winner   string   // from_id победителя
+	Winners  []string // все победители при ничьей
+	Subtitle string   // число или дата на карточке
+}
+
+// Run — подтест на каждую строку cases
+func Run(t *testing.T, cases []Case) {
+	t.Helper()
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			nom, ok, err := stats.Evaluate(c.Nomination, c.Messages, c.Options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok == c.NoCard {
+				t.Fatalf("%s: card shown = %v, want %v", c.Nomination, ok, !c.NoCard)
+			}
+			Check(t, nom, c)
+		})
+	}
+}
+
+// Check сравнивает карточку с ожиданиями c
+func Check(t testing.TB, nom stats.Nomination, c Case) {
+	t.Helper()
+	if c.Winner != "" && nom.Winner != c.Winner {
+		t.Errorf("%s: winner %q, want %q", c.Nomination, nom.Winner, c.Winner)
+	}
+	if c.Winners != nil && !slices.Equal(nom.Winners, c.Winners) {
+		t.Errorf("%s: winners %q, want %q", c.Nomination, nom.Winners, c.Winners)
+	}
+	if c.Subtitle != "" && nom.Subtitle != c.Subtitle {
+		t.Errorf("%s: subtitle %q, want %q", c.Nomination, nom.Subtitle, c.Subtitle)
+	}
+}
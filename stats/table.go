@@ -0,0 +1,42 @@
+package stats
+
+import "math"
+
+// userColumns — столбцы таблицы участников: ключ подписи в каталоге
+// (table.<key>) и значение
+var userColumns = []struct {
+	key   string
+	value func(u UserStats) any
+}{
+	{"id", func(u UserStats) any { return u.ID }},
+	{"name", func(u UserStats) any { return u.Name }},
+	{"messages", func(u UserStats) any { return u.Messages }},
+	{"words", func(u UserStats) any { return u.Words }},
+	{"avgLength", func(u UserStats) any { return math.Round(u.AvgLength()*10) / 10 }},
+	{"photos", func(u UserStats) any { return u.Photos }},
+	{"videos", func(u UserStats) any { return u.Videos }},
+	{"voice", func(u UserStats) any { return u.Voice }},
+	{"voiceMinutes", func(u UserStats) any { return math.Round(float64(u.VoiceSeconds)/6) / 10 }},
+	{"stickers", func(u UserStats) any { return u.Stickers }},
+	{"gifs", func(u UserStats) any { return u.GIFs }},
+	{"forwards", func(u UserStats) any { return u.Forwards }},
+	{"reactionsReceived", func(u UserStats) any { return u.ReactionsReceived }},
+	{"reactionsGiven", func(u UserStats) any { return u.ReactionsGiven }},
+	{"activeDays", func(u UserStats) any { return u.ActiveDays }},
+}
+
+// UserTable — участники таблицей, строка на участника в порядке Users:
+// подписи столбцов на языке страницы и значения (string, int или float64)
+func (a *Aggregates) UserTable() (header []string, rows [][]any) {
+	for _, c := range userColumns {
+		header = append(header, textIn(a.lang, "table."+c.key, nil))
+	}
+	for _, u := range a.Users {
+		row := make([]any, len(userColumns))
+		for i, c := range userColumns {
+			row[i] = c.value(u)
+		}
+		rows = append(rows, row)
+	}
+	return header, rows
+}
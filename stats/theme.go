@@ -0,0 +1,9 @@
+package stats
+
+// Theme — оформление страницы: значения CSS-переменных шаблона (--bg,
+// --accent, …). Задаётся при отрисовке, см. render.LoadTheme; без темы
+// шаблон рисуется своими цветами.
+type Theme struct {
+	Name string            `json:"name,omitempty"` // встроенная палитра, если выбрана
+	Vars map[string]string `json:"vars"`           // имя переменной без «--» → значение
+}
@@ -0,0 +1,62 @@
+package stats
+
+// Thresholds — пороги, от которых зависят карточки и хроника. Нулевое
+// поле — значение по умолчанию, так в конфиге можно задать только нужное.
+type Thresholds struct {
+	CorrMinMessages         int     `yaml:"corr_min_messages,omitempty" json:"corr_min_messages,omitempty"`                 // «Синхронные души»: участник с меньшим числом сообщений в пары не попадает
+	CorrMinR                float64 `yaml:"corr_min_r,omitempty" json:"corr_min_r,omitempty"`                               // «Синхронные души»: слабее совпадение не считается
+	DiscoverMinMessages     int     `yaml:"discover_min_messages,omitempty" json:"discover_min_messages,omitempty"`         // необычные факты: участник или месяц с меньшим числом сообщений не сравнивается
+	DiscoverMinZ            float64 `yaml:"discover_min_z,omitempty" json:"discover_min_z,omitempty"`                       // необычные факты: менее необычное за факт не считается
+	SpikeMinZ               float64 `yaml:"spike_min_z,omitempty" json:"spike_min_z,omitempty"`                             // хроника: насколько день должен выбиваться из обычных
+	TimelineSpikes          int     `yaml:"timeline_spikes,omitempty" json:"timeline_spikes,omitempty"`                     // хроника: сколько самых бурных дней показать
+	TimelineTop             int     `yaml:"timeline_top,omitempty" json:"timeline_top,omitempty"`                           // хроника: сколько постов с наибольшим числом реакций
+	ControversyMinReactions int     `yaml:"controversy_min_reactions,omitempty" json:"controversy_min_reactions,omitempty"` // «Спорный пост года»: с меньшим числом реакций пост не спорный
+	SparklineUsers          int     `yaml:"sparkline_users,omitempty" json:"sparkline_users,omitempty"`                     // «Кто когда был»: у скольких самых активных рисовать график
+}
+
+// DefaultThresholds — пороги по умолчанию
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		CorrMinMessages:         30, // иначе случайные совпадения двух молчунов дают корреляцию под единицу
+		CorrMinR:                0.3,
+		DiscoverMinMessages:     30,
+		DiscoverMinZ:            1.5,
+		SpikeMinZ:               2.0,
+		TimelineSpikes:          5,
+		TimelineTop:             3,
+		ControversyMinReactions: 6, // иначе спорным окажется любой пост с 👍 и ❤ по одной
+		SparklineUsers:          8,
+	}
+}
+
+func (t Thresholds) withDefaults() Thresholds {
+	d := DefaultThresholds()
+	if t.CorrMinMessages == 0 {
+		t.CorrMinMessages = d.CorrMinMessages
+	}
+	if t.CorrMinR == 0 {
+		t.CorrMinR = d.CorrMinR
+	}
+	if t.DiscoverMinMessages == 0 {
+		t.DiscoverMinMessages = d.DiscoverMinMessages
+	}
+	if t.DiscoverMinZ == 0 {
+		t.DiscoverMinZ = d.DiscoverMinZ
+	}
+	if t.SpikeMinZ == 0 {
+		t.SpikeMinZ = d.SpikeMinZ
+	}
+	if t.TimelineSpikes == 0 {
+		t.TimelineSpikes = d.TimelineSpikes
+	}
+	if t.TimelineTop == 0 {
+		t.TimelineTop = d.TimelineTop
+	}
+	if t.ControversyMinReactions == 0 {
+		t.ControversyMinReactions = d.ControversyMinReactions
+	}
+	if t.SparklineUsers == 0 {
+		t.SparklineUsers = d.SparklineUsers
+	}
+	return t
+}
@@ -0,0 +1,223 @@
+package stats
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// Event — строчка «хроники года»
+type Event struct {
+	Kind  string    `json:"kind"`          // month, spike, title, join, leave, top
+	Date  time.Time `json:"date"`          // для сортировки
+	Day   string    `json:"day,omitempty"` // «14 февраля»; у month пусто
+	Title string    `json:"title"`
+	Text  string    `json:"text,omitempty"`
+}
+
+// topTextRunes — длиннее цитата обрезается; остальные пороги хроники — в Thresholds
+const topTextRunes = 140
+
+// Timeline собирает хронику года: всплески активности, переименования чата,
+// кто пришёл и ушёл, посты с наибольшим числом реакций. Перед событиями
+// каждого месяца идёт его заголовок. Нужны все сообщения года, включая
+// service — из них берутся переименования и состав чата.
+func Timeline(s *Settings, msg []telegram.Message) []Event {
+	var events []Event
+	events = append(events, s.spikeEvents(msg)...)
+	events = append(events, s.serviceEvents(msg)...)
+	events = append(events, s.topEvents(msg)...)
+	if len(events) == 0 {
+		return nil
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Date.Before(events[j].Date) })
+
+	var res []Event
+	month := time.Month(0)
+	for _, e := range events {
+		if e.Date.Month() != month {
+			month = e.Date.Month()
+			res = append(res, Event{
+				Kind:  "month",
+				Date:  time.Date(e.Date.Year(), month, 1, 0, 0, 0, 0, e.Date.Location()),
+				Title: s.monthName(month),
+			})
+		}
+		e.Day = s.dayLabel(e.Date)
+		res = append(res, e)
+	}
+	return res
+}
+
+// spikeEvents — дни, когда сообщений было намного больше обычного
+func (s *Settings) spikeEvents(msg []telegram.Message) []Event {
+	days := map[string]int{}
+	dates := map[string]time.Time{}
+	for _, m := range msg {
+		if m.Type != "message" {
+			continue
+		}
+		key := m.Date.Format("2006-01-02")
+		days[key]++
+		if _, ok := dates[key]; !ok {
+			dates[key] = m.Date
+		}
+	}
+	counts := make([]float64, 0, len(days))
+	for _, n := range days {
+		counts = append(counts, float64(n))
+	}
+	// порядок обхода map меняет последние биты суммы, а с ними — попадание на порог
+	sort.Float64s(counts)
+	mean, std := meanStd(counts)
+	if len(counts) < 7 || std == 0 {
+		return nil
+	}
+
+	var keys []string
+	for key, n := range days {
+		if (float64(n)-mean)/std >= s.limits.SpikeMinZ {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if days[keys[i]] != days[keys[j]] {
+			return days[keys[i]] > days[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > s.limits.TimelineSpikes {
+		keys = keys[:s.limits.TimelineSpikes]
+	}
+
+	events := make([]Event, len(keys))
+	for i, key := range keys {
+		d := textData{Count: days[key], Times: float64(days[key]) / mean}
+		events[i] = Event{
+			Kind:  "spike",
+			Date:  dates[key],
+			Title: s.text("timeline.spikeTitle", d),
+			Text:  s.text("timeline.spikeText", d),
+		}
+	}
+	return events
+}
+
+// serviceEvents — переименования чата, кто пришёл и кто ушёл
+func (s *Settings) serviceEvents(msg []telegram.Message) []Event {
+	var events []Event
+	for _, m := range msg {
+		if m.Type != "service" {
+			continue
+		}
+		// в подписях .Name — тот, кто сделал действие
+		d := s.newTextData(m.ActorID)
+		if d.Name == "" {
+			d.Name = m.Actor
+		}
+		switch m.Action {
+		case "edit_group_title":
+			if m.Title == "" {
+				continue
+			}
+			events = append(events, Event{
+				Kind:  "title",
+				Date:  m.Date,
+				Title: s.text("timeline.renameTitle", textData{Value: m.Title}),
+				Text:  s.text("timeline.renameText", d),
+			})
+		case "join_group_by_link":
+			if s.optedOut(m.ActorID) {
+				continue
+			}
+			events = append(events, Event{
+				Kind:  "join",
+				Date:  m.Date,
+				Title: d.Name,
+				Text:  s.text("timeline.joinLink", d),
+			})
+		case "invite_members":
+			members := s.visibleMembers(m.Members)
+			if len(members) == 0 {
+				continue
+			}
+			events = append(events, Event{
+				Kind:  "join",
+				Date:  m.Date,
+				Title: strings.Join(members, ", "),
+				Text:  s.text("timeline.invited", d),
+			})
+		case "remove_members":
+			members := s.visibleMembers(m.Members)
+			if len(members) == 0 {
+				continue
+			}
+			key := "timeline.removed"
+			if len(m.Members) == 1 && m.Members[0] == m.Actor {
+				key = "timeline.left"
+			}
+			events = append(events, Event{
+				Kind:  "leave",
+				Date:  m.Date,
+				Title: strings.Join(members, ", "),
+				Text:  s.text(key, d),
+			})
+		}
+	}
+	return events
+}
+
+// visibleMembers убирает из списка тех, кто отказался от участия
+func (s *Settings) visibleMembers(members []string) []string {
+	var res []string
+	for _, name := range members {
+		if name != "" && !s.optedOut(name) {
+			res = append(res, name)
+		}
+	}
+	return res
+}
+
+// topEvents — посты с наибольшим числом реакций
+func (s *Settings) topEvents(msg []telegram.Message) []Event {
+	var top []telegram.Message
+	for _, m := range msg {
+		if m.Type == "message" && m.Text != "" && reactionTotal(m) > 0 && !s.optedOut(m.FromID) {
+			top = append(top, m)
+		}
+	}
+	sort.SliceStable(top, func(i, j int) bool { return reactionTotal(top[i]) > reactionTotal(top[j]) })
+	if len(top) > s.limits.TimelineTop {
+		top = top[:s.limits.TimelineTop]
+	}
+
+	events := make([]Event, len(top))
+	for i, m := range top {
+		d := s.newTextData(m.FromID)
+		if d.Name == "" {
+			d.Name = m.From
+		}
+		d.Count = reactionTotal(m)
+		quoted := []rune(m.Text)
+		if len(quoted) > topTextRunes {
+			quoted = append(quoted[:topTextRunes], '…')
+		}
+		events[i] = Event{
+			Kind:  "top",
+			Date:  m.Date,
+			Title: s.text("timeline.top", d),
+			Text:  string(quoted),
+		}
+	}
+	return events
+}
+
+func reactionTotal(m telegram.Message) int {
+	total := 0
+	for _, r := range m.Reactions {
+		total += r.Count
+	}
+	return total
+}
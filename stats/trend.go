@@ -0,0 +1,95 @@
+package stats
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// noReaction — месяц без реакций в полосе «Эволюции реакций»
+const noReaction = "·"
+
+// reactionKey — чем считать реакцию: эмодзи, а у custom_emoji и платных
+// реакций, где эмодзи нет, — тип
+func reactionKey(r *telegram.Reaction) string {
+	if r.Emoji != "" {
+		return r.Emoji
+	}
+	return r.Type
+}
+
+// dominantReactions — самая частая реакция каждого месяца, "" — реакций не было
+func dominantReactions(months *[12]map[string]int) [12]string {
+	var top [12]string
+	for i, counts := range months {
+		if list := emojiTable(counts); len(list) > 0 {
+			top[i] = list[0].Emoji
+		}
+	}
+	return top
+}
+
+// reactionTrend — «Эволюция реакций»: главная реакция каждого месяца
+// полосой из 12 эмодзи
+func reactionTrend(s *Settings) Collector {
+	var months [12]map[string]int
+	return collectorFunc{
+		subscribe: func(b *Bus) {
+			b.On(Reaction, func(e BusEvent) {
+				if e.Reaction.Count <= 0 {
+					return
+				}
+				i := e.Message.Date.Month() - 1
+				if months[i] == nil {
+					months[i] = map[string]int{}
+				}
+				months[i][reactionKey(e.Reaction)] += e.Reaction.Count
+			})
+		},
+		result: func() (Nomination, bool) {
+			top := dominantReactions(&months)
+			strip := make([]string, len(top))
+			active, changes, prev := 0, 0, ""
+			for i, emoji := range top {
+				strip[i] = noReaction
+				if emoji == "" {
+					continue
+				}
+				strip[i] = emoji
+				if prev != "" && emoji != prev {
+					changes++
+				}
+				active, prev = active+1, emoji
+			}
+			if active < 2 {
+				return Nomination{}, false
+			}
+			d := s.newTextData("")
+			d.Count, d.Value = changes, strings.Join(strip, "")
+			nom := s.card("reactionTrend", d)
+			nom.Avatar = s.avatars.Common()
+			nom.Chart = s.emojiStrip(strip)
+			return nom, true
+		},
+	}
+}
+
+// emojiStrip рисует эмодзи по месяцам в ряд с подписями месяцев, как
+// lineChart — SVG в data URL
+func (s *Settings) emojiStrip(strip []string) string {
+	const w, h = 480, 72
+	step := float64(w) / float64(len(strip))
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" text-anchor="middle" font-family="sans-serif">`, w, h, w, h)
+	for i, emoji := range strip {
+		x := step * (float64(i) + 0.5)
+		fmt.Fprintf(&b, `<text x="%.1f" y="38" font-size="28">%s</text>`, x, html.EscapeString(emoji))
+		fmt.Fprintf(&b, `<text x="%.1f" y="64" font-size="13" fill="#9aa4c8">%s</text>`, x, html.EscapeString(s.shortMonth(time.Month(i+1))))
+	}
+	b.WriteString(`</svg>`)
+	return "data:image/svg+xml;utf8," + url.PathEscape(b.String())
+}
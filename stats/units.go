@@ -0,0 +1,42 @@
+package stats
+
+import (
+	"strconv"
+	"time"
+)
+
+// HumanDuration пишет длительность для подписи на языке l: «40 с»,
+// «3 мин 20 с», «4 ч 23 мин», «почти двое суток», «12 дней 5 ч»
+func HumanDuration(l string, d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return textIn(l, "duration.seconds", int(d.Seconds()))
+	case d < 10*time.Minute:
+		return joinUnits(l, int(d/time.Minute), "minutes", int(d%time.Minute/time.Second), "seconds")
+	case d < time.Hour:
+		return textIn(l, "duration.minutes", int(d.Round(time.Minute)/time.Minute))
+	}
+
+	day := 24 * time.Hour
+	days := float64(d) / float64(day)
+	// без малого целые сутки звучат лучше, чем «1 день 22 ч»
+	if key := "duration.almost." + strconv.Itoa(int(days)+1); days-float64(int(days)) >= 0.85 && hasTextIn(l, key) {
+		return textIn(l, key, nil)
+	}
+	if d < day {
+		d = d.Round(time.Minute)
+		return joinUnits(l, int(d/time.Hour), "hours", int(d%time.Hour/time.Minute), "minutes")
+	}
+	d = d.Round(time.Hour)
+	return joinUnits(l, int(d/day), "days", int(d%day/time.Hour), "hours")
+}
+
+// joinUnits — «4 ч 23 мин»; нулевая младшая часть опускается
+func joinUnits(l string, big int, bigUnit string, small int, smallUnit string) string {
+	s := textIn(l, "duration."+bigUnit, big)
+	if small > 0 {
+		s += " " + textIn(l, "duration."+smallUnit, small)
+	}
+	return s
+}
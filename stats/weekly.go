@@ -0,0 +1,94 @@
+package stats
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// mondayFirst — номер дня недели с понедельника: 0 — понедельник, 6 — воскресенье
+func mondayFirst(d time.Weekday) int {
+	return (int(d) + 6) % 7
+}
+
+// weekdayName — «вторник» по номеру с понедельника
+func (s *Settings) weekdayName(row int) string {
+	return s.text("weekdays."+strconv.Itoa((row+1)%7), nil)
+}
+
+// weeklyRhythm — «Когда чат живёт»: сообщения по часам и дням недели
+// тепловой картой 24×7, главное число — самый людный час недели
+func weeklyRhythm(s *Settings) Accumulator {
+	var week [7][24]int
+	return accFunc{
+		add: func(m telegram.Message) {
+			week[mondayFirst(m.Date.Weekday())][m.Date.Hour()]++
+		},
+		result: func() (Nomination, bool) {
+			peakDay, peakHour, active, total := 0, 0, 0, 0
+			for day, hours := range week {
+				for hour, n := range hours {
+					if n > 0 {
+						active++
+					}
+					if n > week[peakDay][peakHour] {
+						peakDay, peakHour = day, hour
+					}
+					total += n
+				}
+			}
+			if active < 2 {
+				return Nomination{}, false
+			}
+			d := s.newTextData("")
+			d.Count, d.Total = week[peakDay][peakHour], total
+			d.Value = s.hourSlot(peakDay, peakHour)
+			nom := s.card("weeklyRhythm", d)
+			nom.Avatar = s.avatars.Common()
+			nom.Chart = s.weekChart(&week)
+			return nom, true
+		},
+	}
+}
+
+// hourSlot — «вторник, 23:00»
+func (s *Settings) hourSlot(day, hour int) string {
+	return fmt.Sprintf("%s, %02d:00", s.weekdayName(day), hour)
+}
+
+// weekChart рисует тепловую карту недели: строка — день с понедельника,
+// столбец — час. Подсказка у клетки — точное число сообщений, самый
+// людный час выделен.
+func (s *Settings) weekChart(week *[7][24]int) string {
+	const cell, step, left, top = 17, 19, 24, 18
+	w, h := left+24*step, top+7*step
+	highest := 0
+	for _, hours := range week {
+		for _, v := range hours {
+			highest = max(highest, v)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="10" fill="#9aa4c8">`, w, h, w, h)
+	for hour := 0; hour < 24; hour += 3 {
+		fmt.Fprintf(&b, `<text x="%d" y="%d">%02d</text>`, left+hour*step+3, top-6, hour)
+	}
+	for day, hours := range week {
+		fmt.Fprintf(&b, `<text x="0" y="%d">%s</text>`, top+day*step+cell-4,
+			html.EscapeString(s.text("weekdaysShort."+strconv.Itoa((day+1)%7), nil)))
+		for hour, v := range hours {
+			d := s.newTextData("")
+			d.Count, d.Value = v, s.hourSlot(day, hour)
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="3" %s><title>%s</title></rect>`,
+				left+hour*step, top+day*step, cell, cell, heatFill(v, highest), html.EscapeString(s.text("nominations.weeklyRhythm.cell", d)))
+		}
+	}
+	b.WriteString(`</svg>`)
+	return "data:image/svg+xml;utf8," + url.PathEscape(b.String())
+}
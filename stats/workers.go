@@ -0,0 +1,170 @@
+package stats
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bebroedik/year-summary-2025/telegram"
+)
+
+// Timing — сколько считалась номинация. У коллекторов это их доля общего
+// прохода (оценка по каждому timingSample-му сообщению) плюс Result.
+type Timing struct {
+	Name     string
+	Duration time.Duration
+}
+
+// TakeTimings возвращает и забывает время номинаций, посчитанных с этими
+// настройками с прошлого вызова, от самых медленных
+func (s *Settings) TakeTimings() []Timing {
+	s.timingsMu.Lock()
+	defer s.timingsMu.Unlock()
+	list := s.timings
+	s.timings = nil
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Duration > list[j].Duration })
+	return list
+}
+
+// timingSample — время Add меряется на каждом timingSample-м сообщении:
+// time.Now на каждом стоил бы больше самих накопителей
+const timingSample = 256
+
+type computed struct {
+	nom  Nomination
+	ok   bool
+	took time.Duration
+}
+
+// computeAll считает номинации list пулом из Options.Workers горутин. Коллекторы
+// и накопители делятся между проходами — по одному на горутину, так что
+// проходов по сообщениям не больше, чем горутин; остальные номинации —
+// отдельные задачи. Результаты — в порядке list.
+func computeAll(ctx context.Context, s *Settings, list []Nominator, msg []telegram.Message) ([]computed, error) {
+	workers := s.workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	res := make([]computed, len(list))
+	cols := make([]Collector, len(list))
+
+	passes := make([][]int, workers)
+	var single []int
+	streaming := 0
+	for i, n := range list {
+		if cols[i] = collectorOf(s, n); cols[i] != nil {
+			passes[streaming%workers] = append(passes[streaming%workers], i)
+			streaming++
+			continue
+		}
+		single = append(single, i)
+	}
+
+	var sv []telegram.Message // service-сообщения чатов msg, одни на все проходы
+	if streaming > 0 {
+		sv = s.serviceOf(msg)
+	}
+	var jobs []func() error
+	for _, idx := range passes {
+		if len(idx) > 0 {
+			idx := idx
+			jobs = append(jobs, func() error { return pass(ctx, msg, sv, idx, cols, res) })
+		}
+	}
+	for _, i := range single {
+		i := i
+		jobs = append(jobs, func() error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			start := time.Now()
+			res[i].nom, res[i].ok = list[i].Compute(s, msg)
+			res[i].took = time.Since(start)
+			return nil
+		})
+	}
+
+	work := make(chan func() error)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range work {
+				if err := job(); err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+				}
+			}
+		}()
+	}
+	for _, job := range jobs {
+		work <- job
+	}
+	close(work)
+	wg.Wait()
+	select {
+	case err := <-errs:
+		return nil, err
+	default:
+	}
+
+	s.timingsMu.Lock()
+	for i, n := range list {
+		s.timings = append(s.timings, Timing{Name: n.Name(), Duration: res[i].took})
+	}
+	s.timingsMu.Unlock()
+	return res, nil
+}
+
+// pass — общий проход по сообщениям для коллекторов idx: своя шина, на
+// которую подписаны они все, потом service-сообщения sv, если на них
+// кто-то подписан. Отмену проверяем не на каждом сообщении, а раз в
+// accumulateCheck.
+func pass(ctx context.Context, msg, sv []telegram.Message, idx []int, cols []Collector, res []computed) error {
+	var b Bus
+	for k, i := range idx {
+		b.owner = k
+		cols[i].Subscribe(&b)
+	}
+	took := make([]time.Duration, len(idx))
+	publish := func(list []telegram.Message) error {
+		for j := range list {
+			if j%accumulateCheck == 0 {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+			}
+			if j%timingSample == 0 {
+				b.timing = took
+				b.Publish(&list[j])
+				b.timing = nil
+				continue
+			}
+			b.Publish(&list[j])
+		}
+		return nil
+	}
+	if err := publish(msg); err != nil {
+		return err
+	}
+	if b.has(ServiceAction) {
+		if err := publish(sv); err != nil {
+			return err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for k, i := range idx {
+		start := time.Now()
+		res[i].nom, res[i].ok = cols[i].Result()
+		res[i].took = took[k]*timingSample + time.Since(start)
+	}
+	return nil
+}
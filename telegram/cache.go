@@ -0,0 +1,98 @@
+package telegram
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// cacheVersion меняется вместе с Message, чтобы старый кэш не читался
+const cacheVersion = 11
+
+// Cache — разобранные экспорты на диске. Каждый файл разбирается один раз:
+// при повторном запуске, в том числе после Ctrl+C посреди нескольких
+// больших экспортов, уже разобранные файлы берутся из кэша. Запись в кэше
+// привязана к пути, размеру и времени изменения файла.
+type Cache struct {
+	Dir string
+}
+
+// path — файл кэша для экспорта; папки (Slack, VK) не кэшируются: по времени
+// изменения папки не видно правок внутри
+func (c *Cache) path(file, format string) (string, bool) {
+	st, err := os.Stat(file)
+	if err != nil || !st.Mode().IsRegular() {
+		return "", false
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", false
+	}
+	key := fmt.Sprintf("%d\x00%s\x00%d\x00%d\x00%s", cacheVersion, abs, st.Size(), st.ModTime().UnixNano(), format)
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:16])+".gob"), true
+}
+
+// ReadFile — как telegram.ReadFile, но сначала смотрит в кэш
+func (c *Cache) ReadFile(ctx context.Context, fileName, format string) (*ChatExport, error) {
+	path, ok := c.path(fileName, format)
+	if !ok {
+		return ReadFile(ctx, fileName, format)
+	}
+	if export, err := readCached(path); err == nil {
+		return export, nil
+	}
+
+	export, err := ReadFile(ctx, fileName, format)
+	if err != nil {
+		return nil, err
+	}
+	// кэш только ускоряет: не записался — прочитаем заново в следующий раз
+	_ = writeCached(path, export)
+	return export, nil
+}
+
+// ReadExports — как telegram.ReadExports, но через кэш
+func (c *Cache) ReadExports(ctx context.Context, files []string, format string) ([]Message, []FileReport, error) {
+	return readExports(ctx, files, format, c.ReadFile)
+}
+
+func readCached(path string) (*ChatExport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var export ChatExport
+	if err := gob.NewDecoder(f).Decode(&export); err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// writeCached пишет во временный файл и переименовывает, чтобы прерванная
+// запись не оставила битый кэш; в кэше переписка, поэтому права только владельцу
+func writeCached(path string, export *ChatExport) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := gob.NewEncoder(tmp).Encode(export); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
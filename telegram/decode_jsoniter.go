@@ -0,0 +1,12 @@
+//go:build jsoniter
+
+package telegram
+
+import jsoniter "github.com/json-iterator/go"
+
+// json-iterator вместо encoding/json для сообщений: go build -tags jsoniter,
+// потом -json-decoder jsoniter. Битое сообщение он не всегда отличает от
+// обрезанного файла так же, как encoding/json, поэтому по умолчанию не включён.
+func init() {
+	decoders["jsoniter"] = decoder{export: scanExport, unmarshal: jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal}
+}
@@ -0,0 +1,182 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Формат DiscordChatExporter (JSON): https://github.com/Tyrrrz/DiscordChatExporter
+
+type discordExport struct {
+	Guild struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"guild"`
+	Channel struct {
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		Category string `json:"category"`
+	} `json:"channel"`
+	Messages []discordMessage `json:"messages"`
+}
+
+type discordUser struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Nickname string `json:"nickname"`
+	IsBot    bool   `json:"isBot"`
+}
+
+type discordMessage struct {
+	ID          string              `json:"id"`
+	Type        string              `json:"type"` // Default, Reply, GuildMemberJoin, ...
+	Timestamp   time.Time           `json:"timestamp"`
+	Content     string              `json:"content"`
+	Author      discordUser         `json:"author"`
+	Attachments []discordAttachment `json:"attachments"`
+	Stickers    []struct {
+		Name string `json:"name"`
+	} `json:"stickers"`
+	Reactions []discordReaction `json:"reactions"`
+	Mentions  []discordUser     `json:"mentions"`
+	Reference *struct {
+		MessageID string `json:"messageId"`
+	} `json:"reference"` // у ответов: на какое сообщение
+}
+
+type discordAttachment struct {
+	URL      string `json:"url"`
+	FileName string `json:"fileName"`
+}
+
+type discordReaction struct {
+	Emoji struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"emoji"`
+	Count int           `json:"count"`
+	Users []discordUser `json:"users"`
+}
+
+// isDiscordExport узнаёт DiscordChatExporter по ключам guild и channel в начале файла
+func isDiscordExport(data []byte) bool {
+	head := data[:min(len(data), 4096)]
+	return bytes.Contains(head, []byte(`"guild"`)) && bytes.Contains(head, []byte(`"channel"`))
+}
+
+type discordSource struct{}
+
+func (discordSource) Identify(path string) bool { return isDiscordExport(sniff(path)) }
+
+func (discordSource) Load(ctx context.Context, path string) (<-chan Message, ChatInfo, error) {
+	return loadFileExport(ctx, path, decodeDiscord)
+}
+
+func decodeDiscord(data []byte) (*ChatExport, error) {
+	var in discordExport
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, err
+	}
+
+	export := &ChatExport{
+		Name: in.Guild.Name + " #" + in.Channel.Name,
+		Type: "discord_channel",
+	}
+	export.ID, _ = strconv.ParseInt(in.Channel.ID, 10, 64)
+
+	for _, dm := range in.Messages {
+		export.Messages = append(export.Messages, dm.toMessage())
+	}
+	export.Report.Parsed = len(export.Messages)
+	return export, nil
+}
+
+func discordUserID(id string) string { return "user" + id }
+
+func (u discordUser) displayName() string {
+	if u.Nickname != "" {
+		return u.Nickname
+	}
+	return u.Name
+}
+
+func (dm discordMessage) toMessage() Message {
+	m := Message{
+		Type:   "message",
+		Date:   dm.Timestamp,
+		From:   dm.Author.displayName(),
+		FromID: discordUserID(dm.Author.ID),
+		Text:   dm.Content,
+	}
+	m.ID, _ = strconv.ParseInt(dm.ID, 10, 64)
+	if dm.Reference != nil {
+		m.ReplyToMessageID, _ = strconv.ParseInt(dm.Reference.MessageID, 10, 64)
+	}
+
+	switch dm.Type {
+	case "Default", "Reply", "ThreadStarterMessage", "":
+	default:
+		// вход участника, закреп, звонок и т.п.
+		m.Type = "service"
+	}
+
+	if dm.Content != "" {
+		m.TextEntities = append(m.TextEntities, TextFragment{Type: "plain", Text: dm.Content})
+	}
+	for _, u := range dm.Mentions {
+		m.TextEntities = append(m.TextEntities, TextFragment{Type: "mention", Text: "@" + u.displayName()})
+	}
+
+	for _, a := range dm.Attachments {
+		mediaType, isPhoto := discordMediaType(a.FileName)
+		if isPhoto {
+			m.Photo = a.URL
+		} else if m.MediaType == "" {
+			m.MediaType = mediaType
+		}
+	}
+	if len(dm.Stickers) > 0 {
+		m.MediaType = "sticker"
+	}
+
+	for _, r := range dm.Reactions {
+		reaction := Reaction{Emoji: r.Emoji.Name, Count: r.Count, Type: "emoji"}
+		if r.Emoji.ID != "" {
+			reaction.Type = "custom_emoji"
+		}
+		for _, u := range r.Users {
+			reaction.Recent = append(reaction.Recent, ReactionUser{
+				From:   u.displayName(),
+				FromID: discordUserID(u.ID),
+			})
+		}
+		m.Reactions = append(m.Reactions, reaction)
+	}
+
+	return m
+}
+
+// discordMediaType переводит вложение в media_type Telegram; фото идут в Photo
+func discordMediaType(fileName string) (mediaType string, isPhoto bool) {
+	name := strings.ToLower(fileName)
+	switch path.Ext(name) {
+	case ".jpg", ".jpeg", ".png", ".webp":
+		return "", true
+	case ".gif":
+		return "animation", false
+	case ".mp4", ".mov", ".webm":
+		return "video_file", false
+	case ".ogg", ".mp3", ".m4a", ".wav":
+		// голосовые сообщения Discord всегда называются voice-message.ogg
+		if strings.HasPrefix(name, "voice-message") {
+			return "voice_message", false
+		}
+		return "audio_file", false
+	}
+	return "file", false
+}
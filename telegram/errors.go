@@ -0,0 +1,53 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrEmptyExport — в экспорте не нашлось ни одного читаемого сообщения
+var ErrEmptyExport = errors.New("no messages could be parsed")
+
+// ParseError — экспорт не читается. Index и ID показывают, на каком
+// сообщении остановились, чтобы человеку можно было сказать «сообщение
+// №1204 (id 5581) битое», а не «unexpected EOF».
+type ParseError struct {
+	File   string // путь к экспорту; заполняет ReadFile
+	Index  int    // номер сообщения в messages, с 1; 0 — ошибка не в сообщении
+	ID     int64  // id сообщения, если его удалось прочитать
+	Offset int64  // байт, на котором чтение остановилось
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	if e.File != "" {
+		b.WriteString(e.File + ": ")
+	}
+	if e.Index > 0 {
+		fmt.Fprintf(&b, "message #%d", e.Index)
+		if e.ID != 0 {
+			fmt.Fprintf(&b, " (id %d)", e.ID)
+		}
+		b.WriteString(": ")
+	} else if e.ID != 0 {
+		fmt.Fprintf(&b, "message %d: ", e.ID)
+	}
+	b.WriteString(e.Err.Error())
+	if e.Offset > 0 {
+		fmt.Fprintf(&b, " (stopped at byte %d)", e.Offset)
+	}
+	return b.String()
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// asParseError оборачивает ошибку разбора в ParseError, если она ещё не он
+func asParseError(err error) *ParseError {
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		return pe
+	}
+	return &ParseError{Err: err}
+}
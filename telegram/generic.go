@@ -0,0 +1,280 @@
+package telegram
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Общий формат для всего, чего нет среди встроенных: его легко получить
+// скриптом из любой выгрузки. CSV с заголовком или JSON Lines с полями
+//
+//	timestamp    RFC 3339 или unix-время в секундах (миллисекундах)
+//	sender_id    постоянный id участника, по нему же конфиг и картинки
+//	sender_name  имя для подписей
+//	text         текст; @ник в тексте считается упоминанием
+//	quote        цитата, на которую отвечает сообщение; в text не входит
+//	media_type   пусто для текста, иначе photo, video_file, video_message,
+//	             voice_message, audio_file, sticker, animation, file
+//	reactions    CSV: «👍:3;❤:1»; JSON: [{"emoji": "👍", "count": 3, "sender_ids": ["a"]}]
+//
+// Обязательны timestamp и sender_id. Формат выбирается -format generic или
+// узнаётся по заголовку/ключам timestamp и sender_id.
+
+var genericColumns = []string{"timestamp", "sender_id", "sender_name", "text", "quote", "media_type", "reactions"}
+
+type genericRecord struct {
+	Timestamp  json.RawMessage   `json:"timestamp"`
+	SenderID   string            `json:"sender_id"`
+	SenderName string            `json:"sender_name"`
+	Text       string            `json:"text"`
+	Quote      string            `json:"quote"`
+	MediaType  string            `json:"media_type"`
+	Reactions  []genericReaction `json:"reactions"`
+}
+
+type genericReaction struct {
+	Emoji     string   `json:"emoji"`
+	Count     int      `json:"count"`
+	SenderIDs []string `json:"sender_ids"`
+}
+
+type genericSource struct{}
+
+func (genericSource) Identify(path string) bool {
+	head := sniff(path)
+	if bytes.HasPrefix(bytes.TrimSpace(head), []byte("{")) {
+		return bytes.Contains(head, []byte(`"sender_id"`)) && bytes.Contains(head, []byte(`"timestamp"`))
+	}
+	line, _, _ := bytes.Cut(head, []byte("\n"))
+	return bytes.Contains(line, []byte("timestamp")) && bytes.Contains(line, []byte("sender_id"))
+}
+
+func (genericSource) Load(ctx context.Context, path string) (<-chan Message, ChatInfo, error) {
+	return loadFileExport(ctx, path, decodeGeneric)
+}
+
+func decodeGeneric(data []byte) (*ChatExport, error) {
+	var records []genericRecord
+	export := &ChatExport{Type: "generic"}
+
+	var err error
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		records, err = readGenericJSONL(data, &export.Report)
+	} else {
+		records, err = readGenericCSV(data, &export.Report)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var bad *ParseError
+	for i, r := range records {
+		m, err := r.toMessage()
+		if err != nil {
+			export.Report.Lost++
+			if bad == nil {
+				bad = &ParseError{Index: i + 1, Err: err}
+			}
+			continue
+		}
+		export.Messages = append(export.Messages, m)
+	}
+	if len(export.Messages) == 0 {
+		if bad != nil {
+			bad.Err = fmt.Errorf("%w: %w", ErrEmptyExport, bad.Err)
+			return nil, bad
+		}
+		return nil, &ParseError{Err: ErrEmptyExport}
+	}
+
+	sort.SliceStable(export.Messages, func(i, j int) bool {
+		return export.Messages[i].Date.Before(export.Messages[j].Date)
+	})
+	for i := range export.Messages {
+		export.Messages[i].ID = int64(i + 1)
+	}
+	export.Report.Parsed = len(export.Messages)
+	return export, nil
+}
+
+func readGenericJSONL(data []byte, report *ParseReport) ([]genericRecord, error) {
+	var records []genericRecord
+	s := bufio.NewScanner(bytes.NewReader(data))
+	s.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for s.Scan() {
+		line := bytes.TrimSpace(s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var r genericRecord
+		if err := json.Unmarshal(line, &r); err != nil {
+			report.Lost++
+			continue
+		}
+		records = append(records, r)
+	}
+	return records, s.Err()
+}
+
+func readGenericCSV(data []byte, report *ParseReport) ([]genericRecord, error) {
+	rd := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	rd.FieldsPerRecord = -1
+
+	header, err := rd.Read()
+	if err != nil {
+		return nil, &ParseError{Err: fmt.Errorf("read header: %w", err)}
+	}
+	col := map[string]int{}
+	for i, name := range header {
+		col[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range genericColumns[:2] {
+		if _, ok := col[name]; !ok {
+			return nil, &ParseError{Err: fmt.Errorf("no %q column, need %s", name, strings.Join(genericColumns, ","))}
+		}
+	}
+
+	field := func(row []string, name string) string {
+		if i, ok := col[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	var records []genericRecord
+	for {
+		row, err := rd.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			report.Lost++
+			continue
+		}
+		ts, _ := json.Marshal(field(row, "timestamp"))
+		r := genericRecord{
+			Timestamp:  ts,
+			SenderID:   field(row, "sender_id"),
+			SenderName: field(row, "sender_name"),
+			Text:       field(row, "text"),
+			Quote:      field(row, "quote"),
+			MediaType:  field(row, "media_type"),
+		}
+		if r.Reactions, err = parseGenericReactions(field(row, "reactions")); err != nil {
+			report.Lost++
+			continue
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// parseGenericReactions разбирает «👍:3;❤:1»
+func parseGenericReactions(s string) ([]genericReaction, error) {
+	var list []genericReaction
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		i := strings.LastIndex(part, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("reaction %q: want emoji:count", part)
+		}
+		n, err := strconv.Atoi(part[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("reaction %q: %w", part, err)
+		}
+		list = append(list, genericReaction{Emoji: part[:i], Count: n})
+	}
+	return list, nil
+}
+
+var genericMentionRe = regexp.MustCompile(`@[\p{L}\p{N}_]+`)
+
+func (r genericRecord) toMessage() (Message, error) {
+	date, wallClock, err := parseGenericTime(r.Timestamp)
+	if err != nil {
+		return Message{}, err
+	}
+	if r.SenderID == "" {
+		return Message{}, errors.New("no sender_id")
+	}
+
+	m := Message{
+		Type:      "message",
+		Date:      date,
+		WallClock: wallClock,
+		From:      r.SenderName,
+		FromID:    r.SenderID,
+		Text:      r.Text,
+		Quote:     r.Quote,
+	}
+	if m.From == "" {
+		m.From = m.FromID
+	}
+
+	switch r.MediaType {
+	case "", "text":
+	case "photo":
+		m.Photo = "(omitted)"
+	default:
+		m.MediaType = r.MediaType
+	}
+
+	if m.Text != "" {
+		m.TextEntities = append(m.TextEntities, TextFragment{Type: "plain", Text: m.Text})
+	}
+	for _, mention := range genericMentionRe.FindAllString(m.Text, -1) {
+		m.TextEntities = append(m.TextEntities, TextFragment{Type: "mention", Text: mention})
+	}
+
+	for _, gr := range r.Reactions {
+		reaction := Reaction{Emoji: gr.Emoji, Count: gr.Count, Type: "emoji"}
+		for _, id := range gr.SenderIDs {
+			reaction.Recent = append(reaction.Recent, ReactionUser{From: id, FromID: id})
+		}
+		if reaction.Count < len(reaction.Recent) {
+			reaction.Count = len(reaction.Recent)
+		}
+		m.Reactions = append(m.Reactions, reaction)
+	}
+	return m, nil
+}
+
+// parseGenericTime: "2025-01-01T12:00:00Z", 1735732800 или "1735732800000";
+// wallClock — время без пояса, "2025-01-01 12:00:00" (см. Message.WallClock)
+func parseGenericTime(raw json.RawMessage) (t time.Time, wallClock bool, err error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		s = string(raw) // число без кавычек
+	}
+	s = strings.TrimSpace(s)
+
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if n > 1e12 {
+			return time.UnixMilli(n).UTC(), false, nil
+		}
+		return time.Unix(n, 0).UTC(), false, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("bad timestamp %q", s)
+}
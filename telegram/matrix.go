@@ -0,0 +1,213 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Экспорт комнаты Matrix из Element (Export chat → JSON):
+//
+//	{"room_name": "...", "messages": [
+//	  {"type": "m.room.message", "event_id": "$a", "sender": "@anna:matrix.org",
+//	   "origin_server_ts": 1735689600000, "content": {"msgtype": "m.text", "body": "привет"}},
+//	  {"type": "m.reaction", "sender": "@bob:matrix.org",
+//	   "content": {"m.relates_to": {"rel_type": "m.annotation", "event_id": "$a", "key": "👍"}}}
+//	]}
+//
+// Имена участников — из событий m.room.member, иначе локальная часть адреса.
+
+type matrixExport struct {
+	RoomName string        `json:"room_name"`
+	Messages []matrixEvent `json:"messages"`
+}
+
+type matrixEvent struct {
+	Type     string `json:"type"`
+	EventID  string `json:"event_id"`
+	Sender   string `json:"sender"`
+	TS       int64  `json:"origin_server_ts"`
+	StateKey string `json:"state_key"`
+	Content  struct {
+		MsgType     string `json:"msgtype"`
+		Body        string `json:"body"`
+		Membership  string `json:"membership"`
+		DisplayName string `json:"displayname"`
+		RelatesTo   struct {
+			RelType   string `json:"rel_type"`
+			EventID   string `json:"event_id"`
+			Key       string `json:"key"`
+			InReplyTo *struct {
+				EventID string `json:"event_id"`
+			} `json:"m.in_reply_to"`
+		} `json:"m.relates_to"`
+		Mentions struct {
+			UserIDs []string `json:"user_ids"`
+		} `json:"m.mentions"`
+		Voice json.RawMessage `json:"org.matrix.msc3245.voice"`
+	} `json:"content"`
+	Unsigned struct {
+		RedactedBecause json.RawMessage `json:"redacted_because"`
+	} `json:"unsigned"`
+}
+
+type matrixSource struct{}
+
+func (matrixSource) Identify(path string) bool {
+	head := sniff(path)
+	return bytes.Contains(head, []byte(`"room_name"`)) || bytes.Contains(head, []byte(`"origin_server_ts"`))
+}
+
+func (matrixSource) Load(ctx context.Context, path string) (<-chan Message, ChatInfo, error) {
+	return loadFileExport(ctx, path, decodeMatrix)
+}
+
+func decodeMatrix(data []byte) (*ChatExport, error) {
+	var in matrixExport
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(in.Messages, func(i, j int) bool { return in.Messages[i].TS < in.Messages[j].TS })
+
+	names := map[string]string{}
+	for _, ev := range in.Messages {
+		if ev.Type == "m.room.member" && ev.Content.DisplayName != "" {
+			names[ev.StateKey] = ev.Content.DisplayName
+		}
+	}
+	name := func(mxid string) string {
+		if n, ok := names[mxid]; ok {
+			return n
+		}
+		local, _, _ := strings.Cut(strings.TrimPrefix(mxid, "@"), ":")
+		return local
+	}
+
+	export := &ChatExport{Name: in.RoomName, Type: "matrix_room"}
+	byEvent := map[string]int{} // event_id → индекс в export.Messages
+	var reactions []matrixEvent
+
+	for _, ev := range in.Messages {
+		if ev.Unsigned.RedactedBecause != nil {
+			continue // удалённое сообщение
+		}
+		switch ev.Type {
+		case "m.reaction":
+			reactions = append(reactions, ev)
+			continue
+		case "m.room.message", "m.sticker":
+			if ev.Content.RelatesTo.RelType == "m.replace" {
+				continue // правка уже посчитанного сообщения
+			}
+		}
+
+		m := ev.toMessage(name)
+		m.ID = int64(len(export.Messages) + 1)
+		if reply := ev.Content.RelatesTo.InReplyTo; reply != nil {
+			// ответ идёт позже исходного, так что тот уже пронумерован
+			if i, ok := byEvent[reply.EventID]; ok {
+				m.ReplyToMessageID = export.Messages[i].ID
+			}
+		}
+		byEvent[ev.EventID] = len(export.Messages)
+		export.Messages = append(export.Messages, m)
+	}
+
+	// реакции — отдельные события, привязываем их к сообщениям и группируем по эмодзи
+	for _, ev := range reactions {
+		rel := ev.Content.RelatesTo
+		i, ok := byEvent[rel.EventID]
+		if rel.RelType != "m.annotation" || !ok {
+			continue
+		}
+		m := &export.Messages[i]
+		j := 0
+		for j < len(m.Reactions) && m.Reactions[j].Emoji != rel.Key {
+			j++
+		}
+		if j == len(m.Reactions) {
+			m.Reactions = append(m.Reactions, Reaction{Emoji: rel.Key, Type: "emoji"})
+		}
+		m.Reactions[j].Count++
+		m.Reactions[j].Recent = append(m.Reactions[j].Recent, ReactionUser{
+			From:   name(ev.Sender),
+			FromID: ev.Sender,
+			Date:   time.UnixMilli(ev.TS).UTC().Format("2006-01-02T15:04:05"),
+		})
+	}
+
+	export.Report.Parsed = len(export.Messages)
+	return export, nil
+}
+
+func (ev matrixEvent) toMessage(name func(string) string) Message {
+	m := Message{
+		Type:   "message",
+		Date:   time.UnixMilli(ev.TS).UTC(),
+		From:   name(ev.Sender),
+		FromID: ev.Sender,
+	}
+
+	switch ev.Type {
+	case "m.room.message":
+	case "m.sticker":
+		m.MediaType = "sticker"
+		return m
+	default:
+		// вход в комнату, смена названия, шифрование и т.п.
+		m.Type = "service"
+		return m
+	}
+
+	switch ev.Content.MsgType {
+	case "m.image":
+		m.Photo = ev.Content.Body
+		return m
+	case "m.video":
+		m.MediaType = "video_file"
+		return m
+	case "m.audio":
+		m.MediaType = "audio_file"
+		if ev.Content.Voice != nil {
+			m.MediaType = "voice_message"
+		}
+		return m
+	case "m.file":
+		m.MediaType = "file"
+		return m
+	}
+
+	body := ev.Content.Body
+	if ev.Content.RelatesTo.InReplyTo != nil {
+		// в ответе тело начинается с цитаты: «> <@anna:matrix.org> текст», потом пустая строка
+		if quote, rest, ok := strings.Cut(body, "\n\n"); ok && strings.HasPrefix(body, "> ") {
+			body = rest
+			m.Quote = matrixQuote(quote)
+		}
+	}
+	m.Text = body
+	if m.Text != "" {
+		m.TextEntities = append(m.TextEntities, TextFragment{Type: "plain", Text: m.Text})
+	}
+	for _, id := range ev.Content.Mentions.UserIDs {
+		m.TextEntities = append(m.TextEntities, TextFragment{Type: "mention", Text: "@" + name(id)})
+	}
+	return m
+}
+
+// matrixQuote — текст цитаты без «> » в начале строк и без автора в первой
+func matrixQuote(quote string) string {
+	lines := strings.Split(quote, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimPrefix(strings.TrimPrefix(l, ">"), " ")
+	}
+	if first := lines[0]; strings.HasPrefix(first, "<") {
+		if _, rest, ok := strings.Cut(first, "> "); ok {
+			lines[0] = rest
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,364 @@
+// Package telegram читает экспорты чатов в общую модель сообщений.
+// Кроме result.json из Telegram Desktop понимает Discord, WhatsApp, Slack,
+// VK, Signal, Matrix и свой CSV/JSONL (см. Source); свои форматы
+// добавляются через RegisterSource.
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ChatExport — экспорт одного чата
+type ChatExport struct {
+	Name     string    `json:"name"`
+	Type     string    `json:"type"`
+	ID       int64     `json:"id"`
+	Messages []Message `json:"messages"`
+
+	Report ParseReport `json:"-"` // сколько сообщений удалось прочитать
+}
+
+// Message — сообщение в формате Telegram; остальные форматы приводятся к нему
+type Message struct {
+	ID   int64     `json:"id"`
+	Type string    `json:"type"` // "message", "service"
+	Date time.Time `json:"-"`
+	// Date — часы без пояса, как их пишет экспорт (date из result.json,
+	// WhatsApp, VK), подписанные UTC; false — настоящий момент времени:
+	// unix-время (date_unixtime, Slack, Signal, Matrix) или время со смещением
+	WallClock    bool           `json:"-"`
+	From         string         `json:"from,omitempty"`
+	FromID       string         `json:"from_id,omitempty"`
+	Text         string         `json:"-"`             // final parsed text
+	TextEntities []TextFragment `json:"text_entities"` // final parsed text
+	// ответ: id сообщения, на которое ответили
+	ReplyToMessageID int64 `json:"reply_to_message_id,omitempty"`
+	// цитата из него, если ответили на выделенный кусок (новые экспорты);
+	// в Text и TextEntities не входит, чтобы не считаться словами автора
+	Quote string `json:"-"`
+	// Edited           string    `json:"edited,omitempty"`
+	MediaType string `json:"media_type,omitempty"`
+	Photo     string `json:"photo,omitempty"`
+	// длительность голосовых и кружков
+	DurationSeconds int `json:"duration_seconds,omitempty"`
+	// размер вложения в байтах, если экспорт его пишет
+	FileSize int64 `json:"file_size,omitempty"`
+	// инлайн-бот, через которого отправлено сообщение: "@gif", "@pic", …
+	ViaBot string `json:"via_bot,omitempty"`
+	// ответ на сторис: id сторис, на которую ответили; текст ответа — обычный
+	ReplyToStoryID int64 `json:"reply_to_story_id,omitempty"`
+	// File            *File      `json:"file,omitempty"`
+	// Audio           *Audio     `json:"audio,omitempty"`
+	// Video           *Video     `json:"video,omitempty"`
+	// Sticker         *Sticker   `json:"sticker,omitempty"`
+	// Contact         *Contact   `json:"contact,omitempty"`
+	// Location        *Location  `json:"location,omitempty"`
+	// Poll            *Poll      `json:"poll,omitempty"`
+	ForwardedFrom string `json:"forwarded_from,omitempty"`
+	// "channel…" у репостов из каналов, "user…" у пересылок от людей;
+	// в старых экспортах его нет
+	ForwardedFromID string     `json:"forwarded_from_id,omitempty"`
+	Reactions       []Reaction `json:"reactions,omitempty"`
+
+	// только у service: кто и что сделал с чатом
+	Actor   string   `json:"actor,omitempty"`
+	ActorID string   `json:"actor_id,omitempty"`
+	Action  string   `json:"action,omitempty"`  // "invite_members", "remove_members", "join_group_by_link", "edit_group_title", …
+	Title   string   `json:"title,omitempty"`   // новое название чата
+	Members []string `json:"members,omitempty"` // кого добавили или удалили
+	Boosts  int      `json:"boosts,omitempty"`  // у "boost_apply": сколько бустов применил actor
+
+	Chat string `json:"-"` // из какого чата сообщение, если экспортов несколько
+}
+
+// ChatSenderID — from_id сообщений «от имени чата»: так пишут анонимные
+// админы группы. В экспорте у них from_id чата ("channel<id>") или нет
+// from_id вовсе; все такие сообщения собираются под этим id.
+const ChatSenderID = "chat"
+
+// markChatSenders переносит сообщения анонимных админов на ChatSenderID.
+// В каналах от имени канала пишется всё, там ничего не трогаем.
+func markChatSenders(export *ChatExport) {
+	if strings.HasSuffix(export.Type, "_channel") {
+		return
+	}
+	chatID := "channel" + strconv.FormatInt(export.ID, 10)
+	for i, m := range export.Messages {
+		if m.Type != "message" || (m.FromID != "" && m.FromID != chatID) {
+			continue
+		}
+		export.Messages[i].FromID = ChatSenderID
+		if m.From == "" {
+			export.Messages[i].From = export.Name
+		}
+	}
+}
+
+// parts of composite text
+type TextFragment struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (m *Message) UnmarshalJSON(data []byte) error {
+	// alias to prevent recursion
+	type alias Message
+	aux := &struct {
+		Text    textField `json:"text"`
+		Quote   textField `json:"quote"`
+		RawDate string    `json:"date"`
+		RawUnix string    `json:"date_unixtime"`
+		File    string    `json:"file"`
+		Mime    string    `json:"mime_type"`
+		Story   presence  `json:"story"`
+
+		Giveaway        presence `json:"giveaway_information"`
+		GiveawayResults presence `json:"giveaway_results"`
+
+		*alias
+	}{
+		alias: (*alias)(m),
+	}
+
+	if err := unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	t, err := time.Parse("2006-01-02T15:04:05", aux.RawDate)
+	if err != nil {
+		// запасной вариант — unix-время, оно есть в новых экспортах; в UTC,
+		// чтобы результат не зависел от часового пояса машины
+		sec, uerr := strconv.ParseInt(aux.RawUnix, 10, 64)
+		if uerr != nil {
+			return &ParseError{ID: m.ID, Err: fmt.Errorf("bad date %q: %w", aux.RawDate, err)}
+		}
+		t = time.Unix(sec, 0).UTC()
+	}
+	m.Date, m.WallClock = t, err == nil
+
+	m.Text = string(aux.Text)
+	m.Quote = string(aux.Quote)
+	if m.ReplyToMessageID != 0 && m.Quote == "" {
+		splitQuote(m)
+	}
+	if m.MediaType == "" {
+		m.MediaType = inlineMediaType(m.ViaBot, aux.File, aux.Mime)
+	}
+	// пересланная сторис и розыгрыши (2024+) в новых экспортах — объект
+	// без текста и без media_type; без этого они выглядели бы пустыми
+	// сообщениями
+	switch {
+	case m.MediaType != "":
+	case bool(aux.Story):
+		m.MediaType = MediaStory
+	case bool(aux.Giveaway):
+		m.MediaType = MediaGiveaway
+	case bool(aux.GiveawayResults):
+		m.MediaType = MediaGiveawayResults
+	}
+	return nil
+}
+
+// splitQuote отделяет цитату, которую клиент вставил в начало ответа
+// разметкой blockquote: «> что ответили» и дальше свой текст
+func splitQuote(m *Message) {
+	if len(m.TextEntities) < 2 || m.TextEntities[0].Type != "blockquote" {
+		return
+	}
+	quote := m.TextEntities[0].Text
+	rest, ok := strings.CutPrefix(m.Text, quote)
+	if !ok {
+		return
+	}
+	m.Quote = quote
+	m.Text = strings.TrimLeft(rest, " \t\r\n")
+	m.TextEntities = m.TextEntities[1:]
+}
+
+// media_type вложений, которые экспорт пишет отдельным объектом
+const (
+	MediaStory           = "story"            // пересланная сторис
+	MediaGiveaway        = "giveaway"         // объявление розыгрыша
+	MediaGiveawayResults = "giveaway_results" // итоги розыгрыша
+)
+
+// textField — text сообщения: склеивается прямо при разборе, без копии
+// сырого JSON
+type textField string
+
+func (t *textField) UnmarshalJSON(data []byte) error {
+	*t = textField(flattenText(data))
+	return nil
+}
+
+// presence — есть ли в сообщении объект; сам объект не нужен и не копируется
+type presence bool
+
+func (p *presence) UnmarshalJSON(data []byte) error {
+	*p = string(data) != "null"
+	return nil
+}
+
+// gifBots — инлайн-боты, которые присылают гифки роликами mp4
+var gifBots = map[string]bool{"@gif": true, "@gifs": true, "@giphy": true, "@tenor": true}
+
+// inlineMediaType — тип вложения без media_type. Результаты инлайн-ботов
+// приходят просто файлом: гифка от @gif — это mp4 без media_type, и без
+// этого она считалась бы текстом подписи.
+func inlineMediaType(viaBot, file, mime string) string {
+	if file == "" && mime == "" {
+		return ""
+	}
+	switch {
+	case mime == "image/gif", strings.HasSuffix(strings.ToLower(file), ".gif"):
+		return "animation"
+	case gifBots[strings.ToLower(viaBot)] && strings.HasPrefix(mime, "video/"):
+		return "animation"
+	}
+	return ""
+}
+
+// UnmarshalJSON терпит text в виде строки, массива или чего угодно ещё
+func (f *TextFragment) UnmarshalJSON(data []byte) error {
+	// фрагмент-строка без обёртки — чаще всего в массиве text
+	if d := bytes.TrimLeft(data, " \t\r\n"); len(d) > 0 && (d[0] == '"' || d[0] == '[') {
+		f.Type, f.Text = "plain", flattenText(d)
+		return nil
+	}
+	var aux struct {
+		Type string          `json:"type"`
+		Text json.RawMessage `json:"text"`
+	}
+	if err := unmarshal(data, &aux); err != nil {
+		// фрагмент-строка без обёртки
+		f.Type, f.Text = "plain", flattenText(data)
+		return nil
+	}
+	f.Type = aux.Type
+	f.Text = flattenText(aux.Text)
+	return nil
+}
+
+// flattenText склеивает text из экспорта в строку:
+// "строка", [ "строка", {"type": ..., "text": ...}, [вложенный массив] ].
+// Вид узнаётся по первому байту, так что каждый кусок разбирается один раз.
+func flattenText(raw json.RawMessage) string {
+	raw = bytes.TrimLeft(raw, " \t\r\n")
+	if len(raw) == 0 {
+		return ""
+	}
+
+	switch raw[0] {
+	case '"':
+		// 1) TEXT = "string"
+		return unquote(raw)
+	case '[':
+		// 2) TEXT = array (mixed types): строки и объекты разбирает TextFragment
+		var arr []TextFragment
+		if err := unmarshal(raw, &arr); err != nil {
+			return ""
+		}
+		var out strings.Builder
+		for _, f := range arr {
+			out.WriteString(f.Text)
+		}
+		return out.String()
+	case '{':
+		// 3) item = { "type": "...", "text": ... }
+		var obj struct {
+			Text json.RawMessage `json:"text"`
+		}
+		if err := unmarshal(raw, &obj); err == nil {
+			return flattenText(obj.Text)
+		}
+	}
+
+	// unknown but non-critical — treat as empty text
+	return ""
+}
+
+// unquote — JSON-строка без экранирования берётся как есть, без декодера
+func unquote(raw []byte) string {
+	if len(raw) >= 2 && raw[len(raw)-1] == '"' {
+		if body := raw[1 : len(raw)-1]; bytes.IndexByte(body, '\\') < 0 && utf8.Valid(body) {
+			return string(body)
+		}
+	}
+	var s string
+	if err := unmarshal(raw, &s); err != nil {
+		return ""
+	}
+	return s
+}
+
+type Photo struct {
+	File      string `json:"file"`
+	Thumbnail string `json:"thumbnail,omitempty"`
+	Width     int    `json:"width,omitempty"`
+	Height    int    `json:"height,omitempty"`
+}
+
+type File struct {
+	FileName string `json:"file_name"`
+	FileSize int64  `json:"file_size"`
+	MimeType string `json:"mime_type"`
+}
+
+type Audio struct {
+	FileName  string `json:"file_name"`
+	Duration  int    `json:"duration"`
+	Performer string `json:"performer,omitempty"`
+	Title     string `json:"title,omitempty"`
+}
+
+type Video struct {
+	FileName string `json:"file_name"`
+	Duration int    `json:"duration"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+}
+
+type Sticker struct {
+	Emoji string `json:"emoji"`
+	File  string `json:"file"`
+}
+
+type Contact struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name,omitempty"`
+	Phone     string `json:"phone_number"`
+}
+
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type Poll struct {
+	Question string       `json:"question"`
+	Answers  []PollAnswer `json:"answers"`
+}
+
+type PollAnswer struct {
+	Text   string `json:"text"`
+	Voters int    `json:"voters"`
+}
+
+type Reaction struct {
+	Emoji  string         `json:"emoji"`  // сам эмодзи
+	Count  int            `json:"count"`  // сколько всего таких реакций на сообщении
+	Type   string         `json:"type"`   // "emoji", "custom_emoji" (только с Premium), "paid"
+	Recent []ReactionUser `json:"recent"` // кто ставил реакцию недавно
+}
+
+type ReactionUser struct {
+	From   string `json:"from"`    // имя пользователя, который поставил реакцию
+	FromID string `json:"from_id"` // id пользователя
+	Date   string `json:"date"`    // дата реакции
+}
@@ -0,0 +1,194 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ParseReport — итог чтения экспорта: сколько сообщений прочитано и
+// сколько потеряно из-за битых записей или обрезанного файла
+type ParseReport struct {
+	Parsed    int   // прочитано сообщений
+	Lost      int   // битые сообщения + оценка числа сообщений в обрезанном хвосте
+	Truncated bool  // файл оборвался посреди messages
+	Offset    int64 // байт, на котором чтение остановилось
+
+	Unknown []UnknownValue // незнакомые type, media_type и разметка, по порядку появления
+}
+
+// Damaged — часть сообщений потеряна
+func (r ParseReport) Damaged() bool {
+	return r.Lost > 0 || r.Truncated
+}
+
+// Add суммирует отчёты по нескольким файлам
+func (r *ParseReport) Add(o ParseReport) {
+	r.Parsed += o.Parsed
+	r.Lost += o.Lost
+	r.Truncated = r.Truncated || o.Truncated
+	for _, u := range o.Unknown {
+		r.addUnknown(u)
+	}
+}
+
+// telegramSource — result.json из Telegram Desktop
+type telegramSource struct{}
+
+func (telegramSource) Identify(path string) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(sniff(path)), []byte("{"))
+}
+
+func (telegramSource) Load(ctx context.Context, path string) (<-chan Message, ChatInfo, error) {
+	return loadFileExport(ctx, path, func(data []byte) (*ChatExport, error) {
+		export, err := decode(data)
+		if export != nil {
+			markChatSenders(export)
+		}
+		return export, err
+	})
+}
+
+// decodeExport читает result.json потоково, по одному сообщению, чтобы
+// одно кривое сообщение или обрезанный конец не роняли весь экспорт
+func decodeExport(data []byte) (export *ChatExport, err error) {
+	// encoding/json не должен паниковать, но на чужих файлах лучше перестраховаться
+	defer func() {
+		if r := recover(); r != nil {
+			export, err = nil, fmt.Errorf("decoder panic: %v", r)
+		}
+	}()
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, &ParseError{Offset: dec.InputOffset(), Err: err}
+	}
+
+	export = &ChatExport{}
+	var bad *ParseError // первое битое сообщение
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return recovered(truncated(export, data, dec.InputOffset()), &ParseError{Offset: dec.InputOffset(), Err: err})
+		}
+		key, _ := tok.(string)
+
+		switch key {
+		case "name":
+			err = dec.Decode(&export.Name)
+		case "type":
+			err = dec.Decode(&export.Type)
+		case "id":
+			err = dec.Decode(&export.ID)
+		case "messages":
+			// место под все сообщения сразу: у каждого ровно один "id", а
+			// дорастать до сотен тысяч сообщений append'ом — лишние копии
+			if off := dec.InputOffset(); off >= 0 && off < int64(len(data)) {
+				export.Messages = make([]Message, 0, bytes.Count(data[off:], []byte(`"id"`)))
+			}
+			var broken *ParseError
+			if bad, broken = decodeMessages(dec, export); broken != nil {
+				return recovered(truncated(export, data, export.Report.Offset), broken)
+			}
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return recovered(truncated(export, data, dec.InputOffset()), &ParseError{Offset: dec.InputOffset(), Err: err})
+		}
+	}
+
+	export.Report.Offset = dec.InputOffset()
+	// More() молчит и на ']' вместо ключа, и на конце файла: '}' проверяем сами
+	if _, err := dec.Token(); err != nil {
+		return recovered(truncated(export, data, export.Report.Offset), &ParseError{Offset: export.Report.Offset, Err: err})
+	}
+	if export.Report.Parsed == 0 && bad != nil {
+		return recovered(export, bad)
+	}
+	return export, nil
+}
+
+// recovered отдаёт то, что успели прочитать до поломки, если прочитали хоть
+// что-то; иначе — ошибку с местом поломки
+func recovered(export *ChatExport, cause *ParseError) (*ChatExport, error) {
+	if export.Report.Parsed > 0 {
+		return export, nil
+	}
+	cause.Err = fmt.Errorf("%w: %w", ErrEmptyExport, cause.Err)
+	return nil, cause
+}
+
+// decodeMessages читает messages; битые сообщения пропускаются, первое из
+// них возвращается как bad, а broken — если дальше читать нельзя
+func decodeMessages(dec *json.Decoder, export *ChatExport) (bad, broken *ParseError) {
+	if err := expectDelim(dec, '['); err != nil {
+		return nil, &ParseError{Offset: dec.InputOffset(), Err: err}
+	}
+	// raw переиспользуется: Decode дописывает в него с нуля, а строки
+	// сообщения копируются из него при разборе
+	var raw json.RawMessage
+	for index := 1; dec.More(); index++ {
+		// начало сообщения: если оно окажется битым, хвост считаем отсюда
+		export.Report.Offset = dec.InputOffset()
+
+		if err := dec.Decode(&raw); err != nil {
+			return bad, &ParseError{Index: index, Offset: export.Report.Offset, Err: err}
+		}
+
+		var m Message
+		if err := unmarshalMessage(raw, &m); err != nil {
+			export.Report.Lost++
+			if bad == nil {
+				bad = asParseError(err)
+				bad.Index, bad.ID = index, m.ID
+			}
+			continue
+		}
+		export.Messages = append(export.Messages, m)
+		export.Report.Parsed++
+		export.Report.noteUnknown(m)
+	}
+	// за последним сообщением: если вместо ']' мусор, прочитанное в хвост
+	// не считается
+	export.Report.Offset = dec.InputOffset()
+	if _, err := dec.Token(); err != nil { // ']'
+		return bad, &ParseError{Offset: dec.InputOffset(), Err: err}
+	}
+	return bad, nil
+}
+
+func unmarshalMessage(raw []byte, m *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("message panic: %v", r)
+		}
+	}()
+	// json.Unmarshal проверил бы raw лишний раз: его уже проверил json.Decoder,
+	// а у сканера проверит Unmarshal внутри UnmarshalJSON
+	return m.UnmarshalJSON(raw)
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("expected %q, got %v", want, tok)
+	}
+	return nil
+}
+
+// truncated помечает экспорт обрезанным и оценивает, сколько сообщений
+// осталось в нечитаемом хвосте: у каждого сообщения ровно один ключ "id"
+func truncated(export *ChatExport, data []byte, offset int64) *ChatExport {
+	export.Report.Truncated = true
+	export.Report.Offset = offset
+	if offset >= 0 && offset < int64(len(data)) {
+		export.Report.Lost += bytes.Count(data[offset:], []byte(`"id"`))
+	}
+	return export
+}
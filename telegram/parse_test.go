@@ -0,0 +1,174 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fixture — маленький result.json со всеми видами сообщений, которые
+// разбирает UnmarshalJSON: service, text строкой и массивом, ответ, фото,
+// голосовое, гифка от бота, сторис, сообщение от имени чата
+func fixture(t testing.TB) []byte {
+	data, err := os.ReadFile("testdata/result.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// fixtureMessages — сообщения фикстуры по отдельности
+func fixtureMessages(t testing.TB) []json.RawMessage {
+	var export struct {
+		Messages []json.RawMessage `json:"messages"`
+	}
+	if err := json.Unmarshal(fixture(t), &export); err != nil {
+		t.Fatal(err)
+	}
+	return export.Messages
+}
+
+// damaged — варианты фикстуры, на которых декодеры не должны падать:
+// обрезанные в разных местах, с битым сообщением и с мусором
+func damaged(data []byte) [][]byte {
+	out := [][]byte{data}
+	for _, n := range []int{1, 10, len(data) / 3, len(data) / 2, len(data) - 3, len(data) - 1} {
+		out = append(out, data[:n])
+	}
+	out = append(out,
+		bytes.Replace(data, []byte(`"2025-03-08T12:00:00"`), []byte(`"вчера"`), 1),
+		bytes.Replace(data, []byte(`"date_unixtime": "1741424400"`), []byte(`"date_unixtime": "когда-то"`), 1),
+		bytes.Replace(data, []byte(`"id": 5,`), []byte(`"id": "5",`), 1),
+		bytes.Replace(data, []byte(`},
+  {
+   "id": 6`), []byte(`}
+  {
+   "id": 6`), 1),
+		bytes.Replace(data, []byte(`"text_entities": []`), []byte(`"text_entities": [}`), 1),
+		[]byte(`{]0`),
+		[]byte(`{"messages": [1, null, "x", {}]}`),
+		[]byte(`{"messages": []}`),
+		[]byte(`{}`),
+		[]byte(``),
+	)
+	return out
+}
+
+// countMessages — сколько элементов в messages верхнего объекта (во всех,
+// если ключ повторяется); ok = false, если data не такой объект
+func countMessages(data []byte) (n int, ok bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return 0, false
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return 0, false
+		}
+		if tok != "messages" {
+			var skip json.RawMessage
+			if dec.Decode(&skip) != nil {
+				return 0, false
+			}
+			continue
+		}
+		var msgs []json.RawMessage
+		if dec.Decode(&msgs) != nil {
+			return 0, false
+		}
+		n += len(msgs)
+	}
+	if _, err := dec.Token(); err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+func FuzzUnmarshalMessage(f *testing.F) {
+	for _, raw := range fixtureMessages(f) {
+		f.Add([]byte(raw))
+	}
+	f.Add([]byte(`{"id": 1, "date": "2025-01-01T00:00:00", "text": [[["глубоко"]]]}`))
+	f.Add([]byte(`{"id": 1, "date_unixtime": "1735678805", "text": {"text": {"text": "x"}}}`))
+	f.Add([]byte(`{"id": 1, "date": "2025-01-01T00:00:00", "reply_to_message_id": 2, "text_entities": [{"type": "blockquote", "text": "цитата"}, "ответ"], "text": "цитата ответ"}`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var m Message
+		err := m.UnmarshalJSON(data) // без recover: паника — провал
+		if err == nil && !json.Valid(data) {
+			t.Errorf("invalid JSON %q accepted", data)
+		}
+	})
+}
+
+func FuzzDecodeExport(f *testing.F) {
+	for _, data := range damaged(fixture(f)) {
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		export, err := decodeExport(data)
+		if err != nil {
+			if strings.Contains(err.Error(), "panic") {
+				t.Fatalf("decoder panicked: %v", err)
+			}
+			return
+		}
+		r := export.Report
+		if r.Parsed != len(export.Messages) {
+			t.Errorf("Parsed = %d, but %d messages", r.Parsed, len(export.Messages))
+		}
+		if r.Offset < 0 || r.Offset > int64(len(data)) {
+			t.Errorf("Offset = %d outside of %d bytes", r.Offset, len(data))
+		}
+		if r.Truncated {
+			return // Lost в хвосте — оценка по числу "id"
+		}
+		seen, ok := countMessages(data)
+		if !ok {
+			t.Fatalf("broken export %q read as whole: %+v", data, r)
+		}
+		if r.Parsed+r.Lost != seen {
+			t.Errorf("Parsed %d + Lost %d != %d messages in the file", r.Parsed, r.Lost, seen)
+		}
+	})
+}
+
+// Декодеры должны читать одинаково: scan — только быстрее. Сравниваются
+// сообщения и отчёт; у ошибки — только есть она или нет, текст у них свой.
+func sameDecoding(t *testing.T, data []byte) {
+	t.Helper()
+	std, errStd := decodeExport(data)
+	scan, errScan := scanExport(data)
+	if (errStd == nil) != (errScan == nil) {
+		t.Fatalf("%q: std error %v, scan error %v", data, errStd, errScan)
+	}
+	if errStd != nil {
+		return
+	}
+	if !reflect.DeepEqual(std.Messages, scan.Messages) {
+		t.Errorf("messages differ:\nstd  %+v\nscan %+v", std.Messages, scan.Messages)
+	}
+	if !reflect.DeepEqual(std.Report, scan.Report) {
+		t.Errorf("report differs:\nstd  %+v\nscan %+v", std.Report, scan.Report)
+	}
+	if std.Name != scan.Name || std.Type != scan.Type || std.ID != scan.ID {
+		t.Errorf("%q: chat differs: std %q %q %d, scan %q %q %d", data, std.Name, std.Type, std.ID, scan.Name, scan.Type, scan.ID)
+	}
+}
+
+func TestDecodersEqual(t *testing.T) {
+	for i, data := range damaged(fixture(t)) {
+		t.Run(strconv.Itoa(i), func(t *testing.T) { sameDecoding(t, data) })
+	}
+}
+
+func FuzzDecoders(f *testing.F) {
+	for _, data := range damaged(fixture(f)) {
+		f.Add(data)
+	}
+	f.Fuzz(sameDecoding)
+}
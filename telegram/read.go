@@ -0,0 +1,91 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ReadFile читает экспорт целиком; format — имя формата или пусто, тогда
+// формат узнаётся по содержимому. Повреждённый экспорт не ошибка:
+// прочитанное возвращается, а потери видны в Report.
+func ReadFile(ctx context.Context, fileName, format string) (*ChatExport, error) {
+	var src namedSource
+	var err error
+	if format != "" {
+		src, err = sourceByName(format)
+	} else {
+		src, err = detectSource(fileName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ch, info, err := src.Load(ctx, fileName)
+	if err != nil {
+		var pe *ParseError
+		if errors.As(err, &pe) {
+			pe.File = fileName
+		}
+		return nil, fmt.Errorf("%s export: %w", src.Name, err)
+	}
+	export := &ChatExport{Name: info.Name, Type: info.Type, ID: info.ID}
+	for m := range ch {
+		export.Messages = append(export.Messages, m)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	export.Report = *info.Report
+	return export, nil
+}
+
+// FileReport — итог чтения одного файла из нескольких
+type FileReport struct {
+	File string
+	ParseReport
+}
+
+// ReadExports читает несколько экспортов, помечает сообщения чатом-источником
+// и сливает их в один поток, упорядоченный по времени
+func ReadExports(ctx context.Context, files []string, format string) ([]Message, []FileReport, error) {
+	return readExports(ctx, files, format, ReadFile)
+}
+
+func readExports(ctx context.Context, files []string, format string, read func(context.Context, string, string) (*ChatExport, error)) ([]Message, []FileReport, error) {
+	var all []Message
+	var reports []FileReport
+	for _, f := range files {
+		export, err := read(ctx, f, format)
+		if err != nil {
+			var pe *ParseError
+			if errors.As(err, &pe) {
+				return nil, reports, err // путь к файлу уже в ошибке
+			}
+			return nil, reports, fmt.Errorf("%s: %w", f, err)
+		}
+		reports = append(reports, FileReport{File: f, ParseReport: export.Report})
+
+		chat := export.Name
+		if chat == "" {
+			// у WhatsApp имя чата есть только в названии папки: «WhatsApp Chat - Семья»
+			chat = filepath.Base(filepath.Dir(f))
+			chat = strings.TrimPrefix(strings.TrimPrefix(chat, "WhatsApp Chat - "), "WhatsApp Chat with ")
+		}
+		for i := range export.Messages {
+			// Slack сам раскладывает сообщения по каналам
+			if export.Messages[i].Chat == "" {
+				export.Messages[i].Chat = chat
+			}
+		}
+		all = append(all, export.Messages...)
+	}
+
+	if len(files) > 1 {
+		sort.SliceStable(all, func(i, j int) bool { return all[i].Date.Before(all[j].Date) })
+	}
+	return all, reports, nil
+}
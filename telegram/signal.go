@@ -0,0 +1,321 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// Сообщения Signal Desktop в том виде, в каком они лежат в его базе (колонка
+// json таблицы messages) — так их выгружают signalbackup-tools и sigtop:
+// JSON-массив или по объекту на строку. Имена участников и групп берутся из
+// conversations.json рядом с файлом, если он есть, иначе остаются номера и id.
+//
+//	{"type": "incoming", "sent_at": 1735689600000, "body": "текст",
+//	 "sourceServiceId": "…", "conversationId": "…",
+//	 "attachments": [{"contentType": "image/jpeg", "fileName": "…"}],
+//	 "reactions": [{"emoji": "👍", "fromId": "<conversationId>"}]}
+
+type signalMessage struct {
+	Type            string `json:"type"` // incoming, outgoing, остальное — служебное
+	SentAt          int64  `json:"sent_at"`
+	Timestamp       int64  `json:"timestamp"`
+	Body            string `json:"body"`
+	Source          string `json:"source"` // номер телефона в старых версиях
+	SourceUUID      string `json:"sourceUuid"`
+	SourceServiceID string `json:"sourceServiceId"`
+	ConversationID  string `json:"conversationId"`
+	Attachments     []struct {
+		ContentType string `json:"contentType"`
+		FileName    string `json:"fileName"`
+		Flags       int    `json:"flags"`
+	} `json:"attachments"`
+	Sticker    json.RawMessage `json:"sticker"`
+	BodyRanges []struct {
+		Start       int    `json:"start"`
+		Length      int    `json:"length"`
+		MentionUUID string `json:"mentionUuid"`
+		MentionAci  string `json:"mentionAci"`
+	} `json:"bodyRanges"`
+	Reactions []struct {
+		Emoji     string `json:"emoji"`
+		FromID    string `json:"fromId"` // id беседы того, кто поставил реакцию
+		Timestamp int64  `json:"timestamp"`
+	} `json:"reactions"`
+}
+
+type signalConversation struct {
+	ID              string `json:"id"`
+	ServiceID       string `json:"serviceId"`
+	UUID            string `json:"uuid"`
+	E164            string `json:"e164"`
+	Name            string `json:"name"`
+	ProfileName     string `json:"profileName"`
+	ProfileFullName string `json:"profileFullName"`
+}
+
+// у вложения-голосового сообщения выставлен этот флаг
+const signalVoiceMessageFlag = 1
+
+type signalSource struct{}
+
+// Identify: JSON с ключами sent_at и conversationId в начале файла
+func (signalSource) Identify(path string) bool {
+	head := sniff(path)
+	return bytes.Contains(head, []byte(`"sent_at"`)) && bytes.Contains(head, []byte(`"conversationId"`))
+}
+
+func (signalSource) Load(ctx context.Context, path string) (<-chan Message, ChatInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, ChatInfo{}, fmt.Errorf("cannot read file: %w", err)
+	}
+
+	var convs []signalConversation
+	if err := readJSONFile(filepath.Join(filepath.Dir(path), "conversations.json"), &convs); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, ChatInfo{}, err
+	}
+
+	export, err := decodeSignal(data, convs)
+	if err != nil {
+		return nil, ChatInfo{}, asParseError(err)
+	}
+	ch, info := streamExport(ctx, export)
+	return ch, info, nil
+}
+
+// signalPeople сопоставляет id участников и бесед с именами
+type signalPeople struct {
+	byID      map[string]signalConversation // по id беседы
+	byService map[string]signalConversation // по id участника или номеру
+	self      map[string]bool               // свои id: у исходящих они тоже бывают
+}
+
+func decodeSignal(data []byte, convs []signalConversation) (*ChatExport, error) {
+	people := signalPeople{
+		byID:      map[string]signalConversation{},
+		byService: map[string]signalConversation{},
+		self:      map[string]bool{},
+	}
+	for _, c := range convs {
+		people.byID[c.ID] = c
+		for _, id := range []string{c.ServiceID, c.UUID, c.E164} {
+			if id != "" {
+				people.byService[id] = c
+			}
+		}
+	}
+
+	export := &ChatExport{Type: "signal_chat"}
+	var raws []json.RawMessage
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if err := json.Unmarshal(data, &raws); err != nil {
+			return nil, err
+		}
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var raw json.RawMessage
+			err := dec.Decode(&raw)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				// дальше битой строки JSON Lines не читается
+				export.Report.Truncated = true
+				export.Report.Offset = dec.InputOffset()
+				export.Report.Lost++
+				break
+			}
+			raws = append(raws, raw)
+		}
+	}
+
+	var sms []signalMessage
+	for _, raw := range raws {
+		var sm signalMessage
+		if err := json.Unmarshal(raw, &sm); err != nil {
+			export.Report.Lost++
+			continue
+		}
+		sms = append(sms, sm)
+	}
+	if len(sms) == 0 {
+		return nil, &ParseError{Err: ErrEmptyExport}
+	}
+	sort.SliceStable(sms, func(i, j int) bool { return sms[i].sentAt() < sms[j].sentAt() })
+
+	chats := map[string]bool{}
+	for _, sm := range sms {
+		chats[sm.ConversationID] = true
+		if sm.Type == "outgoing" && sm.sender() != "" {
+			people.self[sm.sender()] = true
+		}
+	}
+
+	for i, sm := range sms {
+		m := sm.toMessage(people)
+		m.ID = int64(i + 1)
+		if len(chats) > 1 {
+			m.Chat = people.chatName(sm.ConversationID)
+		}
+		export.Messages = append(export.Messages, m)
+	}
+	if len(chats) == 1 {
+		export.Name = people.chatName(sms[0].ConversationID)
+	}
+
+	export.Report.Parsed = len(export.Messages)
+	return export, nil
+}
+
+func (sm signalMessage) sentAt() int64 {
+	if sm.SentAt != 0 {
+		return sm.SentAt
+	}
+	return sm.Timestamp
+}
+
+func (sm signalMessage) sender() string {
+	for _, id := range []string{sm.SourceServiceID, sm.SourceUUID, sm.Source} {
+		if id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+func (c signalConversation) displayName() string {
+	for _, name := range []string{c.Name, c.ProfileFullName, c.ProfileName, c.E164} {
+		if name != "" {
+			return name
+		}
+	}
+	return c.ID
+}
+
+func (c signalConversation) userID() string {
+	for _, id := range []string{c.ServiceID, c.UUID, c.E164} {
+		if id != "" {
+			return id
+		}
+	}
+	return c.ID
+}
+
+func (p signalPeople) chatName(id string) string {
+	if c, ok := p.byID[id]; ok {
+		return c.displayName()
+	}
+	return id
+}
+
+// user — from_id и имя участника по его id или номеру
+func (p signalPeople) user(id string) (string, string) {
+	if p.self[id] {
+		return SelfID, "Я"
+	}
+	if c, ok := p.byService[id]; ok {
+		return c.userID(), c.displayName()
+	}
+	return id, id
+}
+
+func (sm signalMessage) toMessage(people signalPeople) Message {
+	m := Message{
+		Type: "message",
+		Date: time.UnixMilli(sm.sentAt()).UTC(),
+	}
+
+	switch sm.Type {
+	case "outgoing":
+		m.FromID, m.From = SelfID, "Я"
+	case "incoming":
+		m.FromID, m.From = people.user(sm.sender())
+	default:
+		// смена названия группы, звонки, таймер исчезающих сообщений и т.п.
+		m.Type = "service"
+	}
+
+	// упоминания в тексте — символ U+FFFC, на его месте подставляем имя;
+	// позиции в bodyRanges считаются в UTF-16, поэтому идём с конца
+	body := utf16.Encode([]rune(sm.Body))
+	ranges := sm.BodyRanges
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start > ranges[j].Start })
+	var mentions []TextFragment
+	for _, r := range ranges {
+		id := r.MentionAci
+		if id == "" {
+			id = r.MentionUUID
+		}
+		if id == "" || r.Start < 0 || r.Start+r.Length > len(body) {
+			continue
+		}
+		_, name := people.user(id)
+		mention := "@" + name
+		body = append(body[:r.Start:r.Start], append(utf16.Encode([]rune(mention)), body[r.Start+r.Length:]...)...)
+		mentions = append(mentions, TextFragment{Type: "mention", Text: mention})
+	}
+	m.Text = string(utf16.Decode(body))
+	if m.Text != "" {
+		m.TextEntities = append(m.TextEntities, TextFragment{Type: "plain", Text: m.Text})
+	}
+	m.TextEntities = append(m.TextEntities, mentions...)
+
+	for _, a := range sm.Attachments {
+		kind, _, _ := strings.Cut(a.ContentType, "/")
+		switch {
+		case a.ContentType == "image/gif":
+			m.MediaType = "animation"
+		case kind == "image":
+			m.Photo = a.FileName
+			if m.Photo == "" {
+				m.Photo = "(omitted)"
+			}
+		case kind == "video":
+			m.MediaType = "video_file"
+		case kind == "audio" && a.Flags&signalVoiceMessageFlag != 0:
+			m.MediaType = "voice_message"
+		case kind == "audio":
+			m.MediaType = "audio_file"
+		default:
+			m.MediaType = "file"
+		}
+	}
+	if len(sm.Sticker) > 0 && string(sm.Sticker) != "null" {
+		m.MediaType = "sticker"
+	}
+
+	// в Signal у каждой реакции свой автор; собираем их по эмодзи, как в Telegram
+	index := map[string]int{}
+	for _, r := range sm.Reactions {
+		i, ok := index[r.Emoji]
+		if !ok {
+			i = len(m.Reactions)
+			index[r.Emoji] = i
+			m.Reactions = append(m.Reactions, Reaction{Emoji: r.Emoji, Type: "emoji"})
+		}
+		reaction := &m.Reactions[i]
+		reaction.Count++
+
+		user := ReactionUser{FromID: r.FromID, From: r.FromID}
+		if c, ok := people.byID[r.FromID]; ok {
+			user.FromID, user.From = people.user(c.userID())
+		}
+		if r.Timestamp != 0 {
+			user.Date = time.UnixMilli(r.Timestamp).UTC().Format("2006-01-02T15:04:05")
+		}
+		reaction.Recent = append(reaction.Recent, user)
+	}
+
+	return m
+}
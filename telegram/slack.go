@@ -0,0 +1,265 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Экспорт рабочего пространства Slack — папка:
+//
+//	users.json, channels.json
+//	general/2025-01-01.json, general/2025-01-02.json, ...
+//
+// Каждый файл дня — массив сообщений канала за этот день.
+
+type slackUser struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Deleted bool   `json:"deleted"`
+	Profile struct {
+		RealName    string `json:"real_name"`
+		DisplayName string `json:"display_name"`
+	} `json:"profile"`
+}
+
+func (u slackUser) displayName() string {
+	switch {
+	case u.Profile.DisplayName != "":
+		return u.Profile.DisplayName
+	case u.Profile.RealName != "":
+		return u.Profile.RealName
+	}
+	return u.Name
+}
+
+type slackChannel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type slackMessage struct {
+	Type    string `json:"type"`
+	Subtype string `json:"subtype"`
+	User    string `json:"user"`
+	Text    string `json:"text"`
+	TS      string `json:"ts"`
+	Files   []struct {
+		Name     string `json:"name"`
+		Mimetype string `json:"mimetype"`
+	} `json:"files"`
+	Reactions []struct {
+		Name  string   `json:"name"`
+		Users []string `json:"users"`
+		Count int      `json:"count"`
+	} `json:"reactions"`
+	UserProfile struct {
+		RealName    string `json:"real_name"`
+		DisplayName string `json:"display_name"`
+	} `json:"user_profile"`
+}
+
+// isSlackExport: папка с users.json — сам экспорт, а папка канала внутри него
+// тоже подходит, тогда читается только этот канал
+func isSlackExport(path string) bool {
+	return slackRoot(path) != ""
+}
+
+func slackRoot(path string) string {
+	for _, dir := range []string{path, filepath.Dir(path)} {
+		if _, err := os.Stat(filepath.Join(dir, "users.json")); err == nil {
+			return dir
+		}
+	}
+	return ""
+}
+
+type slackSource struct{}
+
+func (slackSource) Identify(path string) bool { return isDir(path) && isSlackExport(path) }
+
+func (slackSource) Load(ctx context.Context, path string) (<-chan Message, ChatInfo, error) {
+	export, err := readSlackExport(path)
+	if err != nil {
+		return nil, ChatInfo{}, err
+	}
+	ch, info := streamExport(ctx, export)
+	return ch, info, nil
+}
+
+func readSlackExport(path string) (*ChatExport, error) {
+	root := slackRoot(path)
+
+	users := map[string]slackUser{}
+	var userList []slackUser
+	if err := readJSONFile(filepath.Join(root, "users.json"), &userList); err != nil {
+		return nil, err
+	}
+	for _, u := range userList {
+		users[u.ID] = u
+	}
+
+	var channels []string
+	if filepath.Clean(root) != filepath.Clean(path) {
+		channels = []string{filepath.Base(path)}
+	} else {
+		var list []slackChannel
+		if err := readJSONFile(filepath.Join(root, "channels.json"), &list); err != nil {
+			return nil, err
+		}
+		for _, c := range list {
+			channels = append(channels, c.Name)
+		}
+	}
+
+	export := &ChatExport{Name: filepath.Base(filepath.Clean(root)), Type: "slack_workspace"}
+	if len(channels) == 1 {
+		export.Name = "#" + channels[0]
+	}
+
+	for _, channel := range channels {
+		days, _ := filepath.Glob(filepath.Join(root, channel, "*.json"))
+		sort.Strings(days) // имена файлов — даты, так сообщения идут по порядку
+		for _, day := range days {
+			var msgs []slackMessage
+			if err := readJSONFile(day, &msgs); err != nil {
+				export.Report.Lost++
+				continue
+			}
+			for _, sm := range msgs {
+				m, ok := sm.toMessage(users)
+				if !ok {
+					export.Report.Lost++
+					continue
+				}
+				m.Chat = "#" + channel
+				export.Messages = append(export.Messages, m)
+			}
+		}
+	}
+
+	sort.SliceStable(export.Messages, func(i, j int) bool {
+		return export.Messages[i].Date.Before(export.Messages[j].Date)
+	})
+	export.Report.Parsed = len(export.Messages)
+	return export, nil
+}
+
+func readJSONFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// <@U123>, <@U123|name>, <https://example.com|подпись>, <#C123|general>
+var slackRefRe = regexp.MustCompile(`<([@#!]?)([^>|]+)(?:\|([^>]*))?>`)
+
+func (sm slackMessage) toMessage(users map[string]slackUser) (Message, bool) {
+	sec, frac, _ := strings.Cut(sm.TS, ".")
+	s, err := strconv.ParseInt(sec, 10, 64)
+	if err != nil {
+		return Message{}, false
+	}
+	us, _ := strconv.ParseInt((frac + "000000")[:6], 10, 64)
+
+	m := Message{
+		ID:     s*1_000_000 + us,
+		Type:   "message",
+		Date:   time.Unix(s, us*1000).UTC(),
+		FromID: sm.User,
+	}
+
+	switch sm.Subtype {
+	case "", "thread_broadcast", "file_share", "me_message":
+	default:
+		// channel_join, channel_topic, bot_message и т.п.
+		m.Type = "service"
+	}
+
+	m.From = users[sm.User].displayName()
+	if m.From == "" {
+		m.From = sm.UserProfile.DisplayName
+	}
+	if m.From == "" {
+		m.From = sm.UserProfile.RealName
+	}
+
+	// разворачиваем ссылки Slack в читаемый текст, упоминания — в сущности
+	text := slackRefRe.ReplaceAllStringFunc(sm.Text, func(ref string) string {
+		p := slackRefRe.FindStringSubmatch(ref)
+		switch p[1] {
+		case "@":
+			name := p[3]
+			if u, ok := users[p[2]]; ok {
+				name = u.displayName()
+			}
+			mention := "@" + name
+			m.TextEntities = append(m.TextEntities, TextFragment{Type: "mention", Text: mention})
+			return mention
+		case "#":
+			return "#" + p[3]
+		case "!":
+			return "@" + p[2] // @here, @channel
+		}
+		// ссылку оставляем адресом, как в Telegram, — по нему ищутся тиктоки
+		return p[2]
+	})
+	m.Text = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&").Replace(text)
+	if m.Text != "" {
+		m.TextEntities = append([]TextFragment{{Type: "plain", Text: m.Text}}, m.TextEntities...)
+	}
+
+	for _, f := range sm.Files {
+		kind, _, _ := strings.Cut(f.Mimetype, "/")
+		switch kind {
+		case "image":
+			m.Photo = f.Name
+		case "video":
+			m.MediaType = "video_file"
+		case "audio":
+			m.MediaType = "voice_message"
+		default:
+			m.MediaType = "file"
+		}
+	}
+
+	for _, r := range sm.Reactions {
+		reaction := Reaction{Emoji: slackEmoji(r.Name), Count: r.Count, Type: "emoji"}
+		for _, id := range r.Users {
+			reaction.Recent = append(reaction.Recent, ReactionUser{From: users[id].displayName(), FromID: id})
+		}
+		m.Reactions = append(m.Reactions, reaction)
+	}
+
+	return m, true
+}
+
+// самые частые короткие имена эмодзи Slack; остальные остаются как :name:
+var slackEmojiNames = map[string]string{
+	"+1": "👍", "thumbsup": "👍", "-1": "👎", "thumbsdown": "👎",
+	"heart": "❤", "joy": "😂", "rolling_on_the_floor_laughing": "🤣",
+	"fire": "🔥", "tada": "🎉", "pray": "🙏", "eyes": "👀", "clap": "👏",
+	"white_check_mark": "✅", "heavy_check_mark": "✔", "100": "💯",
+	"smile": "😄", "slightly_smiling_face": "🙂", "thinking_face": "🤔",
+	"cry": "😢", "sob": "😭", "rocket": "🚀", "clown_face": "🤡",
+}
+
+func slackEmoji(name string) string {
+	base, _, _ := strings.Cut(name, "::") // :+1::skin-tone-2:
+	if e, ok := slackEmojiNames[base]; ok {
+		return e
+	}
+	return ":" + name + ":"
+}
@@ -0,0 +1,136 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ChatInfo — сведения о чате из экспорта
+type ChatInfo struct {
+	Name string
+	Type string
+	ID   int64
+
+	// Report заполняется к моменту, когда канал сообщений закрыт
+	Report *ParseReport
+}
+
+// Source — формат экспорта одного мессенджера
+type Source interface {
+	// Identify решает по файлу или папке, его ли это формат
+	Identify(path string) bool
+	// Load читает экспорт и отдаёт сообщения по порядку; канал закрывается,
+	// когда сообщения кончились или ctx отменён
+	Load(ctx context.Context, path string) (<-chan Message, ChatInfo, error)
+}
+
+// SelfID — from_id владельца экспорта там, где у своих сообщений нет id (VK, Signal)
+const SelfID = "me"
+
+type namedSource struct {
+	Name string
+	Source
+}
+
+// sources проверяются по порядку: Telegram — самый общий JSON, поэтому последний
+var sources = []namedSource{
+	{"slack", slackSource{}},
+	{"vk", vkSource{}},
+	{"discord", discordSource{}},
+	{"whatsapp", whatsappSource{}},
+	{"signal", signalSource{}},
+	{"matrix", matrixSource{}},
+	{"generic", genericSource{}},
+	{"telegram", telegramSource{}},
+}
+
+// RegisterSource добавляет свой формат; он проверяется раньше встроенных
+func RegisterSource(name string, s Source) {
+	sources = append([]namedSource{{name, s}}, sources...)
+}
+
+// sourceByName — формат, заданный явно через -format
+func sourceByName(name string) (namedSource, error) {
+	var names []string
+	for _, s := range sources {
+		if strings.EqualFold(s.Name, name) {
+			return s, nil
+		}
+		names = append(names, s.Name)
+	}
+	return namedSource{}, fmt.Errorf("unknown format %q, known: %s", name, strings.Join(names, ", "))
+}
+
+func detectSource(path string) (namedSource, error) {
+	for _, s := range sources {
+		if s.Identify(path) {
+			return s, nil
+		}
+	}
+	if st, err := os.Stat(path); err != nil {
+		return namedSource{}, fmt.Errorf("cannot open file: %w", err)
+	} else if st.IsDir() {
+		return namedSource{}, errors.New("directory is not a chat export")
+	}
+	return namedSource{}, errors.New("unknown export format")
+}
+
+// sniff — начало файла, по которому узнаётся формат; у папок и нечитаемых файлов nil
+func sniff(path string) []byte {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	if st, err := f.Stat(); err != nil || st.IsDir() {
+		return nil
+	}
+
+	head := make([]byte, 4096)
+	n, _ := io.ReadFull(f, head)
+	return bytes.TrimPrefix(head[:n], []byte("\ufeff"))
+}
+
+func isDir(path string) bool {
+	st, err := os.Stat(path)
+	return err == nil && st.IsDir()
+}
+
+// streamExport отдаёт через канал экспорт, уже прочитанный целиком
+func streamExport(ctx context.Context, export *ChatExport) (<-chan Message, ChatInfo) {
+	info := ChatInfo{Name: export.Name, Type: export.Type, ID: export.ID, Report: &export.Report}
+	ch := make(chan Message)
+	go func() {
+		defer close(ch)
+		for _, m := range export.Messages {
+			select {
+			case ch <- m:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, info
+}
+
+// loadFileExport — Load для форматов, которые читаются из одного файла целиком
+func loadFileExport(ctx context.Context, path string, decode func([]byte) (*ChatExport, error)) (<-chan Message, ChatInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, ChatInfo{}, fmt.Errorf("cannot read file: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, ChatInfo{}, err
+	}
+	export, err := decode(data)
+	if err != nil {
+		return nil, ChatInfo{}, asParseError(err)
+	}
+	ch, info := streamExport(ctx, export)
+	return ch, info, nil
+}
@@ -0,0 +1,124 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/sources/*.golden.json")
+
+// goldenMessage — то, что из экспорта доходит до номинаций: время с поясом,
+// отправитель, ответ и пересылка, вложения, реакции и упоминания
+type goldenMessage struct {
+	ID        int64    `json:"id"`
+	Type      string   `json:"type"`
+	Date      string   `json:"date"`
+	WallClock bool     `json:"wall_clock,omitempty"`
+	From      string   `json:"from,omitempty"`
+	FromID    string   `json:"from_id,omitempty"`
+	Text      string   `json:"text,omitempty"`
+	Quote     string   `json:"quote,omitempty"`
+	ReplyTo   int64    `json:"reply_to,omitempty"`
+	Forwarded string   `json:"forwarded,omitempty"`
+	Media     string   `json:"media_type,omitempty"`
+	Photo     string   `json:"photo,omitempty"`
+	Mentions  []string `json:"mentions,omitempty"`
+	Reactions []string `json:"reactions,omitempty"` // «эмодзи×число: from_id …»
+	Chat      string   `json:"chat,omitempty"`
+}
+
+type goldenExport struct {
+	Name     string          `json:"name"`
+	Type     string          `json:"type"`
+	Parsed   int             `json:"parsed"`
+	Lost     int             `json:"lost"`
+	Messages []goldenMessage `json:"messages"`
+}
+
+func golden(export *ChatExport) goldenExport {
+	g := goldenExport{Name: export.Name, Type: export.Type, Parsed: export.Report.Parsed, Lost: export.Report.Lost}
+	for _, m := range export.Messages {
+		gm := goldenMessage{
+			ID: m.ID, Type: m.Type, Date: m.Date.Format(time.RFC3339Nano), WallClock: m.WallClock,
+			From: m.From, FromID: m.FromID, Text: m.Text, Quote: m.Quote, ReplyTo: m.ReplyToMessageID,
+			Forwarded: strings.TrimSpace(m.ForwardedFrom + " " + m.ForwardedFromID),
+			Media:     m.MediaType, Photo: m.Photo, Chat: m.Chat,
+		}
+		for _, e := range m.TextEntities {
+			if e.Type == "mention" {
+				gm.Mentions = append(gm.Mentions, e.Text)
+			}
+		}
+		for _, r := range m.Reactions {
+			var from []string
+			for _, u := range r.Recent {
+				from = append(from, u.FromID)
+			}
+			gm.Reactions = append(gm.Reactions, strings.TrimSpace(r.Emoji+"×"+strconv.Itoa(r.Count)+": "+strings.Join(from, " ")))
+		}
+		g.Messages = append(g.Messages, gm)
+	}
+	return g
+}
+
+// TestSourcesGolden читает маленькие экспорты каждого формата, кроме
+// Telegram (его проверяет parse_test.go), с определением формата по файлу и
+// сравнивает результат с testdata/sources/<имя>.golden.json. После намеренных
+// правок разбора: go test ./telegram -run SourcesGolden -update
+func TestSourcesGolden(t *testing.T) {
+	for _, c := range []struct {
+		name, path, format string
+	}{
+		{"discord", "discord.json", "discord"},
+		{"slack", "slack", "slack"},
+		{"slack-channel", "slack/general", "slack"},
+		{"vk", "vk", "vk"},
+		{"signal", "signal/messages.jsonl", "signal"},
+		{"matrix", "matrix.json", "matrix"},
+		{"generic-csv", "generic.csv", "generic"},
+		{"generic-jsonl", "generic.jsonl", "generic"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join("testdata", "sources", filepath.FromSlash(c.path))
+			src, err := detectSource(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if src.Name != c.format {
+				t.Fatalf("detected as %s, want %s", src.Name, c.format)
+			}
+			export, err := ReadFile(context.Background(), path, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.MarshalIndent(golden(export), "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			file := filepath.Join("testdata", "sources", c.name+".golden.json")
+			if *update {
+				if err := os.WriteFile(file, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s differs from %s:\n%s", path, file, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package telegram
+
+// Значения полей, которые Telegram Desktop пишет сейчас. Всё остальное
+// читается как раньше (разметка склеивается в текст, вложение остаётся со
+// своим media_type), но попадает в ParseReport.Unknown: так новый формат
+// экспорта видно сразу, а не по странным цифрам на странице.
+var (
+	knownTypes = map[string]bool{"message": true, "service": true}
+
+	knownMediaTypes = map[string]bool{
+		"sticker": true, "animation": true, "video_file": true, "video_message": true,
+		"voice_message": true, "audio_file": true,
+		MediaStory: true, MediaGiveaway: true, MediaGiveawayResults: true,
+	}
+
+	knownEntityTypes = map[string]bool{
+		"plain": true, "bold": true, "italic": true, "underline": true, "strikethrough": true,
+		"spoiler": true, "code": true, "pre": true, "blockquote": true,
+		"link": true, "text_link": true, "email": true, "phone": true, "bank_card": true,
+		"mention": true, "mention_name": true, "hashtag": true, "cashtag": true,
+		"bot_command": true, "custom_emoji": true, "unknown": true,
+	}
+)
+
+// UnknownValue — незнакомое значение поля в экспорте
+type UnknownValue struct {
+	Field    string  // type, media_type или text_entities.type
+	Value    string  // само значение
+	Count    int     // в скольких сообщениях встретилось
+	Examples []int64 // id первых таких сообщений, не больше unknownExamples
+}
+
+const unknownExamples = 3
+
+// noteUnknown запоминает незнакомые type, media_type и типы разметки сообщения
+func (r *ParseReport) noteUnknown(m Message) {
+	if !knownTypes[m.Type] {
+		r.addUnknown(UnknownValue{Field: "type", Value: m.Type, Count: 1, Examples: []int64{m.ID}})
+	}
+	if m.MediaType != "" && !knownMediaTypes[m.MediaType] {
+		r.addUnknown(UnknownValue{Field: "media_type", Value: m.MediaType, Count: 1, Examples: []int64{m.ID}})
+	}
+	seen := map[string]bool{} // одно сообщение считается один раз
+	for _, e := range m.TextEntities {
+		if !knownEntityTypes[e.Type] && !seen[e.Type] {
+			seen[e.Type] = true
+			r.addUnknown(UnknownValue{Field: "text_entities.type", Value: e.Type, Count: 1, Examples: []int64{m.ID}})
+		}
+	}
+}
+
+func (r *ParseReport) addUnknown(u UnknownValue) {
+	for i := range r.Unknown {
+		old := &r.Unknown[i]
+		if old.Field != u.Field || old.Value != u.Value {
+			continue
+		}
+		old.Count += u.Count
+		for _, id := range u.Examples {
+			if len(old.Examples) < unknownExamples {
+				old.Examples = append(old.Examples, id)
+			}
+		}
+		return
+	}
+	u.Examples = append([]int64(nil), u.Examples...)
+	r.Unknown = append(r.Unknown, u)
+}
@@ -0,0 +1,317 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Архив ВКонтакте (vk.com/data_protection) — папка Archive, в ней
+//
+//	messages/index-messages.html
+//	messages/<peer_id>/messages0.html, messages50.html, ...
+//
+// Страницы в windows-1251, по 50 сообщений, от новых к старым. Сообщение:
+//
+//	<div class="message" data-id="123">
+//	  <div class="message__header"><a href="https://vk.com/id1">Имя</a>, 1 янв 2025 в 12:00:00</div>
+//	  <div>текст<div class="kludges"><div class="attachment">...</div></div></div>
+//	</div>
+//
+// Свои сообщения подписаны «Вы» без ссылки — такой участник получает from_id "me".
+
+// isVKArchive: корень архива, папка messages или папка одной беседы
+func isVKArchive(path string) bool {
+	for _, p := range []string{
+		filepath.Join(path, "messages", "index-messages.html"),
+		filepath.Join(path, "index-messages.html"),
+		filepath.Join(path, "messages0.html"),
+	} {
+		if _, err := os.Stat(p); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+type vkSource struct{}
+
+func (vkSource) Identify(path string) bool { return isDir(path) && isVKArchive(path) }
+
+func (vkSource) Load(ctx context.Context, path string) (<-chan Message, ChatInfo, error) {
+	export, err := readVKArchive(path)
+	if err != nil {
+		return nil, ChatInfo{}, err
+	}
+	ch, info := streamExport(ctx, export)
+	return ch, info, nil
+}
+
+func readVKArchive(path string) (*ChatExport, error) {
+	if _, err := os.Stat(filepath.Join(path, "messages")); err == nil {
+		path = filepath.Join(path, "messages")
+	}
+
+	var convs []string
+	if _, err := os.Stat(filepath.Join(path, "messages0.html")); err == nil {
+		convs = []string{path}
+	} else {
+		convs, _ = filepath.Glob(filepath.Join(path, "*", "messages0.html"))
+		for i := range convs {
+			convs[i] = filepath.Dir(convs[i])
+		}
+	}
+
+	export := &ChatExport{Type: "vk_archive"}
+	for _, conv := range convs {
+		pages, _ := filepath.Glob(filepath.Join(conv, "messages*.html"))
+		for _, page := range pages {
+			title, msgs, lost, err := readVKPage(page)
+			if err != nil {
+				export.Report.Lost++
+				continue
+			}
+			export.Report.Lost += lost
+			if title == "" {
+				title = filepath.Base(conv)
+			}
+			if len(convs) == 1 {
+				export.Name = title
+			}
+			for i := range msgs {
+				msgs[i].Chat = title
+			}
+			export.Messages = append(export.Messages, msgs...)
+		}
+	}
+
+	sort.SliceStable(export.Messages, func(i, j int) bool {
+		return export.Messages[i].Date.Before(export.Messages[j].Date)
+	})
+	export.Report.Parsed = len(export.Messages)
+	return export, nil
+}
+
+// readVKPage возвращает название беседы, сообщения одной страницы и
+// сколько сообщений не разобралось (нет даты в заголовке)
+func readVKPage(path string) (title string, msgs []Message, lost int, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, 0, err
+	}
+	if !utf8.Valid(data) {
+		if data, err = charmap.Windows1251.NewDecoder().Bytes(data); err != nil {
+			return "", nil, 0, err
+		}
+	}
+
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	walkHTML(doc, func(n *html.Node) bool {
+		switch {
+		case hasClass(n, "ui_crumb"):
+			title = strings.TrimSpace(nodeText(n)) // последняя «крошка» — сама беседа
+		case hasClass(n, "message"):
+			if m, ok := vkMessage(n); ok {
+				msgs = append(msgs, m)
+			} else {
+				lost++
+			}
+			return false
+		}
+		return true
+	})
+	return title, msgs, lost, nil
+}
+
+var vkProfileRe = regexp.MustCompile(`vk\.com/(id|club|public)(\d+)`)
+
+func vkMessage(n *html.Node) (Message, bool) {
+	m := Message{Type: "message"}
+	m.ID, _ = strconv.ParseInt(attr(n, "data-id"), 10, 64)
+
+	var body *html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		if hasClass(c, "message__header") {
+			if !vkHeader(c, &m) {
+				return m, false
+			}
+		} else if body == nil {
+			body = c
+		}
+	}
+	if m.Date.IsZero() {
+		return m, false
+	}
+	if body == nil {
+		return m, true
+	}
+
+	var text strings.Builder
+	for c := body.FirstChild; c != nil; c = c.NextSibling {
+		if hasClass(c, "kludges") {
+			vkKludges(c, &m)
+			continue
+		}
+		text.WriteString(nodeText(c))
+	}
+	m.Text = strings.TrimSpace(text.String() + m.Text)
+	if m.Text != "" {
+		m.TextEntities = []TextFragment{{Type: "plain", Text: m.Text}}
+	}
+	return m, true
+}
+
+// vkHeader разбирает «<a>Имя</a>, 1 янв 2025 в 12:00:00 (ред.)»
+func vkHeader(n *html.Node, m *Message) bool {
+	header := strings.TrimSpace(nodeText(n))
+	header = strings.TrimSuffix(header, "(ред.)")
+
+	i := strings.LastIndex(header, ", ")
+	if i < 0 {
+		return false
+	}
+	m.From = strings.TrimSpace(header[:i])
+	date, ok := vkDate(strings.TrimSpace(header[i+2:]))
+	if !ok {
+		return false
+	}
+	m.Date, m.WallClock = date, true
+
+	m.FromID = SelfID
+	walkHTML(n, func(a *html.Node) bool {
+		if a.Type == html.ElementNode && a.Data == "a" {
+			if p := vkProfileRe.FindStringSubmatch(attr(a, "href")); p != nil {
+				m.FromID = "user" + p[2]
+				if p[1] != "id" {
+					m.FromID = "club" + p[2] // сообщество или бот
+				}
+			}
+		}
+		return true
+	})
+	return true
+}
+
+var vkMonths = map[string]time.Month{
+	"янв": time.January, "фев": time.February, "мар": time.March, "апр": time.April,
+	"мая": time.May, "июн": time.June, "июл": time.July, "авг": time.August,
+	"сен": time.September, "окт": time.October, "ноя": time.November, "дек": time.December,
+}
+
+// vkDate: «1 янв 2025 в 12:00:00»
+func vkDate(s string) (time.Time, bool) {
+	f := strings.Fields(s)
+	if len(f) != 5 || f[3] != "в" {
+		return time.Time{}, false
+	}
+	day, err1 := strconv.Atoi(f[0])
+	month, ok := vkMonths[f[1]]
+	year, err2 := strconv.Atoi(f[2])
+	clock, err3 := time.Parse("15:04:05", f[4])
+	if err1 != nil || err2 != nil || err3 != nil || !ok {
+		return time.Time{}, false
+	}
+	return time.Date(year, month, day, clock.Hour(), clock.Minute(), clock.Second(), 0, time.UTC), true
+}
+
+// vkKludges — вложения; без вложений там служебное действие
+// («пригласил в беседу», «закрепил сообщение»)
+func vkKludges(n *html.Node, m *Message) {
+	found := false
+	walkHTML(n, func(c *html.Node) bool {
+		if !hasClass(c, "attachment__description") {
+			return true
+		}
+		found = true
+		desc := strings.ToLower(strings.TrimSpace(nodeText(c)))
+		switch {
+		case strings.HasPrefix(desc, "фотограф"):
+			m.Photo = "(omitted)"
+		case strings.HasPrefix(desc, "видео"):
+			m.MediaType = "video_file"
+		case strings.HasPrefix(desc, "голосов"):
+			m.MediaType = "voice_message"
+		case strings.HasPrefix(desc, "аудио"):
+			m.MediaType = "audio_file"
+		case strings.HasPrefix(desc, "стикер"):
+			m.MediaType = "sticker"
+		case strings.Contains(desc, "gif"):
+			m.MediaType = "animation"
+		case strings.HasPrefix(desc, "ссылка"):
+			// адрес ссылки нужен как текст — по нему ищутся тиктоки
+			walkHTML(c.Parent, func(a *html.Node) bool {
+				if hasClass(a, "attachment__link") {
+					m.Text += " " + attr(a, "href")
+				}
+				return true
+			})
+		default:
+			if m.MediaType == "" {
+				m.MediaType = "file"
+			}
+		}
+		return false
+	})
+	if !found && strings.TrimSpace(nodeText(n)) != "" {
+		m.Type = "service"
+	}
+}
+
+// walkHTML обходит дерево; visit возвращает false, чтобы не спускаться в детей
+func walkHTML(n *html.Node, visit func(*html.Node) bool) {
+	if !visit(n) {
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walkHTML(c, visit)
+	}
+}
+
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	walkHTML(n, func(c *html.Node) bool {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		}
+		return c == n || !hasClass(c, "kludges")
+	})
+	return b.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasClass(n *html.Node, class string) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	for _, c := range strings.Fields(attr(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,284 @@
+package telegram
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Экспорт чата WhatsApp (_chat.txt). Бывает двух видов:
+//
+//	Android: 31.12.24, 23:59 - Имя: текст
+//	iOS:     [31.12.24, 23:59:59] Имя: текст
+//
+// Порядок дня и месяца зависит от локали телефона, поэтому угадывается по всему файлу.
+var waLineRe = regexp.MustCompile(
+	`^\[?(\d{1,4})[./-](\d{1,2})[./-](\d{1,4}),? (\d{1,2}):(\d{2})(?::(\d{2}))?[\s\x{202f}]?([AaPp]\.?[Mm]\.?)?\]?(?: -|:)? (.*)$`)
+
+var waMediaMarkers = []struct {
+	marker    string
+	mediaType string
+}{
+	{"image omitted", "photo"},
+	{"изображение отсутствует", "photo"},
+	{"video omitted", "video_file"},
+	{"видео отсутствует", "video_file"},
+	{"audio omitted", "voice_message"},
+	{"аудио отсутствует", "voice_message"},
+	{"sticker omitted", "sticker"},
+	{"стикер отсутствует", "sticker"},
+	{"GIF omitted", "animation"},
+	{"GIF отсутствует", "animation"},
+	{"document omitted", "file"},
+	{"<Media omitted>", "media_omitted"},
+	{"<Без медиафайлов>", "media_omitted"},
+	{"<Медиа отсутствуют>", "media_omitted"},
+}
+
+// waSystemPhrases — куски системных строк, в которых тоже бывает «: »
+// («X changed the subject to: Y»). Ищутся только в части до «: », где у
+// сообщения было бы имя: в имени участника их не бывает.
+var waSystemPhrases = []string{
+	"changed the subject",
+	"changed the group description",
+	"changed this group's",
+	"changed the group name",
+	"end-to-end encrypted",
+	"security code",
+	"created group",
+	"changed their phone number",
+	"disappearing messages",
+	"изменил(а) тему",
+	"изменил тему",
+	"изменила тему",
+	"изменил(а) описание",
+	"сквозным шифрованием",
+	"создал(а) группу",
+	"создал группу",
+	"создала группу",
+	"код безопасности",
+	"сменил(а) номер",
+	"исчезающие сообщения",
+}
+
+// waSystem — строка без отправителя, хотя в ней есть «: »
+func waSystem(name string) bool {
+	name = strings.ToLower(name)
+	for _, p := range waSystemPhrases {
+		if strings.Contains(name, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// префиксы имён вложенных файлов в экспорте «с медиа»
+var waFilePrefixes = map[string]string{
+	"IMG":     "photo",
+	"PHOTO":   "photo",
+	"VID":     "video_file",
+	"VIDEO":   "video_file",
+	"PTT":     "voice_message",
+	"AUDIO":   "voice_message",
+	"AUD":     "audio_file",
+	"STK":     "sticker",
+	"STICKER": "sticker",
+	"GIF":     "animation",
+	"DOC":     "file",
+}
+
+var waAttachedRe = regexp.MustCompile(`<(?:attached|прикреплено): ([^>]+)>|^(\S+\.\w+) \((?:file attached|файл добавлен)\)`)
+
+// isWhatsAppExport: текстовый файл, первая непустая строка которого похожа на сообщение
+func isWhatsAppExport(data []byte) bool {
+	s := bufio.NewScanner(bytes.NewReader(data[:min(len(data), 4096)]))
+	for s.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(s.Text(), "\ufeff"))
+		if line == "" {
+			continue
+		}
+		return waLineRe.MatchString(stripWAMarks(line))
+	}
+	return false
+}
+
+// WhatsApp расставляет невидимые LRM-метки перед вложениями и датами
+func stripWAMarks(s string) string {
+	return strings.NewReplacer("\u200e", "", "\u200f", "").Replace(s)
+}
+
+type whatsappSource struct{}
+
+func (whatsappSource) Identify(path string) bool { return isWhatsAppExport(sniff(path)) }
+
+func (whatsappSource) Load(ctx context.Context, path string) (<-chan Message, ChatInfo, error) {
+	return loadFileExport(ctx, path, decodeWhatsApp)
+}
+
+type waLine struct {
+	a, b, c, hh, mm, ss, ampm, rest string
+}
+
+func decodeWhatsApp(data []byte) (*ChatExport, error) {
+	var lines []waLine
+	var continuation []string // строки, продолжающие предыдущее сообщение
+
+	flush := func() {
+		if len(lines) > 0 && len(continuation) > 0 {
+			lines[len(lines)-1].rest += "\n" + strings.Join(continuation, "\n")
+		}
+		continuation = nil
+	}
+
+	s := bufio.NewScanner(bytes.NewReader(data))
+	s.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for s.Scan() {
+		line := stripWAMarks(strings.TrimPrefix(s.Text(), "\ufeff"))
+		m := waLineRe.FindStringSubmatch(line)
+		if m == nil {
+			continuation = append(continuation, line)
+			continue
+		}
+		flush()
+		lines = append(lines, waLine{m[1], m[2], m[3], m[4], m[5], m[6], m[7], m[8]})
+	}
+	flush()
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%w: no WhatsApp messages found", ErrEmptyExport)
+	}
+
+	dayFirst := waDayFirst(lines)
+	export := &ChatExport{Type: "whatsapp_chat"}
+	for i, l := range lines {
+		date, ok := l.date(dayFirst)
+		if !ok {
+			export.Report.Lost++
+			continue
+		}
+
+		m := Message{ID: int64(i + 1), Type: "message", Date: date, WallClock: true}
+		if name, text, ok := strings.Cut(l.rest, ": "); ok && !waSystem(name) {
+			m.From, m.FromID, m.Text = name, name, text
+		} else {
+			// «Анна добавила Бориса», «Сообщения защищены шифрованием» и т.п.
+			m.Type = "service"
+			m.Text = l.rest
+		}
+		waMedia(&m)
+		if m.Text != "" {
+			m.TextEntities = []TextFragment{{Type: "plain", Text: m.Text}}
+		}
+
+		export.Messages = append(export.Messages, m)
+	}
+	export.Report.Parsed = len(export.Messages)
+	return export, nil
+}
+
+// waMedia распознаёт маркеры вложений и убирает их из текста
+func waMedia(m *Message) {
+	text := strings.TrimSpace(m.Text)
+	for _, mk := range waMediaMarkers {
+		if strings.EqualFold(text, mk.marker) {
+			m.setWAMedia(mk.mediaType, "")
+			m.Text = ""
+			return
+		}
+	}
+
+	if a := waAttachedRe.FindStringSubmatch(text); a != nil {
+		file := a[1] + a[2]
+		mediaType := "file"
+		// 00000012-PHOTO-2024-01-01-12-00-00.jpg или IMG-20240101-WA0001.jpg
+		for _, part := range strings.FieldsFunc(file, func(r rune) bool { return r == '-' || r == '.' }) {
+			if t, ok := waFilePrefixes[strings.ToUpper(part)]; ok {
+				mediaType = t
+				break
+			}
+		}
+		m.setWAMedia(mediaType, file)
+		m.Text = strings.TrimSpace(waAttachedRe.ReplaceAllString(text, ""))
+	}
+}
+
+func (m *Message) setWAMedia(mediaType, file string) {
+	if mediaType == "photo" {
+		m.Photo = file
+		if m.Photo == "" {
+			m.Photo = "(omitted)"
+		}
+		return
+	}
+	m.MediaType = mediaType
+}
+
+// waDayFirst угадывает порядок дня и месяца: если где-то первое число
+// больше 12 — это день, если второе — месяц идёт первым
+func waDayFirst(lines []waLine) bool {
+	for _, l := range lines {
+		if len(l.a) == 4 {
+			return false // 2024-12-31
+		}
+		if a, _ := strconv.Atoi(l.a); a > 12 {
+			return true
+		}
+		if b, _ := strconv.Atoi(l.b); b > 12 {
+			return false
+		}
+	}
+	return true
+}
+
+func (l waLine) date(dayFirst bool) (time.Time, bool) {
+	a, _ := strconv.Atoi(l.a)
+	b, _ := strconv.Atoi(l.b)
+	c, _ := strconv.Atoi(l.c)
+
+	var year, month, day int
+	switch {
+	case len(l.a) == 4:
+		year, month, day = a, b, c
+	case dayFirst:
+		day, month, year = a, b, c
+	default:
+		month, day, year = a, b, c
+	}
+	if year < 100 {
+		year += 2000
+	}
+
+	hh, _ := strconv.Atoi(l.hh)
+	mm, _ := strconv.Atoi(l.mm)
+	ss, _ := strconv.Atoi(l.ss)
+	if l.ampm != "" && (hh < 1 || hh > 12) {
+		return time.Time{}, false // 13:00 PM
+	}
+	switch strings.ToUpper(strings.ReplaceAll(l.ampm, ".", "")) {
+	case "PM":
+		if hh < 12 {
+			hh += 12
+		}
+	case "AM":
+		if hh == 12 {
+			hh = 0
+		}
+	}
+
+	if hh > 23 || mm > 59 || ss > 59 {
+		return time.Time{}, false
+	}
+	// time.Date сам переносит 31.02 на 3 марта: такая строка — не дата
+	t := time.Date(year, time.Month(month), day, hh, mm, ss, 0, time.UTC)
+	if t.Year() != year || t.Month() != time.Month(month) || t.Day() != day {
+		return time.Time{}, false
+	}
+	return t, true
+}
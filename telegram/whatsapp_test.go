@@ -0,0 +1,147 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecodeWhatsApp(t *testing.T) {
+	type want struct {
+		typ, from, text, media, photo string
+		date                          time.Time
+	}
+	date := func(y int, m time.Month, d, hh, mm, ss int) time.Time {
+		return time.Date(y, m, d, hh, mm, ss, 0, time.UTC)
+	}
+	cases := []struct {
+		name string
+		in   string
+		want []want
+		lost int
+	}{{
+		name: "android",
+		in:   "31.12.24, 23:59 - Анна: С наступающим!\n01.01.25, 00:00 - Борис: И тебя",
+		want: []want{
+			{typ: "message", from: "Анна", text: "С наступающим!", date: date(2024, 12, 31, 23, 59, 0)},
+			{typ: "message", from: "Борис", text: "И тебя", date: date(2025, 1, 1, 0, 0, 0)},
+		},
+	}, {
+		name: "ios",
+		in:   "[31.12.24, 23:59:58] Анна: привет\n[01.01.25, 00:00:01] Борис: пока",
+		want: []want{
+			{typ: "message", from: "Анна", text: "привет", date: date(2024, 12, 31, 23, 59, 58)},
+			{typ: "message", from: "Борис", text: "пока", date: date(2025, 1, 1, 0, 0, 1)},
+		},
+	}, {
+		// второе число больше 12 — значит, первым идёт месяц
+		name: "month first",
+		in:   "03/04/25, 10:00 - Anna: first\n03/15/25, 10:00 - Bob: second",
+		want: []want{
+			{typ: "message", from: "Anna", text: "first", date: date(2025, 3, 4, 10, 0, 0)},
+			{typ: "message", from: "Bob", text: "second", date: date(2025, 3, 15, 10, 0, 0)},
+		},
+	}, {
+		name: "day first",
+		in:   "03/04/25, 10:00 - Anna: first\n15/04/25, 10:00 - Bob: second",
+		want: []want{
+			{typ: "message", from: "Anna", text: "first", date: date(2025, 4, 3, 10, 0, 0)},
+			{typ: "message", from: "Bob", text: "second", date: date(2025, 4, 15, 10, 0, 0)},
+		},
+	}, {
+		name: "12h",
+		in: "[1/20/25, 12:05:00 AM] Anna: midnight\n" +
+			"[1/20/25, 12:05:00 PM] Bob: noon\n" +
+			"1/20/25, 11:30\u202fp.m. - Anna: evening",
+		want: []want{
+			{typ: "message", from: "Anna", text: "midnight", date: date(2025, 1, 20, 0, 5, 0)},
+			{typ: "message", from: "Bob", text: "noon", date: date(2025, 1, 20, 12, 5, 0)},
+			{typ: "message", from: "Anna", text: "evening", date: date(2025, 1, 20, 23, 30, 0)},
+		},
+	}, {
+		name: "multiline",
+		in:   "01.02.25, 10:00 - Анна: первая строка\nвторая строка\n\nчетвёртая\n01.02.25, 10:01 - Борис: ок",
+		want: []want{
+			{typ: "message", from: "Анна", text: "первая строка\nвторая строка\n\nчетвёртая", date: date(2025, 2, 1, 10, 0, 0)},
+			{typ: "message", from: "Борис", text: "ок", date: date(2025, 2, 1, 10, 1, 0)},
+		},
+	}, {
+		name: "media",
+		in: "01.02.25, 10:00 - Анна: <Без медиафайлов>\n" +
+			"[01.02.25, 10:01:00] Борис: \u200esticker omitted\n" +
+			"[01.02.25, 10:02:00] Анна: \u200e<attached: 00000012-PHOTO-2025-02-01-10-02-00.jpg>\n" +
+			"01.02.25, 10:03 - Борис: PTT-20250201-WA0001.opus (файл добавлен)",
+		want: []want{
+			{typ: "message", from: "Анна", media: "media_omitted", date: date(2025, 2, 1, 10, 0, 0)},
+			{typ: "message", from: "Борис", media: "sticker", date: date(2025, 2, 1, 10, 1, 0)},
+			{typ: "message", from: "Анна", photo: "00000012-PHOTO-2025-02-01-10-02-00.jpg", date: date(2025, 2, 1, 10, 2, 0)},
+			{typ: "message", from: "Борис", media: "voice_message", date: date(2025, 2, 1, 10, 3, 0)},
+		},
+	}, {
+		name: "system",
+		in: "01.02.25, 10:00 - Messages and calls are end-to-end encrypted. No one outside of this chat can read them: tap to learn more.\n" +
+			"01.02.25, 10:01 - Anna changed the subject to: Дача 2025\n" +
+			"01.02.25, 10:02 - Анна добавила Бориса\n" +
+			"01.02.25, 10:03 - Анна изменил(а) тему с «Дача» на: «Дача 2025»\n" +
+			"01.02.25, 10:04 - Борис: настоящее сообщение",
+		want: []want{
+			{typ: "service", text: "Messages and calls are end-to-end encrypted. No one outside of this chat can read them: tap to learn more.", date: date(2025, 2, 1, 10, 0, 0)},
+			{typ: "service", text: "Anna changed the subject to: Дача 2025", date: date(2025, 2, 1, 10, 1, 0)},
+			{typ: "service", text: "Анна добавила Бориса", date: date(2025, 2, 1, 10, 2, 0)},
+			{typ: "service", text: "Анна изменил(а) тему с «Дача» на: «Дача 2025»", date: date(2025, 2, 1, 10, 3, 0)},
+			{typ: "message", from: "Борис", text: "настоящее сообщение", date: date(2025, 2, 1, 10, 4, 0)},
+		},
+	}, {
+		// 31 февраля и 13 PM не переносятся на другие дни, а теряются
+		name: "impossible dates",
+		in: "31.02.25, 10:00 - Анна: февраль\n" +
+			"[29.02.25, 10:00:00] Борис: не високосный\n" +
+			"[1/2/25, 13:00:00 PM] Anna: afternoon\n" +
+			"28.02.25, 10:00 - Анна: настоящий",
+		want: []want{
+			{typ: "message", from: "Анна", text: "настоящий", date: date(2025, 2, 28, 10, 0, 0)},
+		},
+		lost: 3,
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			export, err := decodeWhatsApp([]byte(c.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if export.Report.Lost != c.lost {
+				t.Errorf("lost %d, want %d", export.Report.Lost, c.lost)
+			}
+			if len(export.Messages) != len(c.want) {
+				t.Fatalf("%d messages, want %d: %+v", len(export.Messages), len(c.want), export.Messages)
+			}
+			for i, w := range c.want {
+				m := export.Messages[i]
+				got := want{typ: m.Type, from: m.From, text: m.Text, media: m.MediaType, photo: m.Photo, date: m.Date}
+				if !got.date.Equal(w.date) || got.typ != w.typ || got.from != w.from || got.text != w.text || got.media != w.media || got.photo != w.photo {
+					t.Errorf("message %d:\n got %+v\nwant %+v", i, got, w)
+				}
+				if m.FromID != m.From {
+					t.Errorf("message %d: from_id %q, want the name %q", i, m.FromID, m.From)
+				}
+				if !m.WallClock {
+					t.Errorf("message %d: WhatsApp time has no zone, want WallClock", i)
+				}
+			}
+		})
+	}
+}
+
+func TestIsWhatsAppExport(t *testing.T) {
+	for in, want := range map[string]bool{
+		"31.12.24, 23:59 - Анна: привет":            true,
+		"\ufeff[31.12.24, 23:59:59] Анна: привет":   true,
+		"\n\n12/31/24, 11:59 PM - Anna: hi":         true,
+		`{"name": "chat", "messages": []}`:          false,
+		"timestamp,sender_id,text\n1,anna,привет\n": false,
+	} {
+		if got := isWhatsAppExport([]byte(in)); got != want {
+			t.Errorf("isWhatsAppExport(%q) = %v, want %v", in, got, want)
+		}
+	}
+}